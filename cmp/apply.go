@@ -0,0 +1,268 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Apply applies the differences recorded in d to x and returns the result.
+// If d was produced by Compare(x, y, opts...), then the result is equal to y
+// according to the same options. The input x is not mutated; instead, all
+// values along the path of each difference are shallow copied.
+// The values taken from the differences are not copied and may alias y.
+//
+// Apply panics if a difference cannot be applied. This occurs if x does not
+// have the same structure as the value originally compared, or if the path
+// contains a Transform step (since transformations cannot be inverted) or
+// an unexported field that cannot be accessed.
+func Apply(x interface{}, d DiffResult) interface{} {
+	if len(d.Differences) == 0 {
+		return x
+	}
+	root := new(applyNode)
+	for _, df := range d.Differences {
+		root.insert(df.Path[1:], df)
+	}
+
+	// The root may have been wrapped in an interface if the types of
+	// the values originally compared differed (see rootStep).
+	vx := reflect.ValueOf(x)
+	if t := d.Differences[0].Path[0].Type(); t != nil && (!vx.IsValid() || vx.Type() != t) {
+		if t.Kind() != reflect.Interface || (vx.IsValid() && !vx.Type().AssignableTo(t)) {
+			panic(fmt.Sprintf("cannot apply differences for %v to value of type %T", t, x))
+		}
+		vvx := reflect.New(t).Elem()
+		if vx.IsValid() {
+			vvx.Set(vx)
+		}
+		vx = vvx
+	}
+
+	vy := root.apply(vx)
+	if !vy.IsValid() {
+		return nil
+	}
+	return vy.Interface()
+}
+
+// applyNode is a trie of path steps leading to differences.
+type applyNode struct {
+	diff     *Difference // Non-nil if this is a leaf node
+	steps    []PathStep  // invariant: len(steps) == len(children)
+	children []*applyNode
+}
+
+func (n *applyNode) insert(p Path, d Difference) {
+	if len(p) == 0 {
+		n.diff = &d
+		return
+	}
+	for i, s := range n.steps {
+		if sameStep(s, p[0]) {
+			n.children[i].insert(p[1:], d)
+			return
+		}
+	}
+	child := new(applyNode)
+	n.steps = append(n.steps, p[0])
+	n.children = append(n.children, child)
+	child.insert(p[1:], d)
+}
+
+// sameStep reports whether the two steps perform the same operation.
+func sameStep(s1, s2 PathStep) bool {
+	switch s1 := s1.(type) {
+	case StructField:
+		s2, ok := s2.(StructField)
+		return ok && s1.Index() == s2.Index()
+	case SliceIndex:
+		s2, ok := s2.(SliceIndex)
+		return ok && s1.xkey == s2.xkey && s1.ykey == s2.ykey
+	case MapIndex:
+		s2, ok := s2.(MapIndex)
+		return ok && s1.Key().CanInterface() && s2.Key().CanInterface() &&
+			s1.Key().Interface() == s2.Key().Interface()
+	case Indirect:
+		_, ok := s2.(Indirect)
+		return ok
+	case TypeAssertion:
+		_, ok := s2.(TypeAssertion)
+		return ok
+	default:
+		return false
+	}
+}
+
+// apply returns a copy of v with all differences in the subtree applied.
+// The returned value is invalid if the value should be removed.
+func (n *applyNode) apply(v reflect.Value) reflect.Value {
+	if n.diff != nil {
+		return n.diff.Y
+	}
+	if !v.IsValid() {
+		panic(fmt.Sprintf("cannot apply difference at %#v: value does not exist", n.anyPath()))
+	}
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Struct:
+		vc := reflect.New(t).Elem()
+		vc.Set(v)
+		for i, s := range n.steps {
+			sf, ok := s.(StructField)
+			if !ok {
+				n.panicStep(s)
+			}
+			f := vc.Field(sf.Index())
+			if !f.CanSet() {
+				if !supportExporters {
+					panic(fmt.Sprintf("cannot apply difference at %#v: unexported field", n.children[i].anyPath()))
+				}
+				f = retrieveUnexportedField(vc, t.Field(sf.Index()), true)
+			}
+			f.Set(n.children[i].apply(f))
+		}
+		return vc
+	case reflect.Ptr:
+		if len(n.steps) != 1 || v.IsNil() {
+			n.panicStep(n.steps[0])
+		}
+		if _, ok := n.steps[0].(Indirect); !ok {
+			n.panicStep(n.steps[0])
+		}
+		vc := reflect.New(t.Elem())
+		vc.Elem().Set(n.children[0].apply(v.Elem()))
+		return vc
+	case reflect.Interface:
+		if len(n.steps) != 1 || v.IsNil() {
+			n.panicStep(n.steps[0])
+		}
+		if _, ok := n.steps[0].(TypeAssertion); !ok {
+			n.panicStep(n.steps[0])
+		}
+		vc := reflect.New(t).Elem()
+		vc.Set(n.children[0].apply(v.Elem()))
+		return vc
+	case reflect.Map:
+		if v.IsNil() {
+			n.panicStep(n.steps[0])
+		}
+		vc := reflect.MakeMap(t)
+		for _, k := range v.MapKeys() {
+			vc.SetMapIndex(k, v.MapIndex(k))
+		}
+		for i, s := range n.steps {
+			mi, ok := s.(MapIndex)
+			if !ok {
+				n.panicStep(s)
+			}
+			vc.SetMapIndex(mi.Key(), n.children[i].apply(v.MapIndex(mi.Key())))
+		}
+		return vc
+	case reflect.Slice, reflect.Array:
+		return n.applySlice(v)
+	default:
+		n.panicStep(n.steps[0])
+		panic("not reachable")
+	}
+}
+
+// applySlice reconstructs the y slice by replaying the differences.
+// Since the edit-script used to compare slices is monotonic, the y slice is
+// the x slice with all removed elements omitted and all inserted elements
+// placed at their y index, where elements present in both may be modified.
+// The x and y indexes of unmodified elements do not necessarily advance
+// together (e.g., if an element was ignored), so the y index is resynchronized
+// at each modified element according to its recorded indexes.
+func (n *applyNode) applySlice(v reflect.Value) reflect.Value {
+	removed := map[int]bool{}
+	inserted := map[int]*applyNode{}
+	modified := map[int]int{} // x index to child index
+	var insertedAt []int      // sorted y indexes of inserted elements
+	for i, s := range n.steps {
+		si, ok := s.(SliceIndex)
+		if !ok {
+			n.panicStep(s)
+		}
+		ix, iy := si.SplitKeys()
+		switch {
+		case iy < 0:
+			removed[ix] = true
+		case ix < 0:
+			inserted[iy] = n.children[i]
+			insertedAt = append(insertedAt, iy)
+		default:
+			modified[ix] = i
+		}
+	}
+	sort.Ints(insertedAt)
+
+	var elems []reflect.Value
+	var iy int
+	insertBefore := func(jy int) {
+		for len(insertedAt) > 0 && insertedAt[0] < jy {
+			elems = append(elems, inserted[insertedAt[0]].apply(reflect.Value{}))
+			insertedAt = insertedAt[1:]
+			iy++
+		}
+	}
+	for ix := 0; ix < v.Len(); ix++ {
+		if removed[ix] {
+			continue
+		}
+		ve := v.Index(ix)
+		if i, ok := modified[ix]; ok {
+			_, jy := n.steps[i].(SliceIndex).SplitKeys()
+			insertBefore(jy)
+			iy = jy
+			ve = n.children[i].apply(ve)
+		} else {
+			for len(insertedAt) > 0 && insertedAt[0] <= iy {
+				insertBefore(iy + 1)
+			}
+		}
+		elems = append(elems, ve)
+		iy++
+	}
+	for _, jy := range insertedAt {
+		elems = append(elems, inserted[jy].apply(reflect.Value{}))
+	}
+
+	var vc reflect.Value
+	switch t := v.Type(); t.Kind() {
+	case reflect.Slice:
+		vc = reflect.MakeSlice(t, len(elems), len(elems))
+	case reflect.Array:
+		if len(elems) != v.Len() {
+			panic(fmt.Sprintf("cannot apply difference at %#v: inconsistent array length", n.anyPath()))
+		}
+		vc = reflect.New(t).Elem()
+	}
+	for i, ve := range elems {
+		vc.Index(i).Set(ve)
+	}
+	return vc
+}
+
+// anyPath returns the path to any difference within the subtree
+// for use in error messages.
+func (n *applyNode) anyPath() Path {
+	for n.diff == nil {
+		n = n.children[0]
+	}
+	return n.diff.Path
+}
+
+func (n *applyNode) panicStep(s PathStep) {
+	for i := range n.steps {
+		if n.steps[i] == s {
+			const help = "only struct fields, slice elements, map entries, pointer indirections, and type assertions can be applied"
+			panic(fmt.Sprintf("cannot apply difference at %#v:\n\tunsupported %T step\n%s", n.children[i].anyPath(), s, help))
+		}
+	}
+	panic("not reachable")
+}
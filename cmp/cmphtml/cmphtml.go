@@ -0,0 +1,193 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Package cmphtml renders the report of cmp.Compare as HTML.
+//
+// The rendered document is self-contained (i.e., it has no external
+// stylesheets or scripts), which makes it suitable for publishing as an
+// artifact of a continuous integration system. Each composite value in the
+// report is a collapsible node, and the lines of the report are highlighted
+// according to whether they were removed, inserted, or unchanged.
+//
+// The report is formatted according to the options passed to cmp.Compare
+// (e.g., cmp.ReportIndent or cmp.ReportUnified), such that the HTML document
+// presents the same content as the textual report.
+package cmphtml
+
+import (
+	"bytes"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const header = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>cmp report</title>
+<style>
+body { font-family: monospace; }
+.report { white-space: pre; tab-size: 4; }
+.report details { margin: 0; }
+.report summary { list-style-position: inside; }
+.removed { background-color: #ffebe9; }
+.inserted { background-color: #e6ffec; }
+.hunk { color: #6e7781; }
+.string { color: #0a3069; }
+.number { color: #0550ae; }
+.keyword { color: #cf222e; }
+.comment { color: #6e7781; font-style: italic; }
+</style>
+</head>
+<body>
+`
+
+const footer = `</body>
+</html>
+`
+
+// Render returns an HTML document that presents the report of r.
+// If the values compared are equal, then the document says so.
+func Render(r cmp.DiffResult) string {
+	var b bytes.Buffer
+	b.WriteString(header)
+	if r.Equal() {
+		b.WriteString("<p>No differences.</p>\n")
+	} else {
+		b.WriteString("<div class=\"report\">")
+		renderReport(&b, stripEscapes(r.Report))
+		b.WriteString("</div>\n")
+	}
+	b.WriteString(footer)
+	return b.String()
+}
+
+// renderReport writes each line of the report, where lines that open a
+// composite value begin a collapsible node that is closed by the line
+// that closes the composite value.
+func renderReport(b *bytes.Buffer, report string) {
+	var depth int
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(report, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\n")
+		_, n := utf8.DecodeRuneInString(line)
+		content := strings.TrimSpace(line[n:]) // Omit the diff marker
+		opens := strings.HasSuffix(content, "{") || strings.HasSuffix(content, "(")
+		closes := strings.HasPrefix(content, "}") || strings.HasPrefix(content, ")")
+
+		if opens {
+			b.WriteString("<details open><summary>")
+			renderLine(b, line)
+			b.WriteString("</summary>")
+			depth++
+			continue
+		}
+		renderLine(b, line)
+		if closes && depth > 0 {
+			b.WriteString("</details>")
+			depth--
+		}
+	}
+	for ; depth > 0; depth-- {
+		b.WriteString("</details>") // Only occurs for a truncated report
+	}
+}
+
+// renderLine writes a single line of the report, which is classified
+// according to its diff marker.
+func renderLine(b *bytes.Buffer, line string) {
+	var class string
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		class = "hunk"
+	case strings.HasPrefix(line, "-"):
+		class = "removed"
+	case strings.HasPrefix(line, "+"):
+		class = "inserted"
+	}
+	if class != "" {
+		b.WriteString("<span class=\"" + class + "\">")
+	}
+	if class == "hunk" {
+		b.WriteString(html.EscapeString(line))
+	} else {
+		highlight(b, line)
+	}
+	if class != "" {
+		b.WriteString("</span>")
+	}
+	b.WriteString("\n")
+}
+
+// highlight writes s with string literals, numbers, keywords, and comments
+// enclosed in spans for syntax highlighting.
+func highlight(b *bytes.Buffer, s string) {
+	writeSpan := func(class, s string) {
+		b.WriteString("<span class=\"" + class + "\">" + html.EscapeString(s) + "</span>")
+	}
+	for len(s) > 0 {
+		var n int
+		switch c := s[0]; {
+		case strings.HasPrefix(s, "//"):
+			writeSpan("comment", s)
+			return
+		case c == '"' || c == '`' || c == '\'':
+			n = 1
+			for n < len(s) && s[n] != c {
+				if s[n] == '\\' && c != '`' {
+					n++
+				}
+				n++
+			}
+			if n < len(s) {
+				n++ // Include the closing quote
+			} else {
+				n = len(s)
+			}
+			writeSpan("string", s[:n])
+		case isIdent(c):
+			for n < len(s) && isIdent(s[n]) {
+				n++
+			}
+			switch word := s[:n]; {
+			case word == "nil" || word == "true" || word == "false":
+				writeSpan("keyword", word)
+			case '0' <= c && c <= '9':
+				writeSpan("number", word)
+			default:
+				b.WriteString(html.EscapeString(word))
+			}
+		default:
+			n = 1
+			b.WriteString(html.EscapeString(s[:n]))
+		}
+		s = s[n:]
+	}
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c >= 0x80
+}
+
+// stripEscapes removes ANSI escape sequences (see cmp.ReportColor).
+func stripEscapes(s string) string {
+	if !strings.Contains(s, "\x1b[") {
+		return s
+	}
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '[' {
+			j := i + 2
+			for j < len(s) && s[j] != 'm' {
+				j++
+			}
+			i = j
+			continue
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
@@ -0,0 +1,68 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmphtml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type (
+	Config struct {
+		Name    string
+		Enabled bool
+		Server  *Server
+	}
+	Server struct {
+		Host  string
+		Ports []int
+	}
+)
+
+func TestRender(t *testing.T) {
+	x := Config{Name: "<prod>", Enabled: true, Server: &Server{Host: "a", Ports: []int{80, 443}}}
+	y := Config{Name: "<prod>", Enabled: false, Server: &Server{Host: "b", Ports: []int{80, 443}}}
+
+	tests := []struct {
+		opts   []cmp.Option
+		indent string
+	}{
+		{nil, "\t"},
+		{[]cmp.Option{cmp.ReportColor(true)}, "\t"},
+		{[]cmp.Option{cmp.ReportIndent("  ")}, "  "},
+	}
+	for _, tt := range tests {
+		opts := tt.opts
+		got := Render(cmp.Compare(x, y, opts...))
+		for _, want := range []string{
+			"<!DOCTYPE html>",
+			`<span class="removed">-`,
+			tt.indent + `Enabled: <span class="keyword">true</span>,</span>`,
+			`<span class="inserted">+`,
+			tt.indent + `Enabled: <span class="keyword">false</span>,</span>`,
+			`<span class="string">&#34;&lt;prod&gt;&#34;</span>`,
+			`<span class="number">443</span>`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("Render(Compare(x, y, %v)) does not contain %q:\n%s", opts, want, got)
+			}
+		}
+		if strings.Contains(got, "\x1b") {
+			t.Errorf("Render(Compare(x, y, %v)) contains escape sequences", opts)
+		}
+		if n, m := strings.Count(got, "<details open>"), strings.Count(got, "</details>"); n != 2 || m != 2 {
+			t.Errorf("Render(Compare(x, y, %v)) has %d opening and %d closing nodes, want 2", opts, n, m)
+		}
+		if !strings.Contains(got, "},\n</details>") {
+			t.Errorf("Render(Compare(x, y, %v)) does not close the node for Server:\n%s", opts, got)
+		}
+	}
+
+	if got := Render(cmp.Compare(x, x)); !strings.Contains(got, "No differences.") {
+		t.Errorf("Render(Compare(x, x)) = %s, want no differences", got)
+	}
+}
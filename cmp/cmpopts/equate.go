@@ -6,22 +6,46 @@
 package cmpopts
 
 import (
+	"fmt"
 	"math"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/internal/diff"
+	"github.com/google/go-cmp/cmp/internal/function"
 	"golang.org/x/xerrors"
 )
 
 func equateAlways(_, _ interface{}) bool { return true }
 
+// describe returns opt described as a call to the cmpopts function of the
+// given name with the provided arguments (e.g., "cmpopts.EquateApprox(0.01, 0)").
+func describe(opt cmp.Option, name string, args ...interface{}) cmp.Option {
+	var ss []string
+	for _, arg := range args {
+		switch v := reflect.ValueOf(arg); v.Kind() {
+		case reflect.Func:
+			ss = append(ss, function.NameOf(v))
+		case reflect.String:
+			ss = append(ss, strconv.Quote(v.String()))
+		default:
+			ss = append(ss, fmt.Sprint(arg))
+		}
+	}
+	return cmp.Describe(fmt.Sprintf("cmpopts.%s(%s)", name, strings.Join(ss, ", ")), opt)
+}
+
 // EquateEmpty returns a Comparer option that determines all maps and slices
 // with a length of zero to be equal, regardless of whether they are nil.
 //
 // EquateEmpty can be used in conjunction with SortSlices and SortMaps.
 func EquateEmpty() cmp.Option {
-	return cmp.FilterValues(isEmpty, cmp.Comparer(equateAlways))
+	return describe(cmp.FilterValues(isEmpty, cmp.Comparer(equateAlways)), "EquateEmpty")
 }
 
 func isEmpty(x, y interface{}) bool {
@@ -50,10 +74,10 @@ func EquateApprox(fraction, margin float64) cmp.Option {
 		panic("margin or fraction must be a non-negative number")
 	}
 	a := approximator{fraction, margin}
-	return cmp.Options{
+	return describe(cmp.Options{
 		cmp.FilterValues(areRealF64s, cmp.Comparer(a.compareF64)),
 		cmp.FilterValues(areRealF32s, cmp.Comparer(a.compareF32)),
-	}
+	}, "EquateApprox", fraction, margin)
 }
 
 type approximator struct{ frac, marg float64 }
@@ -77,10 +101,10 @@ func (a approximator) compareF32(x, y float32) bool {
 //
 // EquateNaNs can be used in conjunction with EquateApprox.
 func EquateNaNs() cmp.Option {
-	return cmp.Options{
+	return describe(cmp.Options{
 		cmp.FilterValues(areNaNsF64s, cmp.Comparer(equateAlways)),
 		cmp.FilterValues(areNaNsF32s, cmp.Comparer(equateAlways)),
-	}
+	}, "EquateNaNs")
 }
 
 func areNaNsF64s(x, y float64) bool {
@@ -99,7 +123,7 @@ func EquateApproxTime(margin time.Duration) cmp.Option {
 		panic("margin must be a non-negative number")
 	}
 	a := timeApproximator{margin}
-	return cmp.FilterValues(areNonZeroTimes, cmp.Comparer(a.compare))
+	return describe(cmp.FilterValues(areNonZeroTimes, cmp.Comparer(a.compare)), "EquateApproxTime", margin)
 }
 
 func areNonZeroTimes(x, y time.Time) bool {
@@ -122,6 +146,88 @@ func (a timeApproximator) compare(x, y time.Time) bool {
 	return !x.Add(a.margin).Before(y)
 }
 
+// EquateIntegers returns a Comparer option that determines integer values
+// of differing types to be equal if they represent the same mathematical value.
+// For example, int64(5) and uint8(5) are equal, while int64(-1) and
+// uint64(math.MaxUint64) are not since a negative value cannot be
+// represented by an unsigned type.
+//
+// This option only applies when the types differ, which typically occurs
+// when comparing values held within interfaces. Unequal values that cannot be
+// represented by the type of the other value are annotated as such by Diff.
+func EquateIntegers() cmp.Option {
+	cm := cmp.AnnotateComparer(describeMixedIntegers, cmp.Comparer(compareIntegers))
+	return describe(cmp.FilterValues(areMixedIntegers, cm), "EquateIntegers")
+}
+
+func areMixedIntegers(x, y interface{}) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	return (x != nil && y != nil && vx.Type() != vy.Type()) &&
+		(isSigned(vx.Kind()) || isUnsigned(vx.Kind())) &&
+		(isSigned(vy.Kind()) || isUnsigned(vy.Kind()))
+}
+
+func compareIntegers(x, y interface{}) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	switch sx, sy := isSigned(vx.Kind()), isSigned(vy.Kind()); {
+	case sx && sy:
+		return vx.Int() == vy.Int()
+	case !sx && !sy:
+		return vx.Uint() == vy.Uint()
+	case sx:
+		// Negative values are never representable as an unsigned integer.
+		// Avoid converting to uint64 first since that would wrap around.
+		return vx.Int() >= 0 && uint64(vx.Int()) == vy.Uint()
+	default:
+		return vy.Int() >= 0 && uint64(vy.Int()) == vx.Uint()
+	}
+}
+
+// describeMixedIntegers describes which of x and y cannot be represented
+// by the type of the other value, if any.
+func describeMixedIntegers(x, y interface{}) string {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	var ss []string
+	if !isRepresentable(vx, vy.Type()) {
+		ss = append(ss, fmt.Sprintf("%v(%v) is not representable as %v", vx.Type(), vx, vy.Type()))
+	}
+	if !isRepresentable(vy, vx.Type()) {
+		ss = append(ss, fmt.Sprintf("%v(%v) is not representable as %v", vy.Type(), vy, vx.Type()))
+	}
+	return strings.Join(ss, "; ")
+}
+
+// isRepresentable reports whether the integer v can be represented by
+// the integer type t without overflow.
+func isRepresentable(v reflect.Value, t reflect.Type) bool {
+	z := reflect.Zero(t)
+	switch sv, st := isSigned(v.Kind()), isSigned(t.Kind()); {
+	case sv && st:
+		return !z.OverflowInt(v.Int())
+	case !sv && !st:
+		return !z.OverflowUint(v.Uint())
+	case sv:
+		return v.Int() >= 0 && !z.OverflowUint(uint64(v.Int()))
+	default:
+		return v.Uint() <= math.MaxInt64 && !z.OverflowInt(int64(v.Uint()))
+	}
+}
+
+func isSigned(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+func isUnsigned(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
 // AnyError is an error that matches any non-nil error.
 var AnyError anyError
 
@@ -134,7 +240,7 @@ func (anyError) Is(err error) bool { return err != nil }
 // if errors.Is reports them to match. The AnyError error can be used to
 // match any non-nil error.
 func EquateErrors() cmp.Option {
-	return cmp.FilterValues(areConcreteErrors, cmp.Comparer(compareErrors))
+	return describe(cmp.FilterValues(areConcreteErrors, cmp.Comparer(compareErrors)), "EquateErrors")
 }
 
 // areConcreteErrors reports whether x and y are types that implement error.
@@ -154,3 +260,214 @@ func compareErrors(x, y interface{}) bool {
 	// TODO(≥go1.13): Use standard definition of errors.Is.
 	return xerrors.Is(xe, ye) || xerrors.Is(ye, xe)
 }
+
+// EquateStringsWithin returns a Comparer option that determines two strings
+// to be equal if the Levenshtein distance between them is no more than
+// distance, where the distance is the minimum number of single rune
+// insertions, deletions, or substitutions needed to turn one into the other.
+// It panics if distance is negative.
+//
+// When two strings are unequal, the report produced by cmp.Diff is annotated
+// with the distance between them and where they differ, where "[-x+y]" denotes
+// that the runes x in the first string are replaced by y in the second.
+func EquateStringsWithin(distance int) cmp.Option {
+	if distance < 0 {
+		panic("distance must be a non-negative number")
+	}
+	sd := stringDistance(distance)
+	return describe(cmp.AnnotateComparer(describeStringDistance, cmp.Comparer(sd.compare)), "EquateStringsWithin", distance)
+}
+
+type stringDistance int
+
+func (d stringDistance) compare(x, y string) bool {
+	return levenshtein([]rune(x), []rune(y)) <= int(d)
+}
+
+func describeStringDistance(x, y string) string {
+	rx, ry := []rune(x), []rune(y)
+	es := diff.Difference(len(rx), len(ry), func(ix, iy int) diff.Result {
+		return diff.BoolResult(rx[ix] == ry[iy])
+	})
+
+	var b, del, ins []rune
+	flush := func() {
+		if len(del)+len(ins) > 0 {
+			b = append(b, '[')
+			if len(del) > 0 {
+				b = append(append(b, '-'), del...)
+			}
+			if len(ins) > 0 {
+				b = append(append(b, '+'), ins...)
+			}
+			b = append(b, ']')
+			del, ins = del[:0], ins[:0]
+		}
+	}
+	var ix, iy int
+	for _, e := range es {
+		switch e {
+		case diff.Identity:
+			flush()
+			b = append(b, rx[ix])
+			ix, iy = ix+1, iy+1
+		case diff.UniqueX:
+			del = append(del, rx[ix])
+			ix++
+		case diff.UniqueY:
+			ins = append(ins, ry[iy])
+			iy++
+		case diff.Modified:
+			del, ins = append(del, rx[ix]), append(ins, ry[iy])
+			ix, iy = ix+1, iy+1
+		}
+	}
+	flush()
+	return fmt.Sprintf("edit distance %d: %s", levenshtein(rx, ry), strconv.Quote(string(b)))
+}
+
+// EquateNormalizedStrings returns a Comparer option that determines two strings
+// to be equal if they are identical after normalization, where normalization
+// converts all letters to lower case, treats every rune that is neither a
+// letter nor a digit as a space, and collapses adjacent spaces into one.
+// For example, "Main St." and "main  st" are equal.
+func EquateNormalizedStrings() cmp.Option {
+	return describe(cmp.Comparer(equateNormalizedStrings), "EquateNormalizedStrings")
+}
+
+func equateNormalizedStrings(x, y string) bool {
+	return normalizeString(x) == normalizeString(y)
+}
+
+// normalizeString returns the tokens of s joined by a single space.
+func normalizeString(s string) string {
+	return strings.Join(tokenizeString(s), " ")
+}
+
+// tokenizeString splits s into lower-case tokens of letters and digits.
+func tokenizeString(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// EquateTokenSets returns a Comparer option that determines two strings
+// to be equal if their token set ratio is at least the provided ratio,
+// which must be within [0, 1]. Strings are split into tokens of letters and
+// digits as by EquateNormalizedStrings, where the order and repetition of
+// tokens are ignored. This is useful for fuzzily comparing denormalized data
+// (e.g., "Smith, John" and "John Smith") where exact equality is too strict.
+//
+// The token set ratio is the similarity between the sorted tokens common to
+// both strings and the common tokens followed by the tokens unique to either
+// string, where the similarity of two strings is one minus their Levenshtein
+// distance divided by the length of the longer string. Thus, the ratio is 1
+// if the tokens of one string are a subset of the tokens of the other.
+//
+// When two strings are unequal, the report produced by cmp.Diff is annotated
+// with the token set ratio between them.
+func EquateTokenSets(ratio float64) cmp.Option {
+	if !(0 <= ratio && ratio <= 1) {
+		panic("ratio must be within [0, 1]")
+	}
+	tr := tokenSetRatio(ratio)
+	return describe(cmp.AnnotateComparer(describeTokenSetRatio, cmp.Comparer(tr.compare)), "EquateTokenSets", ratio)
+}
+
+type tokenSetRatio float64
+
+func (r tokenSetRatio) compare(x, y string) bool {
+	return computeTokenSetRatio(x, y) >= float64(r)
+}
+
+func describeTokenSetRatio(x, y string) string {
+	return fmt.Sprintf("token set ratio %.3g", computeTokenSetRatio(x, y))
+}
+
+// computeTokenSetRatio computes the token set ratio of x and y.
+func computeTokenSetRatio(x, y string) float64 {
+	tx, ty := map[string]bool{}, map[string]bool{}
+	for _, t := range tokenizeString(x) {
+		tx[t] = true
+	}
+	for _, t := range tokenizeString(y) {
+		ty[t] = true
+	}
+	var common, onlyX, onlyY []string
+	for t := range tx {
+		if ty[t] {
+			common = append(common, t)
+		} else {
+			onlyX = append(onlyX, t)
+		}
+	}
+	for t := range ty {
+		if !tx[t] {
+			onlyY = append(onlyY, t)
+		}
+	}
+	join := func(ss ...[]string) string {
+		var all []string
+		for _, s := range ss {
+			sort.Strings(s)
+			all = append(all, s...)
+		}
+		return strings.Join(all, " ")
+	}
+	s0 := join(common)
+	s1 := join(common, onlyX)
+	s2 := join(common, onlyY)
+	if s1 == s2 {
+		return 1 // Identical token sets, including when both are empty
+	}
+	r := similarity(s1, s2)
+	if s0 != "" {
+		r = math.Max(r, math.Max(similarity(s0, s1), similarity(s0, s2)))
+	}
+	return r
+}
+
+// similarity returns one minus the Levenshtein distance between x and y
+// divided by the length of the longer string.
+func similarity(x, y string) float64 {
+	rx, ry := []rune(x), []rune(y)
+	n := len(rx)
+	if len(ry) > n {
+		n = len(ry)
+	}
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(rx, ry))/float64(n)
+}
+
+// levenshtein returns the Levenshtein distance between x and y.
+func levenshtein(x, y []rune) int {
+	prev := make([]int, len(y)+1)
+	curr := make([]int, len(y)+1)
+	for iy := range prev {
+		prev[iy] = iy
+	}
+	for ix := range x {
+		curr[0] = ix + 1
+		for iy := range y {
+			cost := 1
+			if x[ix] == y[iy] {
+				cost = 0
+			}
+			curr[iy+1] = min3(prev[iy+1]+1, curr[iy]+1, prev[iy]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(y)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
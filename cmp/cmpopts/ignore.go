@@ -5,8 +5,13 @@
 package cmpopts
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -24,14 +29,155 @@ import (
 // This does not handle unexported fields; use IgnoreUnexported instead.
 func IgnoreFields(typ interface{}, names ...string) cmp.Option {
 	sf := newStructFilter(typ, names...)
-	return cmp.FilterPath(sf.filter, cmp.Ignore())
+	args := []interface{}{reflect.TypeOf(typ)}
+	for _, name := range names {
+		args = append(args, name)
+	}
+	return describe(cmp.FilterPath(sf.filter, cmp.Ignore()), "IgnoreFields", args...)
+}
+
+// IgnoreFieldsMatching returns an Option that ignores the fields of a single
+// struct type whose names match the regular expression pattern
+// (e.g., "At$" for CreatedAt, UpdatedAt, and DeletedAt).
+// The struct type is specified by passing in a value of that type.
+// As with regexp.MatchString, the pattern matches any substring of the name
+// unless it is anchored with ^ and $.
+//
+// Only the immediate fields of the struct type are considered,
+// including unexported fields. It panics if the pattern is invalid.
+func IgnoreFieldsMatching(typ interface{}, pattern string) cmp.Option {
+	t := reflect.TypeOf(typ)
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("%T must be a struct", typ))
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("invalid field name pattern: %v", err))
+	}
+	f := func(p cmp.Path) bool {
+		sf, ok := p.Last().(cmp.StructField)
+		return ok && len(p) >= 2 && p[len(p)-2].Type().AssignableTo(t) && re.MatchString(sf.Name())
+	}
+	return describe(cmp.FilterPath(f, cmp.Ignore()), "IgnoreFieldsMatching", t, pattern)
+}
+
+// IgnoreKinds returns an Option that ignores struct fields whose type is
+// one of the given kinds (e.g., IgnoreKinds(reflect.Func, reflect.Chan)
+// to ignore callbacks and channels, which are otherwise only equal if nil).
+// The kind of the declared type of the field is considered,
+// such that a func stored in an interface field is not ignored.
+// To only ignore the fields of a single struct type, use IgnoreFieldKinds.
+func IgnoreKinds(kinds ...reflect.Kind) cmp.Option {
+	kf := kindFilter{kinds: kinds}
+	args := []interface{}{}
+	for _, k := range kinds {
+		args = append(args, k)
+	}
+	return describe(cmp.FilterPath(kf.filter, cmp.Ignore()), "IgnoreKinds", args...)
+}
+
+// IgnoreFieldKinds is like IgnoreKinds, but only ignores the immediate
+// fields of a single struct type, which is specified by passing in
+// a value of that type.
+func IgnoreFieldKinds(typ interface{}, kinds ...reflect.Kind) cmp.Option {
+	t := reflect.TypeOf(typ)
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("%T must be a struct", typ))
+	}
+	kf := kindFilter{t: t, kinds: kinds}
+	args := []interface{}{t}
+	for _, k := range kinds {
+		args = append(args, k)
+	}
+	return describe(cmp.FilterPath(kf.filter, cmp.Ignore()), "IgnoreFieldKinds", args...)
+}
+
+type kindFilter struct {
+	t     reflect.Type // The struct type to match on; nil if any
+	kinds []reflect.Kind
+}
+
+func (kf kindFilter) filter(p cmp.Path) bool {
+	if _, ok := p.Last().(cmp.StructField); !ok || len(p) < 2 {
+		return false
+	}
+	if kf.t != nil && !p[len(p)-2].Type().AssignableTo(kf.t) {
+		return false
+	}
+	k := p.Last().Type().Kind()
+	for _, ki := range kf.kinds {
+		if k == ki {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoreTaggedFields returns an Option that ignores struct fields whose
+// struct tag has the given key with the given value (e.g., `cmp:"-"` for
+// IgnoreTaggedFields("cmp", "-")), such that types may declare the fields
+// that are irrelevant to comparisons once, rather than every test listing
+// the fields by name. If the value of the tag is a comma-separated list
+// (e.g., `cmp:"-,omitempty"`), then the field is ignored if any element
+// of the list equals value.
+//
+// Unlike IgnoreFields, this applies to struct fields of every type,
+// including unexported fields.
+func IgnoreTaggedFields(key, value string) cmp.Option {
+	f := func(tag reflect.StructTag) bool {
+		s, ok := tag.Lookup(key)
+		if !ok {
+			return false
+		}
+		for _, v := range strings.Split(s, ",") {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+	return describe(FilterTaggedFields(f, cmp.Ignore()), "IgnoreTaggedFields", key, value)
+}
+
+// IgnorePaths returns an Option that ignores values whose path matches
+// any of the given glob-style patterns (see cmp.CompilePathPattern).
+// For example, IgnorePaths("Spec.Containers[*].Image", `Labels["version"]`)
+// ignores the Image field of every element of Spec.Containers and
+// the "version" entry of the Labels map.
+//
+// Unlike IgnoreFields, the patterns are relative to the root of the values
+// being compared and may select slice elements and map entries.
+// It panics if any of the patterns are invalid.
+func IgnorePaths(patterns ...string) cmp.Option {
+	var pf pathFilter
+	args := []interface{}{}
+	for _, s := range patterns {
+		p, err := cmp.CompilePathPattern(s)
+		if err != nil {
+			panic(err.Error())
+		}
+		pf = append(pf, p)
+		args = append(args, s)
+	}
+	return describe(cmp.FilterPath(pf.filter, cmp.Ignore()), "IgnorePaths", args...)
+}
+
+type pathFilter []*cmp.PathPattern
+
+func (pf pathFilter) filter(p cmp.Path) bool {
+	for _, pp := range pf {
+		if pp.Match(p) {
+			return true
+		}
+	}
+	return false
 }
 
 // IgnoreTypes returns an Option that ignores all values assignable to
 // certain types, which are specified by passing in a value of each type.
 func IgnoreTypes(typs ...interface{}) cmp.Option {
 	tf := newTypeFilter(typs...)
-	return cmp.FilterPath(tf.filter, cmp.Ignore())
+	return describe(cmp.FilterPath(tf.filter, cmp.Ignore()), "IgnoreTypes", typesOf(typs)...)
 }
 
 type typeFilter []reflect.Type
@@ -60,13 +206,137 @@ func (tf typeFilter) filter(p cmp.Path) bool {
 	return false
 }
 
+// IgnoreTypesByName returns an Option that ignores all values of named types
+// with the given fully qualified names, which consist of the import path
+// and the name of the type (e.g., "golang.org/x/net/http2.clientStream"),
+// or just the name for predeclared types (e.g., "error").
+// A name may be prefixed with "*" to ignore pointers to the type instead.
+// Unlike IgnoreTypes, this does not require a value of the type,
+// which is impossible to construct for unexported types of other packages.
+// Since the names are not checked, a name that is misspelled or refers to
+// a type that has since been renamed silently ignores nothing.
+func IgnoreTypesByName(names ...string) cmp.Option {
+	nf := make(typeNameFilter)
+	args := []interface{}{}
+	for _, name := range names {
+		if strings.TrimPrefix(name, "*") == "" {
+			panic(fmt.Sprintf("invalid type name: %q", name))
+		}
+		nf[name] = true
+		args = append(args, name)
+	}
+	return describe(cmp.FilterPath(nf.filter, cmp.Ignore()), "IgnoreTypesByName", args...)
+}
+
+type typeNameFilter map[string]bool
+
+func (nf typeNameFilter) filter(p cmp.Path) bool {
+	t := p.Last().Type()
+	var prefix string
+	if t.Kind() == reflect.Ptr && t.Name() == "" {
+		prefix, t = "*", t.Elem()
+	}
+	if t.Name() == "" {
+		return false
+	}
+	name := t.Name()
+	if t.PkgPath() != "" {
+		name = t.PkgPath() + "." + name
+	}
+	return nf[prefix+name]
+}
+
+// IgnoreSyncPrimitives returns an Option that ignores all values of the
+// synchronization types sync.Mutex, sync.RWMutex, sync.Once, sync.WaitGroup,
+// and sync.Cond, pointers to such values, and values of types named noCopy
+// (the convention for marking a struct as not to be copied, as used by the
+// sync/atomic package and checked by "go vet"), wherever they appear.
+// Such values carry no meaningful state for comparisons, and typically
+// appear as unexported fields that would otherwise cause Equal to panic.
+func IgnoreSyncPrimitives() cmp.Option {
+	return describe(cmp.FilterPath(isSyncPrimitive, cmp.Ignore()), "IgnoreSyncPrimitives")
+}
+
+var syncPrimitiveTypes = map[reflect.Type]bool{
+	reflect.TypeOf(sync.Mutex{}):     true,
+	reflect.TypeOf(sync.RWMutex{}):   true,
+	reflect.TypeOf(sync.Once{}):      true,
+	reflect.TypeOf(sync.WaitGroup{}): true,
+	reflect.TypeOf(sync.Cond{}):      true,
+}
+
+func isSyncPrimitive(p cmp.Path) bool {
+	t := p.Last().Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return syncPrimitiveTypes[t] || (t.Name() == "noCopy" && t.Kind() == reflect.Struct)
+}
+
+// IgnoreContexts returns an Option that ignores all interface values whose
+// type implements context.Context (e.g., struct fields, map values, and
+// slice elements of type context.Context), and interface values where
+// both x and y hold a context.Context. Concrete types that merely embed
+// a context.Context are still compared, except for the embedded field.
+// Contexts typically carry no meaningful state for comparisons, and their
+// implementations have unexported fields that would otherwise cause
+// Equal to panic.
+func IgnoreContexts() cmp.Option {
+	return describe(cmp.FilterPath(isContext, cmp.Ignore()), "IgnoreContexts")
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+func isContext(p cmp.Path) bool {
+	if t := p.Last().Type(); t.Kind() == reflect.Interface && t.Implements(contextType) {
+		return true
+	}
+	// Contexts stored in other interfaces may have different dynamic types.
+	vx, vy := p.Last().Values()
+	for _, v := range []reflect.Value{vx, vy} {
+		if !v.IsValid() || v.Kind() != reflect.Interface || v.IsNil() || !v.Elem().Type().Implements(contextType) {
+			return false
+		}
+	}
+	return true
+}
+
+// IgnoreTimes returns an Option that ignores all time.Time values and
+// pointers to time.Time values, wherever they appear, such as the timestamps
+// of audit metadata. To also ignore time.Duration values, use IgnoreDurations.
+// Use IgnoreFields or IgnorePaths to ignore specific timestamps instead.
+func IgnoreTimes() cmp.Option {
+	return describe(cmp.FilterPath(isTime, cmp.Ignore()), "IgnoreTimes")
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+func isTime(p cmp.Path) bool {
+	t := p.Last().Type()
+	return t == timeType || (t.Kind() == reflect.Ptr && t.Elem() == timeType)
+}
+
+// IgnoreDurations returns an Option that ignores all time.Duration values
+// and pointers to time.Duration values, wherever they appear.
+func IgnoreDurations() cmp.Option {
+	return describe(cmp.FilterPath(isDuration, cmp.Ignore()), "IgnoreDurations")
+}
+
+func isDuration(p cmp.Path) bool {
+	t := p.Last().Type()
+	return t == durationType || (t.Kind() == reflect.Ptr && t.Elem() == durationType)
+}
+
 // IgnoreInterfaces returns an Option that ignores all values or references of
 // values assignable to certain interface types. These interfaces are specified
 // by passing in an anonymous struct with the interface types embedded in it.
 // For example, to ignore sync.Locker, pass in struct{sync.Locker}{}.
 func IgnoreInterfaces(ifaces interface{}) cmp.Option {
 	tf := newIfaceFilter(ifaces)
-	return cmp.FilterPath(tf.filter, cmp.Ignore())
+	return describe(cmp.FilterPath(tf.filter, cmp.Ignore()), "IgnoreInterfaces", reflect.TypeOf(ifaces))
 }
 
 type ifaceFilter []reflect.Type
@@ -108,6 +378,52 @@ func (tf ifaceFilter) filter(p cmp.Path) bool {
 	return false
 }
 
+// IgnoreTypesImplementing returns an Option that ignores all values whose
+// type implements any of the given interface types, which are specified by
+// passing in a pointer to a value of each interface type
+// (e.g., new(io.Closer) for io.Closer).
+// For example, IgnoreTypesImplementing(new(Logger)) ignores every field
+// holding a logger, regardless of its concrete implementation.
+//
+// Unlike IgnoreInterfaces, this also looks at the dynamic types of interface
+// values, such that an interface{} value is ignored if the concrete values
+// in both x and y implement one of the interfaces. Values of type T are also
+// ignored if *T implements one of the interfaces.
+func IgnoreTypesImplementing(ifaces ...interface{}) cmp.Option {
+	var tf implFilter
+	for _, iface := range ifaces {
+		t := reflect.TypeOf(iface)
+		if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+			panic(fmt.Sprintf("%T must be a pointer to an interface type", iface))
+		}
+		tf = append(tf, t.Elem())
+	}
+	return describe(cmp.FilterPath(tf.filter, cmp.Ignore()), "IgnoreTypesImplementing", typesOf(ifaces)...)
+}
+
+type implFilter []reflect.Type
+
+func (tf implFilter) filter(p cmp.Path) bool {
+	if tf.implements(p.Last().Type()) {
+		return true
+	}
+	vx, vy := p.Last().Values()
+	for _, v := range []reflect.Value{vx, vy} {
+		if !v.IsValid() || v.Kind() != reflect.Interface || v.IsNil() || !tf.implements(v.Elem().Type()) {
+			return false
+		}
+	}
+	return true
+}
+func (tf implFilter) implements(t reflect.Type) bool {
+	for _, ti := range tf {
+		if t.Implements(ti) || (t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(ti)) {
+			return true
+		}
+	}
+	return false
+}
+
 // IgnoreUnexported returns an Option that only ignores the immediate unexported
 // fields of a struct, including anonymous fields of unexported types.
 // In particular, unexported fields within the struct's exported fields
@@ -119,7 +435,16 @@ func (tf ifaceFilter) filter(p cmp.Path) bool {
 // may change how the comparison behaves. Prefer a custom Comparer instead.
 func IgnoreUnexported(typs ...interface{}) cmp.Option {
 	ux := newUnexportedFilter(typs...)
-	return cmp.FilterPath(ux.filter, cmp.Ignore())
+	return describe(cmp.FilterPath(ux.filter, cmp.Ignore()), "IgnoreUnexported", typesOf(typs)...)
+}
+
+// typesOf returns the types of the values in vs.
+func typesOf(vs []interface{}) []interface{} {
+	var ts []interface{}
+	for _, v := range vs {
+		ts = append(ts, reflect.TypeOf(v))
+	}
+	return ts
 }
 
 type unexportedFilter struct{ m map[reflect.Type]bool }
@@ -158,7 +483,7 @@ func IgnoreSliceElements(discardFunc interface{}) cmp.Option {
 	if !function.IsType(vf.Type(), function.ValuePredicate) || vf.IsNil() {
 		panic(fmt.Sprintf("invalid discard function: %T", discardFunc))
 	}
-	return cmp.FilterPath(func(p cmp.Path) bool {
+	opt := cmp.FilterPath(func(p cmp.Path) bool {
 		si, ok := p.Index(-1).(cmp.SliceIndex)
 		if !ok {
 			return false
@@ -175,6 +500,77 @@ func IgnoreSliceElements(discardFunc interface{}) cmp.Option {
 		}
 		return false
 	}, cmp.Ignore())
+	return describe(opt, "IgnoreSliceElements", discardFunc)
+}
+
+// IgnoreMapKeys returns an Option that ignores the entries of map[K]V
+// with any of the given keys, which must all be of the same comparable type K.
+// It is a shorthand for IgnoreMapEntries with a function that looks up
+// the key, and it applies to maps whose key type has the same kind as K
+// and is convertible to K. For example, IgnoreMapKeys("etag", "date")
+// ignores those entries of every map[string]V, as well as those of
+// map[MyString]V, where MyString is a named string type.
+func IgnoreMapKeys(keys ...interface{}) cmp.Option {
+	if len(keys) == 0 {
+		panic("no keys provided")
+	}
+	t := reflect.TypeOf(keys[0])
+	if t == nil || !t.Comparable() {
+		panic(fmt.Sprintf("invalid map key type: %T", keys[0]))
+	}
+	set := make(map[interface{}]bool)
+	for _, k := range keys {
+		if reflect.TypeOf(k) != t {
+			panic(fmt.Sprintf("mismatching map key types: %v and %T", t, k))
+		}
+		set[k] = true
+	}
+	opt := cmp.FilterPath(func(p cmp.Path) bool {
+		mi, ok := p.Index(-1).(cmp.MapIndex)
+		if !ok {
+			return false
+		}
+		k := mi.Key()
+		if k.Kind() != t.Kind() || !k.Type().ConvertibleTo(t) || !k.CanInterface() {
+			return false
+		}
+		return set[k.Convert(t).Interface()]
+	}, cmp.Ignore())
+	return describe(opt, "IgnoreMapKeys", keys...)
+}
+
+// IgnoreSliceElementsAt is like IgnoreSliceElements, but the discard function
+// also receives the index of the element. It must be of the form
+// "func(int, T) bool", which is called with the index and value of
+// the element in x, and with the index and value of the element in y,
+// where the indexes may differ as slices are aligned with each other
+// (see SliceIndex.SplitKeys). Elements are ignored if the function reports
+// true for either. This allows ignoring elements by position,
+// such as the header and trailer of framed data or every Nth element.
+func IgnoreSliceElementsAt(discardFunc interface{}) cmp.Option {
+	vf := reflect.ValueOf(discardFunc)
+	if !function.IsType(vf.Type(), function.KeyValuePredicate) || vf.Type().In(0) != reflect.TypeOf(0) || vf.IsNil() {
+		panic(fmt.Sprintf("invalid discard function: %T", discardFunc))
+	}
+	opt := cmp.FilterPath(func(p cmp.Path) bool {
+		si, ok := p.Index(-1).(cmp.SliceIndex)
+		if !ok {
+			return false
+		}
+		if !si.Type().AssignableTo(vf.Type().In(1)) {
+			return false
+		}
+		vx, vy := si.Values()
+		ix, iy := si.SplitKeys()
+		if vx.IsValid() && vf.Call([]reflect.Value{reflect.ValueOf(ix), vx})[0].Bool() {
+			return true
+		}
+		if vy.IsValid() && vf.Call([]reflect.Value{reflect.ValueOf(iy), vy})[0].Bool() {
+			return true
+		}
+		return false
+	}, cmp.Ignore())
+	return describe(opt, "IgnoreSliceElementsAt", discardFunc)
 }
 
 // IgnoreMapEntries returns an Option that ignores entries of map[K]V.
@@ -186,7 +582,7 @@ func IgnoreMapEntries(discardFunc interface{}) cmp.Option {
 	if !function.IsType(vf.Type(), function.KeyValuePredicate) || vf.IsNil() {
 		panic(fmt.Sprintf("invalid discard function: %T", discardFunc))
 	}
-	return cmp.FilterPath(func(p cmp.Path) bool {
+	opt := cmp.FilterPath(func(p cmp.Path) bool {
 		mi, ok := p.Index(-1).(cmp.MapIndex)
 		if !ok {
 			return false
@@ -204,4 +600,5 @@ func IgnoreMapEntries(discardFunc interface{}) cmp.Option {
 		}
 		return false
 	}, cmp.Ignore())
+	return describe(opt, "IgnoreMapEntries", discardFunc)
 }
@@ -6,6 +6,7 @@ package cmpopts
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
 
@@ -32,7 +33,7 @@ func SortSlices(lessFunc interface{}) cmp.Option {
 		panic(fmt.Sprintf("invalid less function: %T", lessFunc))
 	}
 	ss := sliceSorter{vf.Type().In(0), vf}
-	return cmp.FilterValues(ss.filter, cmp.Transformer("cmpopts.SortSlices", ss.sort))
+	return describe(cmp.FilterValues(ss.filter, cmp.Transformer("cmpopts.SortSlices", ss.sort)), "SortSlices", lessFunc)
 }
 
 type sliceSorter struct {
@@ -82,6 +83,145 @@ func (ss sliceSorter) less(v reflect.Value, i, j int) bool {
 	return ss.fnc.Call([]reflect.Value{vx, vy})[0].Bool()
 }
 
+// EquateUnorderedMapOfSlices returns a Transformer option that determines
+// map[K][]V values to be equal if they contain the same elements for each key,
+// regardless of the order of the elements within each slice.
+// A missing key, a nil slice, and an empty slice are all treated as equal,
+// and thus a nil map is equal to an empty map.
+//
+// If V is a boolean, integer, floating-point, or string kind, then each slice
+// is sorted in ascending order, such that the elements are still compared
+// using any other options (e.g., EquateApprox). Otherwise, if V is comparable,
+// then each slice is matched as a multiset of elements, where elements are
+// compared using the == operator and duplicate elements are significant.
+// It does not apply to slices of other element types,
+// for which SortSlices and EquateEmpty may be used instead.
+//
+// Unlike using SortSlices and EquateEmpty, it only applies to slices
+// within such maps and also equates missing keys.
+func EquateUnorderedMapOfSlices() cmp.Option {
+	tr := cmp.Transformer("cmpopts.EquateUnorderedMapOfSlices", normalizeMapOfSlices)
+	return describe(cmp.FilterValues(isUnorderedMapOfSlices, tr), "EquateUnorderedMapOfSlices")
+}
+
+func isUnorderedMapOfSlices(x, y interface{}) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	if !(x != nil && y != nil && vx.Type() == vy.Type()) ||
+		!(vx.Kind() == reflect.Map && vx.Type().Elem().Kind() == reflect.Slice) {
+		return false
+	}
+	if et := vx.Type().Elem().Elem(); !isOrderedKind(et.Kind()) {
+		// Multisets have a different type, and so are never transformed again.
+		return et.Comparable() && hasComparableElems(vx) && hasComparableElems(vy)
+	}
+	// Check whether the maps are already normalized to avoid an infinite
+	// recursion cycle applying the same transform to itself.
+	return !isSortedMapOfSlices(vx) || !isSortedMapOfSlices(vy)
+}
+func isSortedMapOfSlices(v reflect.Value) bool {
+	if v.IsNil() {
+		return true
+	}
+	if v.Len() == 0 {
+		return false
+	}
+	for _, k := range v.MapKeys() {
+		vs := v.MapIndex(k)
+		if vs.Len() == 0 {
+			return false
+		}
+		if !sort.SliceIsSorted(vs.Interface(), func(i, j int) bool { return isOrderedLess(vs.Index(i), vs.Index(j)) }) {
+			return false
+		}
+	}
+	return true
+}
+func hasComparableElems(v reflect.Value) bool {
+	if v.Type().Elem().Elem().Kind() != reflect.Interface {
+		return true
+	}
+	for _, k := range v.MapKeys() {
+		vs := v.MapIndex(k)
+		for i := 0; i < vs.Len(); i++ {
+			if e := vs.Index(i); !e.IsNil() && !e.Elem().Type().Comparable() {
+				return false
+			}
+		}
+	}
+	return true
+}
+func normalizeMapOfSlices(x interface{}) interface{} {
+	src := reflect.ValueOf(x)
+	dstType := src.Type()
+	et := dstType.Elem().Elem()
+	if !isOrderedKind(et.Kind()) {
+		dstType = reflect.MapOf(dstType.Key(), reflect.MapOf(et, reflect.TypeOf(0)))
+	}
+	var dst reflect.Value
+	for _, k := range src.MapKeys() {
+		vs := src.MapIndex(k)
+		if vs.Len() == 0 {
+			continue
+		}
+		if !dst.IsValid() {
+			dst = reflect.MakeMap(dstType)
+		}
+		if isOrderedKind(et.Kind()) {
+			dst.SetMapIndex(k, sortOrdered(vs))
+		} else {
+			dst.SetMapIndex(k, countElems(vs))
+		}
+	}
+	if !dst.IsValid() {
+		return reflect.Zero(dstType).Interface() // nil map
+	}
+	return dst.Interface()
+}
+func sortOrdered(src reflect.Value) reflect.Value {
+	dst := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+	reflect.Copy(dst, src)
+	sort.SliceStable(dst.Interface(), func(i, j int) bool { return isOrderedLess(dst.Index(i), dst.Index(j)) })
+	return dst
+}
+func countElems(src reflect.Value) reflect.Value {
+	dst := reflect.MakeMap(reflect.MapOf(src.Type().Elem(), reflect.TypeOf(0)))
+	for i := 0; i < src.Len(); i++ {
+		var n int
+		if v := dst.MapIndex(src.Index(i)); v.IsValid() {
+			n = int(v.Int())
+		}
+		dst.SetMapIndex(src.Index(i), reflect.ValueOf(n+1))
+	}
+	return dst
+}
+
+func isOrderedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+func isOrderedLess(x, y reflect.Value) bool {
+	switch x.Kind() {
+	case reflect.Bool:
+		return !x.Bool() && y.Bool()
+	case reflect.String:
+		return x.String() < y.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return x.Int() < y.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return x.Uint() < y.Uint()
+	default:
+		fx, fy := x.Float(), y.Float()
+		return fx < fy || math.IsNaN(fx) && !math.IsNaN(fy)
+	}
+}
+
 // SortMaps returns a Transformer option that flattens map[K]V types to be a
 // sorted []struct{K, V}. The less function must be of the form
 // "func(T, T) bool" which is used to sort any map with key K that is
@@ -103,7 +243,7 @@ func SortMaps(lessFunc interface{}) cmp.Option {
 		panic(fmt.Sprintf("invalid less function: %T", lessFunc))
 	}
 	ms := mapSorter{vf.Type().In(0), vf}
-	return cmp.FilterValues(ms.filter, cmp.Transformer("cmpopts.SortMaps", ms.sort))
+	return describe(cmp.FilterValues(ms.filter, cmp.Transformer("cmpopts.SortMaps", ms.sort)), "SortMaps", lessFunc)
 }
 
 type mapSorter struct {
@@ -145,3 +285,70 @@ func (ms mapSorter) less(v reflect.Value, i, j int) bool {
 	vx, vy := v.Index(i).Field(0), v.Index(j).Field(0)
 	return ms.fnc.Call([]reflect.Value{vx, vy})[0].Bool()
 }
+
+// MatchSlicesByKey returns a Transformer option that converts []V into a
+// map[K]V, where the key of each element is computed by the key function.
+// The key function must be of the form "func(T) K" which is used to
+// transform any slice with element type V that is assignable to T,
+// where K must be a comparable type.
+//
+// Elements are thus matched by key regardless of their order within the slice,
+// and the difference is reported in terms of keys only in x, keys only in y,
+// and keys whose elements differ (along with the difference within the element)
+// rather than in terms of positional edits to the slice.
+//
+// The transformation only applies if the keys of the elements within each slice
+// are unique and comparable (which may not be the case if K is an interface).
+// Otherwise, the slices are compared positionally as usual.
+//
+// MatchSlicesByKey can be used in conjunction with EquateEmpty.
+func MatchSlicesByKey(keyFunc interface{}) cmp.Option {
+	vf := reflect.ValueOf(keyFunc)
+	if t := vf.Type(); t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 1 ||
+		t.IsVariadic() || !t.Out(0).Comparable() || vf.IsNil() {
+		panic(fmt.Sprintf("invalid key function: %T", keyFunc))
+	}
+	sm := sliceMatcher{vf.Type().In(0), vf}
+	return describe(cmp.FilterValues(sm.filter, cmp.Transformer("cmpopts.MatchSlicesByKey", sm.match)), "MatchSlicesByKey", keyFunc)
+}
+
+type sliceMatcher struct {
+	in  reflect.Type  // T
+	fnc reflect.Value // func(T) K
+}
+
+func (sm sliceMatcher) filter(x, y interface{}) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	if !(x != nil && y != nil && vx.Type() == vy.Type()) ||
+		!(vx.Kind() == reflect.Slice && vx.Type().Elem().AssignableTo(sm.in)) ||
+		(vx.Len() == 0 && vy.Len() == 0) {
+		return false
+	}
+	return sm.hasUniqueKeys(vx) && sm.hasUniqueKeys(vy)
+}
+func (sm sliceMatcher) hasUniqueKeys(v reflect.Value) bool {
+	seen := make(map[interface{}]bool, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		kv := sm.key(v.Index(i))
+		if kv.Kind() == reflect.Interface && !kv.IsNil() && !kv.Elem().Type().Comparable() {
+			return false // Keys of an interface type may hold incomparable values
+		}
+		k := kv.Interface()
+		if seen[k] {
+			return false
+		}
+		seen[k] = true
+	}
+	return true
+}
+func (sm sliceMatcher) match(x interface{}) interface{} {
+	src := reflect.ValueOf(x)
+	dst := reflect.MakeMap(reflect.MapOf(sm.fnc.Type().Out(0), src.Type().Elem()))
+	for i := 0; i < src.Len(); i++ {
+		dst.SetMapIndex(sm.key(src.Index(i)), src.Index(i))
+	}
+	return dst.Interface()
+}
+func (sm sliceMatcher) key(v reflect.Value) reflect.Value {
+	return sm.fnc.Call([]reflect.Value{v})[0]
+}
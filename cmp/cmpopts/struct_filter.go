@@ -27,6 +27,27 @@ func filterField(typ interface{}, name string, opt cmp.Option) cmp.Option {
 	return cmp.FilterPath(sf.filter, opt)
 }
 
+// FilterTaggedFields returns a new Option where opt is only evaluated on
+// struct fields whose struct tag satisfies the predicate f
+// (e.g., all fields tagged `json:"-"` or `cmp:"ignore"`).
+// This allows a single option to follow the schema of the compared types
+// instead of listing the names of the fields. Only the tag of the
+// immediate field is considered, such that the descendants of a
+// selected field are not themselves selected.
+//
+// The predicate f must be deterministic and is called for every struct field
+// in the value trees, including unexported fields.
+func FilterTaggedFields(f func(reflect.StructTag) bool, opt cmp.Option) cmp.Option {
+	if f == nil {
+		panic("invalid tag filter function: <nil>")
+	}
+	tf := func(p cmp.Path) bool {
+		sf, ok := p.Last().(cmp.StructField)
+		return ok && f(sf.Tag())
+	}
+	return describe(cmp.FilterPath(tf, opt), "FilterTaggedFields", f, opt)
+}
+
 type structFilter struct {
 	t  reflect.Type // The root struct type to match on
 	ft fieldTree    // Tree of fields to match on
@@ -6,9 +6,12 @@ package cmpopts
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"reflect"
 	"strings"
@@ -49,6 +52,7 @@ type (
 	}
 
 	privateStruct struct{ Public, private int }
+	noCopy        struct{}
 	PublicStruct  struct{ Public, private int }
 	ParentStruct  struct {
 		*privateStruct
@@ -304,6 +308,168 @@ func TestOptions(t *testing.T) {
 		},
 		wantEqual: true,
 		reason:    "no panics because EquateEmpty should compose with the sort options",
+	}, {
+		label:     "EquateUnorderedMapOfSlices",
+		x:         map[string][]int{"a": {3, 1, 2}, "b": {}, "c": nil},
+		y:         map[string][]int{"a": {1, 2, 3}},
+		opts:      []cmp.Option{EquateUnorderedMapOfSlices()},
+		wantEqual: true,
+		reason:    "equal because slices are unordered and missing keys are equal to empty slices",
+	}, {
+		label:     "EquateUnorderedMapOfSlices",
+		x:         map[string][]int{"a": {}},
+		y:         map[string][]int(nil),
+		opts:      []cmp.Option{EquateUnorderedMapOfSlices()},
+		wantEqual: true,
+		reason:    "equal because a map with only empty slices is equal to a nil map",
+	}, {
+		label:     "EquateUnorderedMapOfSlices",
+		x:         map[string][]int{"a": {3, 1, 2}, "b": {4}},
+		y:         map[string][]int{"a": {1, 2, 3}, "b": {4, 4}},
+		opts:      []cmp.Option{EquateUnorderedMapOfSlices()},
+		wantEqual: false,
+		reason:    "not equal because duplicate elements are significant",
+	}, {
+		label:     "EquateUnorderedMapOfSlices",
+		x:         map[string][]int{"a": {3, 1, 2}},
+		y:         map[string][]int{"b": {1, 2, 3}},
+		opts:      []cmp.Option{EquateUnorderedMapOfSlices()},
+		wantEqual: false,
+		reason:    "not equal because the elements are under different keys",
+	}, {
+		label: "EquateUnorderedMapOfSlices",
+		x:     MyStruct{A: []int{3, 1, 2}, C: map[time.Time]string{}},
+		y:     MyStruct{A: []int{1, 2, 3}},
+		opts: []cmp.Option{
+			EquateUnorderedMapOfSlices(),
+		},
+		wantEqual: false,
+		reason:    "not equal because slices and maps outside a map of slices are unaffected",
+	}, {
+		label: "EquateUnorderedMapOfSlices+EquateApprox",
+		x:     map[int][]float64{1: {3.0, 1.0}, 2: nil},
+		y:     map[int][]float64{1: {1.0001, 2.9999}},
+		opts: []cmp.Option{
+			EquateUnorderedMapOfSlices(),
+			EquateApprox(0.001, 0),
+		},
+		wantEqual: true,
+		reason:    "equal because elements are still compared using other options",
+	}, {
+		label:     "EquateUnorderedMapOfSlices",
+		x:         map[string][]Foo1{"a": {{Alpha: 1}, {Alpha: 2}, {Alpha: 1}}, "b": nil},
+		y:         map[string][]Foo1{"a": {{Alpha: 2}, {Alpha: 1}, {Alpha: 1}}},
+		opts:      []cmp.Option{EquateUnorderedMapOfSlices()},
+		wantEqual: true,
+		reason:    "equal because slices of comparable structs are matched as multisets",
+	}, {
+		label:     "EquateUnorderedMapOfSlices",
+		x:         map[string][]Foo1{"a": {{Alpha: 1}, {Alpha: 2}}},
+		y:         map[string][]Foo1{"a": {{Alpha: 2}, {Alpha: 2}}},
+		opts:      []cmp.Option{EquateUnorderedMapOfSlices()},
+		wantEqual: false,
+		reason:    "not equal because the multisets of elements differ",
+	}, {
+		label:     "EquateUnorderedMapOfSlices",
+		x:         map[string][]interface{}{"a": {[]int{1}, 2}},
+		y:         map[string][]interface{}{"a": {2, []int{1}}},
+		opts:      []cmp.Option{EquateUnorderedMapOfSlices()},
+		wantEqual: false,
+		reason:    "not equal because slices with incomparable elements are compared positionally",
+	}, {
+		label:     "MatchSlicesByKey",
+		x:         []Foo1{{Alpha: 1, Bravo: 1}, {Alpha: 2, Bravo: 2}},
+		y:         []Foo1{{Alpha: 2, Bravo: 2}, {Alpha: 1, Bravo: 1}},
+		opts:      []cmp.Option{MatchSlicesByKey(func(f Foo1) int { return f.Alpha })},
+		wantEqual: true,
+		reason:    "equal because elements are matched by key regardless of order",
+	}, {
+		label:     "MatchSlicesByKey",
+		x:         []Foo1{{Alpha: 1, Bravo: 1}, {Alpha: 2}},
+		y:         []Foo1{{Alpha: 2}, {Alpha: 1, Bravo: 3}},
+		opts:      []cmp.Option{MatchSlicesByKey(func(f Foo1) int { return f.Alpha })},
+		wantEqual: false,
+		reason:    "not equal because the elements for key 1 differ",
+	}, {
+		label:     "MatchSlicesByKey",
+		x:         []int{1, 1, 2},
+		y:         []int{1, 2, 1},
+		opts:      []cmp.Option{MatchSlicesByKey(func(i int) int { return i })},
+		wantEqual: false,
+		reason:    "not equal because duplicate keys cause the slices to be compared positionally",
+	}, {
+		label:     "MatchSlicesByKey",
+		x:         [][]int{{1}, {2}},
+		y:         [][]int{{2}, {1}},
+		opts:      []cmp.Option{MatchSlicesByKey(func(v []int) interface{} { return v })},
+		wantEqual: false,
+		reason:    "not equal because incomparable interface keys fall back to positional comparison",
+	}, {
+		label: "MatchSlicesByKey+EquateEmpty",
+		x:     []int{},
+		y:     []int(nil),
+		opts: []cmp.Option{
+			MatchSlicesByKey(func(i int) int { return i }),
+			EquateEmpty(),
+		},
+		wantEqual: true,
+		reason:    "equal because EquateEmpty still applies",
+	}, {
+		label:     "EquateStringsWithin",
+		x:         "kitten",
+		y:         "sitting",
+		opts:      []cmp.Option{EquateStringsWithin(3)},
+		wantEqual: true,
+		reason:    "equal because the edit distance is 3",
+	}, {
+		label:     "EquateStringsWithin",
+		x:         []string{"kitten"},
+		y:         []string{"sitting"},
+		opts:      []cmp.Option{EquateStringsWithin(2)},
+		wantEqual: false,
+		reason:    "not equal because the edit distance exceeds 2",
+	}, {
+		label:     "EquateStringsWithin",
+		x:         "日本語",
+		y:         "日本",
+		opts:      []cmp.Option{EquateStringsWithin(1)},
+		wantEqual: true,
+		reason:    "equal because the distance is measured in runes",
+	}, {
+		label:     "EquateNormalizedStrings",
+		x:         []string{"Main St.", "O'Brien"},
+		y:         []string{"main  st", "o brien"},
+		opts:      []cmp.Option{EquateNormalizedStrings()},
+		wantEqual: true,
+		reason:    "equal because case, punctuation, and spacing are ignored",
+	}, {
+		label:     "EquateNormalizedStrings",
+		x:         "main st",
+		y:         "st main",
+		opts:      []cmp.Option{EquateNormalizedStrings()},
+		wantEqual: false,
+		reason:    "not equal because the order of tokens is significant",
+	}, {
+		label:     "EquateTokenSets",
+		x:         "Smith, John",
+		y:         "john smith john",
+		opts:      []cmp.Option{EquateTokenSets(1)},
+		wantEqual: true,
+		reason:    "equal because the order and repetition of tokens are ignored",
+	}, {
+		label:     "EquateTokenSets",
+		x:         "John Smith",
+		y:         "Jon Smith",
+		opts:      []cmp.Option{EquateTokenSets(0.8)},
+		wantEqual: true,
+		reason:    "equal because the token sets are sufficiently similar",
+	}, {
+		label:     "EquateTokenSets",
+		x:         "John Smith",
+		y:         "Jane Doe",
+		opts:      []cmp.Option{EquateTokenSets(0.8)},
+		wantEqual: false,
+		reason:    "not equal because the token sets are dissimilar",
 	}, {
 		label:     "EquateApprox",
 		x:         3.09,
@@ -677,6 +843,54 @@ func TestOptions(t *testing.T) {
 		opts:      []cmp.Option{EquateErrors()},
 		wantEqual: false,
 		reason:    "AnyError is not equal to nil value",
+	}, {
+		label:     "EquateIntegers",
+		x:         int64(5),
+		y:         uint8(5),
+		wantEqual: false,
+		reason:    "values of different types are not equal without EquateIntegers option",
+	}, {
+		label:     "EquateIntegers",
+		x:         int64(5),
+		y:         uint8(5),
+		opts:      []cmp.Option{EquateIntegers()},
+		wantEqual: true,
+		reason:    "mathematically equal integers are equal",
+	}, {
+		label:     "EquateIntegers",
+		x:         []interface{}{int8(-1), uint16(7)},
+		y:         []interface{}{int32(-1), int(7)},
+		opts:      []cmp.Option{EquateIntegers()},
+		wantEqual: true,
+		reason:    "mathematically equal integers within interfaces are equal",
+	}, {
+		label:     "EquateIntegers",
+		x:         int64(-1),
+		y:         uint64(math.MaxUint64),
+		opts:      []cmp.Option{EquateIntegers()},
+		wantEqual: false,
+		reason:    "negative values are not representable as an unsigned integer",
+	}, {
+		label:     "EquateIntegers",
+		x:         uint64(math.MaxUint64),
+		y:         int64(math.MaxInt64),
+		opts:      []cmp.Option{EquateIntegers()},
+		wantEqual: false,
+		reason:    "values larger than math.MaxInt64 are not representable as an int64",
+	}, {
+		label:     "EquateIntegers",
+		x:         uint64(math.MaxInt64),
+		y:         int64(math.MaxInt64),
+		opts:      []cmp.Option{EquateIntegers()},
+		wantEqual: true,
+		reason:    "math.MaxInt64 is representable as both an int64 and uint64",
+	}, {
+		label:     "EquateIntegers",
+		x:         int64(5),
+		y:         float64(5),
+		opts:      []cmp.Option{EquateIntegers()},
+		wantEqual: false,
+		reason:    "floating-point values are not integers",
 	}, {
 		label:     "IgnoreFields",
 		x:         Bar1{Foo3{&Foo2{&Foo1{Alpha: 5}}}},
@@ -804,6 +1018,368 @@ func TestOptions(t *testing.T) {
 		},
 		wantEqual: true,
 		reason:    "equal because mismatching unexported fields are ignored",
+	}, {
+		label: "IgnorePaths",
+		x: map[string][]Foo1{
+			"a": {{Alpha: 1, Bravo: 2}, {Alpha: 3, Bravo: 4}},
+			"b": {{Alpha: 5}},
+		},
+		y: map[string][]Foo1{
+			"a": {{Alpha: 1, Bravo: 20}, {Alpha: 3, Bravo: 40}},
+			"b": {{Alpha: 50}},
+		},
+		opts:      []cmp.Option{IgnorePaths(`["a"][*].Bravo`, `["b"][0]`)},
+		wantEqual: true,
+		reason:    "equal because all mismatching paths are ignored",
+	}, {
+		label: "IgnorePaths",
+		x: map[string][]Foo1{
+			"a": {{Alpha: 1, Bravo: 2}, {Alpha: 3, Bravo: 4}},
+			"b": {{Alpha: 5}},
+		},
+		y: map[string][]Foo1{
+			"a": {{Alpha: 1, Bravo: 20}, {Alpha: 3, Bravo: 40}},
+			"b": {{Alpha: 50}},
+		},
+		opts:      []cmp.Option{IgnorePaths(`["a"][0].Bravo`, `**.Alpha`)},
+		wantEqual: false,
+		reason:    `not equal because ["a"][1].Bravo is not ignored`,
+	}, {
+		label: "FilterTaggedFields",
+		x: struct {
+			A int `json:"-"`
+			B int `json:"b"`
+			C struct {
+				D int `cmp:"ignore"`
+			} `json:"-"`
+		}{A: 1, B: 2},
+		y: struct {
+			A int `json:"-"`
+			B int `json:"b"`
+			C struct {
+				D int `cmp:"ignore"`
+			} `json:"-"`
+		}{A: 3, B: 2, C: struct {
+			D int `cmp:"ignore"`
+		}{D: 4}},
+		opts: []cmp.Option{FilterTaggedFields(func(tag reflect.StructTag) bool {
+			return tag.Get("json") == "-"
+		}, cmp.Ignore())},
+		wantEqual: true,
+		reason:    "equal because fields tagged with json:\"-\" are ignored",
+	}, {
+		label: "FilterTaggedFields",
+		x: struct {
+			A int `json:"-"`
+			B int `json:"b"`
+		}{A: 1, B: 2},
+		y: struct {
+			A int `json:"-"`
+			B int `json:"b"`
+		}{A: 1, B: 3},
+		opts: []cmp.Option{FilterTaggedFields(func(tag reflect.StructTag) bool {
+			return tag.Get("json") == "-"
+		}, cmp.Ignore())},
+		wantEqual: false,
+		reason:    "not equal because untagged fields are still compared",
+	}, {
+		label: "IgnoreTaggedFields",
+		x: struct {
+			A int `cmp:"-"`
+			b int `cmp:"-,other"`
+			C int `cmp:"other"`
+		}{A: 1, b: 2, C: 3},
+		y: struct {
+			A int `cmp:"-"`
+			b int `cmp:"-,other"`
+			C int `cmp:"other"`
+		}{A: 4, b: 5, C: 3},
+		opts:      []cmp.Option{IgnoreTaggedFields("cmp", "-")},
+		wantEqual: true,
+		reason:    "equal because tagged fields are ignored, including unexported fields",
+	}, {
+		label: "IgnoreTaggedFields",
+		x: struct {
+			A int `cmp:"-"`
+			C int `cmp:"other"`
+		}{A: 1, C: 3},
+		y: struct {
+			A int `cmp:"-"`
+			C int `cmp:"other"`
+		}{A: 1, C: 4},
+		opts:      []cmp.Option{IgnoreTaggedFields("cmp", "-")},
+		wantEqual: false,
+		reason:    "not equal because C is not tagged with the ignored value",
+	}, {
+		label: "IgnoreFieldsMatching",
+		x: struct {
+			Name                 string
+			CreatedAt, UpdatedAt time.Time
+			deletedAt            time.Time
+		}{Name: "a", CreatedAt: time.Unix(1, 0), UpdatedAt: time.Unix(2, 0)},
+		y: struct {
+			Name                 string
+			CreatedAt, UpdatedAt time.Time
+			deletedAt            time.Time
+		}{Name: "a", CreatedAt: time.Unix(3, 0), deletedAt: time.Unix(4, 0)},
+		opts: []cmp.Option{IgnoreFieldsMatching(struct {
+			Name                 string
+			CreatedAt, UpdatedAt time.Time
+			deletedAt            time.Time
+		}{}, "(?i)At$")},
+		wantEqual: true,
+		reason:    "equal because all fields ending in At are ignored",
+	}, {
+		label:     "IgnoreFieldsMatching",
+		x:         Bar3{Alpha: "a", Delta: struct{ Echo Foo1 }{Foo1{Alpha: 1}}},
+		y:         Bar3{Alpha: "b", Delta: struct{ Echo Foo1 }{Foo1{Alpha: 2}}},
+		opts:      []cmp.Option{IgnoreFieldsMatching(Bar3{}, "^Alpha$")},
+		wantEqual: false,
+		reason:    "not equal because fields of nested structs of other types are not ignored",
+	}, {
+		label: "IgnoreKinds",
+		x: struct {
+			Name     string
+			Callback func()
+			Done     chan bool
+		}{"a", func() {}, make(chan bool)},
+		y: struct {
+			Name     string
+			Callback func()
+			Done     chan bool
+		}{"a", func() {}, make(chan bool)},
+		opts:      []cmp.Option{IgnoreKinds(reflect.Func, reflect.Chan)},
+		wantEqual: true,
+		reason:    "equal because func and chan fields are ignored",
+	}, {
+		label: "IgnoreKinds",
+		x: struct {
+			Name     string
+			Callback interface{}
+		}{"a", func() {}},
+		y: struct {
+			Name     string
+			Callback interface{}
+		}{"a", func() {}},
+		opts:      []cmp.Option{IgnoreKinds(reflect.Func)},
+		wantEqual: false,
+		reason:    "not equal because the declared kind of the field is an interface",
+	}, {
+		label: "IgnoreFieldKinds",
+		x: struct {
+			F     func()
+			Inner struct{ F func() }
+		}{F: func() {}},
+		y: struct {
+			F     func()
+			Inner struct{ F func() }
+		}{F: func() {}},
+		opts: []cmp.Option{IgnoreFieldKinds(struct {
+			F     func()
+			Inner struct{ F func() }
+		}{}, reflect.Func)},
+		wantEqual: true,
+		reason:    "equal because the func field of the outer struct is ignored and the inner ones are nil",
+	}, {
+		label: "IgnoreFieldKinds",
+		x: struct {
+			F     func()
+			Inner struct{ F func() }
+		}{Inner: struct{ F func() }{func() {}}},
+		y: struct {
+			F     func()
+			Inner struct{ F func() }
+		}{Inner: struct{ F func() }{func() {}}},
+		opts: []cmp.Option{IgnoreFieldKinds(struct {
+			F     func()
+			Inner struct{ F func() }
+		}{}, reflect.Func)},
+		wantEqual: false,
+		reason:    "not equal because the func fields of other struct types are not ignored",
+	}, {
+		label: "IgnoreSyncPrimitives",
+		x: &struct {
+			Name string
+			mu   sync.Mutex
+			rw   *sync.RWMutex
+			once sync.Once
+			wg   sync.WaitGroup
+			nc   noCopy
+		}{Name: "a"},
+		y: &struct {
+			Name string
+			mu   sync.Mutex
+			rw   *sync.RWMutex
+			once sync.Once
+			wg   sync.WaitGroup
+			nc   noCopy
+		}{Name: "a", rw: new(sync.RWMutex)},
+		opts:      []cmp.Option{IgnoreSyncPrimitives()},
+		wantEqual: true,
+		reason:    "equal because synchronization primitives are ignored",
+	}, {
+		label: "IgnoreSyncPrimitives",
+		x: struct {
+			Name string
+			sync.Mutex
+		}{Name: "a"},
+		y: struct {
+			Name string
+			sync.Mutex
+		}{Name: "b"},
+		opts:      []cmp.Option{IgnoreSyncPrimitives()},
+		wantEqual: false,
+		reason:    "not equal because Name differs",
+	}, {
+		label: "IgnoreContexts",
+		x: struct {
+			Ctx  context.Context
+			Ctxs []context.Context
+			Map  map[string]interface{}
+		}{context.Background(), []context.Context{context.TODO()}, map[string]interface{}{"ctx": context.Background()}},
+		y: struct {
+			Ctx  context.Context
+			Ctxs []context.Context
+			Map  map[string]interface{}
+		}{context.WithValue(context.Background(), "key", "value"), []context.Context{context.Background()}, map[string]interface{}{"ctx": context.TODO()}},
+		opts:      []cmp.Option{IgnoreContexts()},
+		wantEqual: true,
+		reason:    "equal because all contexts are ignored",
+	}, {
+		label: "IgnoreTimes",
+		x: struct {
+			Name      string
+			CreatedAt time.Time
+			DeletedAt *time.Time
+			Events    map[string]time.Time
+			Timeout   time.Duration
+		}{"a", time.Unix(1, 0), nil, map[string]time.Time{"start": time.Unix(2, 0)}, time.Second},
+		y: struct {
+			Name      string
+			CreatedAt time.Time
+			DeletedAt *time.Time
+			Events    map[string]time.Time
+			Timeout   time.Duration
+		}{"a", time.Unix(3, 0), new(time.Time), map[string]time.Time{"start": time.Unix(4, 0)}, time.Second},
+		opts:      []cmp.Option{IgnoreTimes()},
+		wantEqual: true,
+		reason:    "equal because all times are ignored",
+	}, {
+		label: "IgnoreTimes",
+		x: struct {
+			CreatedAt time.Time
+			Timeout   time.Duration
+		}{time.Unix(1, 0), time.Second},
+		y: struct {
+			CreatedAt time.Time
+			Timeout   time.Duration
+		}{time.Unix(3, 0), time.Minute},
+		opts:      []cmp.Option{IgnoreTimes()},
+		wantEqual: false,
+		reason:    "not equal because durations are not ignored",
+	}, {
+		label: "IgnoreDurations",
+		x: struct {
+			CreatedAt time.Time
+			Timeout   time.Duration
+		}{time.Unix(1, 0), time.Second},
+		y: struct {
+			CreatedAt time.Time
+			Timeout   time.Duration
+		}{time.Unix(3, 0), time.Minute},
+		opts:      []cmp.Option{IgnoreTimes(), IgnoreDurations()},
+		wantEqual: true,
+		reason:    "equal because both times and durations are ignored",
+	}, {
+		label: "IgnoreTypesByName",
+		x: struct {
+			A privateStruct
+			B *privateStruct
+			C int
+			D time.Duration
+		}{privateStruct{1, 2}, &privateStruct{3, 4}, 5, 6},
+		y: struct {
+			A privateStruct
+			B *privateStruct
+			C int
+			D time.Duration
+		}{privateStruct{7, 8}, &privateStruct{9, 10}, 5, 11},
+		opts: []cmp.Option{IgnoreTypesByName(
+			"github.com/google/go-cmp/cmp/cmpopts.privateStruct",
+			"*github.com/google/go-cmp/cmp/cmpopts.privateStruct",
+			"time.Duration",
+		)},
+		wantEqual: true,
+		reason:    "equal because the types of all differing fields are ignored by name",
+	}, {
+		label: "IgnoreTypesByName",
+		x: struct {
+			B *privateStruct
+			C int
+		}{&privateStruct{Public: 3}, 5},
+		y: struct {
+			B *privateStruct
+			C int
+		}{&privateStruct{Public: 9}, 6},
+		opts:      []cmp.Option{IgnoreTypesByName("github.com/google/go-cmp/cmp/cmpopts.privateStruct", "int64")},
+		wantEqual: false,
+		reason:    "not equal because int is not ignored, even though the pointed-at struct is",
+	}, {
+		label: "IgnoreTypesImplementing",
+		x: struct {
+			Name   string
+			Out    io.Writer
+			Buf    bytes.Buffer
+			Extras []interface{}
+		}{"a", new(bytes.Buffer), *bytes.NewBufferString("x"), []interface{}{ioutil.Discard, 1}},
+		y: struct {
+			Name   string
+			Out    io.Writer
+			Buf    bytes.Buffer
+			Extras []interface{}
+		}{"a", ioutil.Discard, *bytes.NewBufferString("y"), []interface{}{new(bytes.Buffer), 1}},
+		opts:      []cmp.Option{IgnoreTypesImplementing(new(io.Writer))},
+		wantEqual: true,
+		reason:    "equal because all writers are ignored, including those in interface values",
+	}, {
+		label: "IgnoreTypesImplementing",
+		x: struct {
+			Name  string
+			Extra interface{}
+		}{"a", new(bytes.Buffer)},
+		y: struct {
+			Name  string
+			Extra interface{}
+		}{"a", 1},
+		opts:      []cmp.Option{IgnoreTypesImplementing(new(io.Writer))},
+		wantEqual: false,
+		reason:    "not equal because the int in y does not implement io.Writer",
+	}, {
+		label: "IgnoreContexts",
+		x: struct {
+			Ctx  context.Context
+			Name string
+		}{context.Background(), "a"},
+		y: struct {
+			Ctx  context.Context
+			Name string
+		}{context.TODO(), "b"},
+		opts:      []cmp.Option{IgnoreContexts()},
+		wantEqual: false,
+		reason:    "not equal because Name differs",
+	}, {
+		label: "IgnoreContexts",
+		x: struct {
+			context.Context
+			Name string
+		}{context.Background(), "a"},
+		y: struct {
+			context.Context
+			Name string
+		}{context.TODO(), "b"},
+		opts:      []cmp.Option{IgnoreContexts()},
+		wantEqual: false,
+		reason:    "not equal because a struct embedding a context is not itself ignored",
 	}, {
 		label:     "IgnoreTypes",
 		x:         []interface{}{5, "same"},
@@ -1015,6 +1591,45 @@ func TestOptions(t *testing.T) {
 		},
 		wantEqual: false,
 		reason:    "not equal because ignored elements does not imply empty slice",
+	}, {
+		label: "IgnoreSliceElementsAt",
+		x:     []int{9, 1, 2, 3, 7},
+		y:     []int{8, 1, 2, 3, 6},
+		opts: []cmp.Option{
+			IgnoreSliceElementsAt(func(i, v int) bool { return i == 0 || i == 4 }),
+		},
+		wantEqual: true,
+		reason:    "equal because the header and trailer are ignored",
+	}, {
+		label: "IgnoreSliceElementsAt",
+		x:     []int{1, 10, 2, 20},
+		y:     []int{5, 10, 6, 21},
+		opts: []cmp.Option{
+			IgnoreSliceElementsAt(func(i, v int) bool { return i%2 == 0 }),
+		},
+		wantEqual: false,
+		reason:    "not equal because only even indexes are ignored",
+	}, {
+		label:     "IgnoreMapKeys",
+		x:         map[string]int{"one": 1, "etag": 2, "date": 3},
+		y:         map[string]int{"one": 1, "etag": 4},
+		opts:      []cmp.Option{IgnoreMapKeys("etag", "date")},
+		wantEqual: true,
+		reason:    "equal because the etag and date entries are ignored",
+	}, {
+		label:     "IgnoreMapKeys",
+		x:         map[MyString]int{"one": 1, "etag": 2},
+		y:         map[MyString]int{"one": 1, "etag": 4},
+		opts:      []cmp.Option{IgnoreMapKeys("etag")},
+		wantEqual: true,
+		reason:    "equal because MyString keys are converted to string to be looked up",
+	}, {
+		label:     "IgnoreMapKeys",
+		x:         map[int]int{1: 1, 2: 2},
+		y:         map[int]int{1: 1, 2: 4},
+		opts:      []cmp.Option{IgnoreMapKeys("\x02")},
+		wantEqual: false,
+		reason:    "not equal because int keys are not string keys, even though they are convertible",
 	}, {
 		label: "IgnoreMapEntries",
 		x:     map[string]int{"one": 1, "TWO": 2, "three": 3, "FIVE": 5},
@@ -1079,6 +1694,66 @@ func TestOptions(t *testing.T) {
 		},
 		wantEqual: true,
 		reason:    "equal because acyclic transformer splits on any contiguous whitespace",
+	}, {
+		label:     "ZeroFields",
+		x:         Bar3{Alpha: "a", Delta: struct{ Echo Foo1 }{Foo1{Alpha: 1, Bravo: 2}}, Bar1: Bar1{Foo3{&Foo2{&Foo1{Alpha: 3}}}}},
+		y:         Bar3{Alpha: "b", Delta: struct{ Echo Foo1 }{Foo1{Alpha: 5, Bravo: 2}}, Bar1: Bar1{Foo3{&Foo2{&Foo1{Alpha: 3}}}}},
+		opts:      []cmp.Option{ZeroFields(Bar3{}, "Alpha", "Delta.Echo.Alpha")},
+		wantEqual: true,
+		reason:    "equal because the differing fields are zeroed",
+	}, {
+		label:     "ZeroFields",
+		x:         Bar3{Alpha: "a", Delta: struct{ Echo Foo1 }{Foo1{Alpha: 1, Bravo: 2}}},
+		y:         Bar3{Alpha: "b", Delta: struct{ Echo Foo1 }{Foo1{Alpha: 1, Bravo: 3}}},
+		opts:      []cmp.Option{ZeroFields(Bar3{}, "Alpha", "Delta.Echo.Alpha")},
+		wantEqual: false,
+		reason:    "not equal because Delta.Echo.Bravo is not zeroed",
+	}, {
+		label: "InterpretBytes",
+		x:     struct{ Data []byte }{[]byte{0, 0, 0, 10, 'a'}},
+		y:     struct{ Data []byte }{[]byte{0, 0, 0, 12, 'a'}},
+		opts: []cmp.Option{
+			InterpretBytes("Data", ByteLayout{{Name: "Length", Size: 4, Order: binary.BigEndian}, {Name: "Payload"}}),
+		},
+		wantEqual: false,
+		reason:    "not equal because the decoded length differs",
+	}, {
+		label: "InterpretBytes",
+		x:     struct{ Data []byte }{[]byte{0x01, 0x02, 0xff, 0xff}},
+		y:     struct{ Data []byte }{[]byte{0x01, 0x02, 0xff, 0xff}},
+		opts: []cmp.Option{
+			InterpretBytes("Data", ByteLayout{{Name: "Flags", Size: 2, Order: binary.LittleEndian}, {Name: "Mask", Size: 2}}),
+		},
+		wantEqual: true,
+		reason:    "equal because the decoded fields are identical",
+	}, {
+		label: "InterpretBytes",
+		x:     struct{ Data []byte }{[]byte{0, 1}},
+		y:     struct{ Data []byte }{[]byte{0, 1, 2}},
+		opts: []cmp.Option{
+			InterpretBytes("Data", ByteLayout{{Name: "Flags", Size: 2, Order: binary.LittleEndian}}),
+		},
+		wantEqual: false,
+		reason:    "not equal because the layout is not applied to values of a mismatching length",
+	}, {
+		label: "InterpretBytes",
+		x:     struct{ Data, Other []byte }{[]byte{0, 1}, []byte{0, 1}},
+		y:     struct{ Data, Other []byte }{[]byte{0, 1}, []byte{0, 2}},
+		opts: []cmp.Option{
+			InterpretBytes("Data", ByteLayout{{Name: "Flags", Size: 2, Order: binary.LittleEndian}}),
+		},
+		wantEqual: false,
+		reason:    "not equal because the layout only applies to the specified path",
+	}, {
+		label: "InterpretBytes",
+		x:     []struct{ Data []byte }{{[]byte{0, 1, 0xff}}, {[]byte{0, 2, 0xff}}},
+		y:     []struct{ Data []byte }{{[]byte{0, 1, 0xfe}}, {[]byte{0, 2, 0xfe}}},
+		opts: []cmp.Option{
+			InterpretBytes("[*].Data", ByteLayout{{Name: "Length", Size: 2, Order: binary.BigEndian}, {Name: "Payload"}}),
+			cmp.FilterPath(func(p cmp.Path) bool { return p.Last().String() == ".Payload" }, cmp.Ignore()),
+		},
+		wantEqual: true,
+		reason:    "equal because the pattern matches the Data field of every element and the payloads are ignored",
 	}}
 
 	for _, tt := range tests {
@@ -1166,6 +1841,30 @@ func TestPanic(t *testing.T) {
 		args:      args((func(_, _ int) bool)(nil)),
 		wantPanic: "invalid less function",
 		reason:    "nil value is not valid",
+	}, {
+		label:     "MatchSlicesByKey",
+		fnc:       MatchSlicesByKey,
+		args:      args(strings.Compare),
+		wantPanic: "invalid key function",
+		reason:    "func(x, y string) int is wrong signature for a key function",
+	}, {
+		label:     "MatchSlicesByKey",
+		fnc:       MatchSlicesByKey,
+		args:      args(func(s string) []byte { return []byte(s) }),
+		wantPanic: "invalid key function",
+		reason:    "[]byte is not a comparable key",
+	}, {
+		label:     "EquateStringsWithin",
+		fnc:       EquateStringsWithin,
+		args:      args(-1),
+		wantPanic: "distance must be a non-negative number",
+		reason:    "negative distance is invalid",
+	}, {
+		label:     "EquateTokenSets",
+		fnc:       EquateTokenSets,
+		args:      args(1.5),
+		wantPanic: "ratio must be within [0, 1]",
+		reason:    "ratio above 1 is invalid",
 	}, {
 		label:     "SortMaps",
 		fnc:       SortMaps,
@@ -1241,6 +1940,82 @@ func TestPanic(t *testing.T) {
 		args:      args(struct{ privateStruct }{}, "private"),
 		wantPanic: "does not exist",
 		reason:    "private field not permitted since it is a forwarded field that is unexported",
+	}, {
+		label:  "IgnorePaths",
+		fnc:    IgnorePaths,
+		reason: "empty input is valid",
+	}, {
+		label:     "IgnorePaths",
+		fnc:       IgnorePaths,
+		args:      args("A.B", "A[*"),
+		wantPanic: "missing closing bracket",
+		reason:    "patterns must be valid",
+	}, {
+		label:     "IgnoreMapKeys",
+		fnc:       IgnoreMapKeys,
+		args:      args(),
+		wantPanic: "no keys provided",
+		reason:    "at least one key is required",
+	}, {
+		label:     "IgnoreMapKeys",
+		fnc:       IgnoreMapKeys,
+		args:      args("a", 1),
+		wantPanic: "mismatching map key types",
+		reason:    "all keys must have the same type",
+	}, {
+		label:     "IgnoreMapKeys",
+		fnc:       IgnoreMapKeys,
+		args:      args([]int{1}),
+		wantPanic: "invalid map key type",
+		reason:    "keys must be comparable",
+	}, {
+		label:     "IgnoreSliceElementsAt",
+		fnc:       IgnoreSliceElementsAt,
+		args:      args(func(k string, v int) bool { return true }),
+		wantPanic: "invalid discard function",
+		reason:    "first argument must be the index",
+	}, {
+		label:     "IgnoreTypesImplementing",
+		fnc:       IgnoreTypesImplementing,
+		args:      args(io.Writer(nil)),
+		wantPanic: "must be a pointer to an interface type",
+		reason:    "input must be a pointer to an interface",
+	}, {
+		label:     "IgnoreTypesImplementing",
+		fnc:       IgnoreTypesImplementing,
+		args:      args(new(bytes.Buffer)),
+		wantPanic: "must be a pointer to an interface type",
+		reason:    "input must be a pointer to an interface",
+	}, {
+		label:     "IgnoreTypesByName",
+		fnc:       IgnoreTypesByName,
+		args:      args("*"),
+		wantPanic: "invalid type name",
+		reason:    "name must not be empty",
+	}, {
+		label:     "IgnoreFieldKinds",
+		fnc:       IgnoreFieldKinds,
+		args:      args(0, reflect.Func),
+		wantPanic: "must be a struct",
+		reason:    "type must be a struct",
+	}, {
+		label:     "IgnoreFieldsMatching",
+		fnc:       IgnoreFieldsMatching,
+		args:      args(Foo1{}, "("),
+		wantPanic: "invalid field name pattern",
+		reason:    "pattern must be a valid regular expression",
+	}, {
+		label:     "IgnoreFieldsMatching",
+		fnc:       IgnoreFieldsMatching,
+		args:      args(&Foo1{}, "A"),
+		wantPanic: "must be a struct",
+		reason:    "type must be a struct",
+	}, {
+		label:     "FilterTaggedFields",
+		fnc:       FilterTaggedFields,
+		args:      args((func(reflect.StructTag) bool)(nil), cmp.Ignore()),
+		wantPanic: "invalid tag filter function",
+		reason:    "predicate must be non-nil",
 	}, {
 		label:  "IgnoreTypes",
 		fnc:    IgnoreTypes,
@@ -1323,6 +2098,59 @@ func TestPanic(t *testing.T) {
 		args:      args("", "not a func"),
 		wantPanic: "invalid transformer function",
 		reason:    "AcyclicTransformer has same input requirements as Transformer",
+	}, {
+		label:     "ZeroFields",
+		fnc:       ZeroFields,
+		args:      args(Bar3{}, "Missing"),
+		wantPanic: "does not exist",
+		reason:    "field must exist",
+	}, {
+		label:     "ZeroFields",
+		fnc:       ZeroFields,
+		args:      args(Bar3{}, "Bravo.Alpha"),
+		wantPanic: "cannot zero field through *cmpopts.Bar2",
+		reason:    "fields cannot be zeroed through a pointer",
+	}, {
+		label:     "ZeroFields",
+		fnc:       ZeroFields,
+		args:      args(PublicStruct{}, "private"),
+		wantPanic: "cannot zero unexported field",
+		reason:    "unexported fields cannot be set",
+	}, {
+		label:     "InterpretBytes",
+		fnc:       InterpretBytes,
+		args:      args("Data", ByteLayout{}),
+		wantPanic: "layout must not be empty",
+		reason:    "empty layout is invalid",
+	}, {
+		label:     "InterpretBytes",
+		fnc:       InterpretBytes,
+		args:      args("Data", ByteLayout{{Name: "length", Size: 4, Order: binary.BigEndian}}),
+		wantPanic: "invalid field name",
+		reason:    "field names must be exported identifiers",
+	}, {
+		label:     "InterpretBytes",
+		fnc:       InterpretBytes,
+		args:      args("Data", ByteLayout{{Name: "Length", Size: 3, Order: binary.BigEndian}}),
+		wantPanic: "invalid size for integer field",
+		reason:    "integers must be 1, 2, 4, or 8 bytes",
+	}, {
+		label:     "InterpretBytes",
+		fnc:       InterpretBytes,
+		args:      args("Data", ByteLayout{{Name: "Payload"}, {Name: "Length", Size: 4, Order: binary.BigEndian}}),
+		wantPanic: "only the last field may consume the remaining bytes",
+		reason:    "a field consuming the remainder must be last",
+	}, {
+		label:  "InterpretBytes",
+		fnc:    InterpretBytes,
+		args:   args("Data", ByteLayout{{Name: "Length", Size: 4, Order: binary.BigEndian}, {Name: "Payload"}}),
+		reason: "length-prefixed payload is valid",
+	}, {
+		label:     "InterpretBytes",
+		fnc:       InterpretBytes,
+		args:      args("Data[", ByteLayout{{Name: "Payload"}}),
+		wantPanic: "invalid path pattern",
+		reason:    "the path pattern must be valid",
 	}}
 
 	for _, tt := range tests {
@@ -1369,3 +2197,75 @@ func TestPanic(t *testing.T) {
 		})
 	}
 }
+
+func TestEquateStringsWithinReport(t *testing.T) {
+	got := cmp.Diff("kitten", "sitting", EquateStringsWithin(1))
+	want := `edit distance 3: "[-k+s]itt[-e+i]n[+g]"`
+	if !strings.Contains(got, want) {
+		t.Errorf("Diff should contain %q:\n%s", want, got)
+	}
+	if d := describeStringDistance("abc", "abc"); d != `edit distance 0: "abc"` {
+		t.Errorf("describeStringDistance(abc, abc) = %q", d)
+	}
+}
+
+func TestEquateIntegersReport(t *testing.T) {
+	got := cmp.Diff([]interface{}{int64(-1), uint8(5)}, []interface{}{uint64(math.MaxUint64), int16(300)}, EquateIntegers())
+	for _, want := range []string{
+		"int64(-1) is not representable as uint64; uint64(18446744073709551615) is not representable as int64",
+		"int16(300) is not representable as uint8",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Diff should contain %q:\n%s", want, got)
+		}
+	}
+	if d := describeMixedIntegers(int32(5), uint8(6)); d != "" {
+		t.Errorf("describeMixedIntegers(5, 6) = %q, want empty", d)
+	}
+}
+
+func TestTokenSetRatio(t *testing.T) {
+	tests := []struct {
+		x, y string
+		want float64
+	}{
+		{"", "", 1},
+		{"a b", "B, A", 1},
+		{"new york mets", "new york mets vs atlanta braves", 1},
+		{"abc", "xyz", 0},
+		{"john smith", "jon smith", 0.9},
+	}
+	for _, tt := range tests {
+		if got := computeTokenSetRatio(tt.x, tt.y); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("computeTokenSetRatio(%q, %q) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+	got := cmp.Diff("John Smith", "Jane Doe", EquateTokenSets(0.8))
+	if !strings.Contains(got, "// token set ratio") {
+		t.Errorf("Diff should be annotated with the token set ratio:\n%s", got)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		opt  cmp.Option
+		want string
+	}{
+		{EquateEmpty(), "cmpopts.EquateEmpty()"},
+		{EquateApprox(0.01, 0), "cmpopts.EquateApprox(0.01, 0)"},
+		{EquateApproxTime(time.Second), "cmpopts.EquateApproxTime(1s)"},
+		{SortSlices(func(x, y int) bool { return x < y }), "cmpopts.SortSlices(cmpopts.TestDescribe.func1)"},
+		{EquateStringsWithin(2), "cmpopts.EquateStringsWithin(2)"},
+		{EquateTokenSets(0.9), "cmpopts.EquateTokenSets(0.9)"},
+		{IgnoreFields(Bar1{}, "Foo3.Alpha", "Bravo"), `cmpopts.IgnoreFields(cmpopts.Bar1, "Foo3.Alpha", "Bravo")`},
+		{IgnoreTypes(0, ""), "cmpopts.IgnoreTypes(int, string)"},
+		{IgnoreUnexported(), "cmpopts.IgnoreUnexported()"},
+		{AcyclicTransformer("Split", strings.Fields), `cmpopts.AcyclicTransformer("Split", strings.Fields)`},
+		{InterpretBytes("Data", ByteLayout{{Name: "Length", Size: 2}, {Name: "Payload"}}), `cmpopts.InterpretBytes("Data", {Length:2, Payload:0})`},
+	}
+	for _, tt := range tests {
+		if got := cmp.DescribeOptions(tt.opt); got != tt.want {
+			t.Errorf("DescribeOptions() = %s, want %s", got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,12 @@
 package cmpopts
 
 import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -31,5 +37,201 @@ func (xf xformFilter) filter(p cmp.Path) bool {
 // infinite cycle converting a string to []string to [][]string and so on.
 func AcyclicTransformer(name string, xformFunc interface{}) cmp.Option {
 	xf := xformFilter{cmp.Transformer(name, xformFunc)}
-	return cmp.FilterPath(xf.filter, xf.xform)
+	return describe(cmp.FilterPath(xf.filter, xf.xform), "AcyclicTransformer", name, xformFunc)
+}
+
+// ZeroFields returns a Transformer option that sets exported fields of the
+// given names to their zero values on a single struct type before comparing,
+// such that they are always equal. The struct type is specified by passing
+// in a value of that type. As with IgnoreFields, the name may be
+// a dot-delimited string (e.g., "Foo.Bar") to zero a sub-field that is
+// embedded or nested within the parent struct, but not through a pointer.
+//
+// Unlike IgnoreFields, the zeroed fields remain visible in the report
+// (as the zero value), which makes the normalization explicit.
+// The input values are not mutated.
+func ZeroFields(typ interface{}, names ...string) cmp.Option {
+	t := reflect.TypeOf(typ)
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("%T must be a struct", typ))
+	}
+	var indexes [][]int
+	for _, name := range names {
+		cname, err := canonicalName(t, name)
+		if err != nil {
+			panic(fmt.Sprintf("%s: %v", strings.Join(cname, "."), err))
+		}
+		var index []int
+		for i, ft := 0, t; i < len(cname); i++ {
+			if ft.Kind() != reflect.Struct {
+				panic(fmt.Sprintf("%s: cannot zero field through %v", strings.Join(cname[:i+1], "."), ft))
+			}
+			sf, _ := ft.FieldByName(cname[i])
+			if sf.PkgPath != "" {
+				panic(fmt.Sprintf("%s: cannot zero unexported field", strings.Join(cname[:i+1], ".")))
+			}
+			index = append(index, sf.Index...)
+			ft = sf.Type
+		}
+		indexes = append(indexes, index)
+	}
+	fnc := reflect.MakeFunc(reflect.FuncOf([]reflect.Type{t}, []reflect.Type{t}, false), func(in []reflect.Value) []reflect.Value {
+		v := reflect.New(t).Elem()
+		v.Set(in[0])
+		for _, index := range indexes {
+			f := v.FieldByIndex(index)
+			f.Set(reflect.Zero(f.Type()))
+		}
+		return []reflect.Value{v}
+	})
+	xf := xformFilter{cmp.Transformer("cmpopts.ZeroFields", fnc.Interface())}
+	args := []interface{}{t}
+	for _, name := range names {
+		args = append(args, name)
+	}
+	return describe(cmp.FilterPath(xf.filter, xf.xform), "ZeroFields", args...)
+}
+
+// ByteField describes a single field within a fixed layout of bytes.
+type ByteField struct {
+	// Name is the name of the field and must be an exported Go identifier.
+	Name string
+
+	// Size is the number of bytes occupied by the field.
+	// A Size of zero consumes all remaining bytes and is only permitted
+	// on the last field in a layout.
+	Size int
+
+	// Order is the byte order used to decode the field as an unsigned integer.
+	// If non-nil, then Size must be 1, 2, 4, or 8.
+	// If nil, then the field is decoded as raw bytes.
+	Order binary.ByteOrder
+}
+
+// ByteLayout is a sequence of fields that make up a fixed layout of bytes.
+type ByteLayout []ByteField
+
+var exportedIdentRx = regexp.MustCompile(`^[\p{Lu}][_\p{L}\p{N}]*$`)
+
+// InterpretBytes returns a Transformer option that decodes []byte values
+// located at paths matching the glob-style pattern (see cmp.CompilePathPattern)
+// according to the layout prior to comparison. For example, "Header.Data"
+// matches the Data field within the Header field of the root,
+// while "Packets[*].Data" matches that field of every element of Packets.
+// It panics if the pattern is invalid.
+//
+// The bytes are decoded into a struct with a field for each ByteField in
+// the layout, such that differences are reported per field
+// (e.g., a length prefix or flags word) rather than as an opaque sequence
+// of bytes. The transformation is only applied if both values have a length
+// that matches the layout. If the last field consumes the remaining bytes,
+// the values only need to be at least as long as the other fields combined.
+//
+// For example, a 4-byte big-endian length followed by a payload:
+//	InterpretBytes("Packet.Data", ByteLayout{
+//		{Name: "Length", Size: 4, Order: binary.BigEndian},
+//		{Name: "Payload"},
+//	})
+func InterpretBytes(path string, layout ByteLayout) cmp.Option {
+	pp, err := cmp.CompilePathPattern(path)
+	if err != nil {
+		panic(err.Error())
+	}
+	bi := newBytesInterpreter(layout)
+	tr := cmp.Transformer("cmpopts.InterpretBytes", bi.fnc.Interface())
+	var fields []string
+	for _, f := range layout {
+		fields = append(fields, fmt.Sprintf("%s:%d", f.Name, f.Size))
+	}
+	desc := fmt.Sprintf("cmpopts.InterpretBytes(%q, {%s})", path, strings.Join(fields, ", "))
+	return cmp.Describe(desc, cmp.FilterPath(pathFilter{pp}.filter, cmp.FilterValues(bi.filter, tr)))
+}
+
+type bytesInterpreter struct {
+	layout    ByteLayout
+	size      int  // Total size of all fixed-size fields
+	remainder bool // Whether the last field consumes the remaining bytes
+	typ       reflect.Type
+	fnc       reflect.Value // func([]byte) struct{...}
+}
+
+func newBytesInterpreter(layout ByteLayout) *bytesInterpreter {
+	if len(layout) == 0 {
+		panic("layout must not be empty")
+	}
+	bi := &bytesInterpreter{layout: layout}
+	var fields []reflect.StructField
+	seen := map[string]bool{}
+	for i, f := range layout {
+		if !exportedIdentRx.MatchString(f.Name) {
+			panic(fmt.Sprintf("invalid field name: %q", f.Name))
+		}
+		if seen[f.Name] {
+			panic(fmt.Sprintf("duplicate field name: %q", f.Name))
+		}
+		seen[f.Name] = true
+
+		var t reflect.Type
+		switch {
+		case f.Size < 0:
+			panic(fmt.Sprintf("invalid size for field %q: %d", f.Name, f.Size))
+		case f.Size == 0 && i < len(layout)-1:
+			panic(fmt.Sprintf("only the last field may consume the remaining bytes: %q", f.Name))
+		case f.Size == 0 && f.Order != nil:
+			panic(fmt.Sprintf("field %q with a byte order must have a fixed size", f.Name))
+		case f.Order == nil:
+			t = reflect.TypeOf([]byte(nil))
+		case f.Size == 1:
+			t = reflect.TypeOf(uint8(0))
+		case f.Size == 2:
+			t = reflect.TypeOf(uint16(0))
+		case f.Size == 4:
+			t = reflect.TypeOf(uint32(0))
+		case f.Size == 8:
+			t = reflect.TypeOf(uint64(0))
+		default:
+			panic(fmt.Sprintf("invalid size for integer field %q: %d", f.Name, f.Size))
+		}
+		bi.size += f.Size
+		bi.remainder = f.Size == 0
+		fields = append(fields, reflect.StructField{Name: f.Name, Type: t})
+	}
+	bi.typ = reflect.StructOf(fields)
+	ft := reflect.FuncOf([]reflect.Type{reflect.TypeOf([]byte(nil))}, []reflect.Type{bi.typ}, false)
+	bi.fnc = reflect.MakeFunc(ft, func(in []reflect.Value) []reflect.Value {
+		return []reflect.Value{bi.decode(in[0].Bytes())}
+	})
+	return bi
+}
+
+func (bi *bytesInterpreter) filter(x, y []byte) bool {
+	if bi.remainder {
+		return len(x) >= bi.size && len(y) >= bi.size
+	}
+	return len(x) == bi.size && len(y) == bi.size
+}
+
+func (bi *bytesInterpreter) decode(b []byte) reflect.Value {
+	v := reflect.New(bi.typ).Elem()
+	for i, f := range bi.layout {
+		n := f.Size
+		if n == 0 {
+			n = len(b)
+		}
+		fb := b[:n:n]
+		b = b[n:]
+		switch {
+		case f.Order == nil:
+			v.Field(i).SetBytes(fb)
+		case n == 1:
+			v.Field(i).SetUint(uint64(fb[0]))
+		case n == 2:
+			v.Field(i).SetUint(uint64(f.Order.Uint16(fb)))
+		case n == 4:
+			v.Field(i).SetUint(uint64(f.Order.Uint32(fb)))
+		case n == 8:
+			v.Field(i).SetUint(f.Order.Uint64(fb))
+		}
+	}
+	return v
 }
@@ -0,0 +1,113 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Package cmptest provides helpers for tests that make assertions about
+// the differences reported by the cmp package.
+//
+// The helpers operate on the structured result of cmp.Compare rather than
+// the textual report, which is not stable and may change over time.
+// This is particularly useful for authors of custom cmp options, who need to
+// verify which parts of a value an option does or does not affect.
+package cmptest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestingT is the subset of testing.TB used by this package.
+// If the value also has a Helper method, then it is called.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// PathString formats p relative to the root value as a sequence of
+// struct field accesses, slice and map indexes, and type assertions
+// (e.g., "Foo.Bar[2]" or `Foo.Map["key"].(int)`).
+// Pointer indirections are implicit, the root step is omitted, and
+// transformations are formatted as a method call (e.g., "Foo.SortSlices()").
+//
+// A slice element that only exists in one of the values is formatted
+// using the notation of cmp.SliceIndex.String (e.g., "Foo[2->?]").
+func PathString(p cmp.Path) string {
+	var ss []string
+	for i, s := range p {
+		switch s := s.(type) {
+		case cmp.StructField:
+			ss = append(ss, "."+s.Name())
+		case cmp.Indirect:
+			// Implicit, similar to how Go automatically dereferences pointers.
+		case cmp.Transform:
+			ss = append(ss, "."+s.Name()+"()")
+		default:
+			if i > 0 {
+				ss = append(ss, s.String())
+			}
+		}
+	}
+	return strings.TrimPrefix(strings.Join(ss, ""), ".")
+}
+
+// DiffPaths returns the path strings (see PathString) of all differences
+// in r in the order that they were encountered.
+func DiffPaths(r cmp.DiffResult) []string {
+	var ss []string
+	for _, d := range r.Differences {
+		ss = append(ss, PathString(d.Path))
+	}
+	return ss
+}
+
+// DiffContainsPath reports whether r contains a difference at path or at
+// any path beneath it, where path is formatted according to PathString.
+// For example, "Foo" contains both "Foo" and "Foo.Bar[2]", but not "FooBar".
+// If not, then it also reports a test error through t.
+func DiffContainsPath(t TestingT, r cmp.DiffResult, path string) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	ss := DiffPaths(r)
+	for _, s := range ss {
+		if hasPathPrefix(s, path) {
+			return true
+		}
+	}
+	t.Errorf("difference not found at %s\n%s", path, formatPaths(ss))
+	return false
+}
+
+// DiffOmitsPath reports whether r contains no difference at path or at any
+// path beneath it, where path is formatted according to PathString.
+// If not, then it also reports a test error through t.
+func DiffOmitsPath(t TestingT, r cmp.DiffResult, path string) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	ss := DiffPaths(r)
+	for _, s := range ss {
+		if hasPathPrefix(s, path) {
+			t.Errorf("unexpected difference found at %s\n%s", s, formatPaths(ss))
+			return false
+		}
+	}
+	return true
+}
+
+// hasPathPrefix reports whether path s is equal to or beneath prefix.
+func hasPathPrefix(s, prefix string) bool {
+	prefix = strings.TrimPrefix(prefix, ".")
+	if prefix == "" || s == prefix {
+		return true
+	}
+	return strings.HasPrefix(s, prefix) && strings.ContainsAny(s[len(prefix):][:1], ".[")
+}
+
+func formatPaths(ss []string) string {
+	if len(ss) == 0 {
+		return "no differences were reported"
+	}
+	return fmt.Sprintf("reported differences:\n\t%s", strings.Join(ss, "\n\t"))
+}
@@ -0,0 +1,73 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmptest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeT struct{ errs []string }
+
+func (t *fakeT) Errorf(f string, args ...interface{}) {
+	t.errs = append(t.errs, fmt.Sprintf(f, args...))
+}
+
+type (
+	Root struct {
+		Foo  *Foo
+		Map  map[string]interface{}
+		Name string
+	}
+	Foo struct {
+		Bar []int
+	}
+)
+
+func TestDiffPaths(t *testing.T) {
+	x := Root{Foo: &Foo{Bar: []int{1, 2, 3}}, Map: map[string]interface{}{"k": 1, "same": "s"}, Name: "x"}
+	y := Root{Foo: &Foo{Bar: []int{1, 2, 4}}, Map: map[string]interface{}{"k": 2, "same": "s"}, Name: "x"}
+	r := cmp.Compare(x, y)
+
+	got := fmt.Sprint(DiffPaths(r))
+	want := fmt.Sprint([]string{"Foo.Bar[2]", `Map["k"].(int)`})
+	if got != want {
+		t.Errorf("DiffPaths() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		path         string
+		wantContains bool
+	}{
+		{"", true},
+		{"Foo", true},
+		{".Foo", true},
+		{"Foo.Bar", true},
+		{"Foo.Bar[2]", true},
+		{"Foo.Bar[1]", false},
+		{"Fo", false},
+		{"Map", true},
+		{`Map["k"]`, true},
+		{`Map["same"]`, false},
+		{"Name", false},
+	}
+	for _, tt := range tests {
+		ft := new(fakeT)
+		if got := DiffContainsPath(ft, r, tt.path); got != tt.wantContains || (len(ft.errs) == 0) != tt.wantContains {
+			t.Errorf("DiffContainsPath(%q) = %v with errors %q, want %v", tt.path, got, ft.errs, tt.wantContains)
+		}
+		ft = new(fakeT)
+		if got := DiffOmitsPath(ft, r, tt.path); got == tt.wantContains || (len(ft.errs) == 0) == tt.wantContains {
+			t.Errorf("DiffOmitsPath(%q) = %v with errors %q, want %v", tt.path, got, ft.errs, !tt.wantContains)
+		}
+	}
+
+	ft := new(fakeT)
+	if DiffContainsPath(ft, cmp.Compare(x, x), "Foo") || len(ft.errs) != 1 {
+		t.Errorf("DiffContainsPath on equal values = true, want false")
+	}
+}
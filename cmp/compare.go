@@ -115,7 +115,9 @@ func Diff(x, y interface{}, opts ...Option) string {
 	// Optimization: If there are no other reporters, we can optimize for the
 	// common case where the result is equal (and thus no reported difference).
 	// This avoids the expensive construction of a difference tree.
-	if len(s.reporters) == 0 {
+	// It is skipped when tracing or reporting progress so that each node
+	// is traced and counted exactly once.
+	if len(s.reporters) == 0 && len(s.tracers) == 0 && len(s.progress) == 0 {
 		s.compareAny(rootStep(x, y))
 		if s.result.Equal() {
 			return ""
@@ -123,7 +125,7 @@ func Diff(x, y interface{}, opts ...Option) string {
 		s.result = diff.Result{} // Reset results
 	}
 
-	r := new(defaultReporter)
+	r := &defaultReporter{opts: s.formatOptions()}
 	s.reporters = append(s.reporters, reporter{r})
 	s.compareAny(rootStep(x, y))
 	d := r.String()
@@ -133,6 +135,75 @@ func Diff(x, y interface{}, opts ...Option) string {
 	return d
 }
 
+// DiffN is like Diff, but only reports the first n differences
+// (in the order that they are encountered) and summarizes the remainder.
+// It also returns the total number of differences found, which is zero
+// if and only if Equal returns true for the same input values and options.
+// It panics if n is negative.
+func DiffN(x, y interface{}, n int, opts ...Option) (diff string, total int) {
+	if n < 0 {
+		panic(fmt.Sprintf("invalid number of differences: %d", n))
+	}
+	s := newState(opts)
+	r := &defaultReporter{opts: s.formatOptions()}
+	s.reporters = append(s.reporters, reporter{r})
+	s.compareAny(rootStep(x, y))
+	total = s.result.NumDiff
+	return r.StringN(n), total
+}
+
+// Compare compares x and y and returns both the humanly-readable report
+// (as produced by Diff) and the structured list of differences.
+// The value trees are only traversed once.
+func Compare(x, y interface{}, opts ...Option) DiffResult {
+	s := newState(opts)
+	r := &defaultReporter{opts: s.formatOptions()}
+	var diffs []Difference
+	c := &diffCollector{diffs: &diffs, classifiers: s.classifiers}
+	s.reporters = append(s.reporters, reporter{r}, reporter{c})
+	s.compareAny(rootStep(x, y))
+	numIgnored, numTransformed := r.root.NumIgnored, r.root.NumTransformed
+	d := r.String()
+	if (d == "") != s.result.Equal() || (len(diffs) == 0) != s.result.Equal() {
+		panic("inconsistent difference and equality results")
+	}
+	return DiffResult{Report: d, Differences: diffs, root: r.root, opts: r.opts,
+		numIgnored: numIgnored, numTransformed: numTransformed}
+}
+
+// EqualAll reports whether all values are equal to each other.
+// It is equivalent to calling Equal on the first value and each of the
+// remaining values, and reports true if fewer than two values are provided.
+// Since values are only compared against the first value, options that are
+// not transitive (e.g., cmpopts.EquateApprox) may consider two of the
+// remaining values to be unequal even if EqualAll reports true.
+func EqualAll(values []interface{}, opts ...Option) bool {
+	for i := 1; i < len(values); i++ {
+		if !Equal(values[0], values[i], opts...) {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffAll returns a human-readable report of the differences between the first
+// value, which is used as the reference, and each of the remaining values.
+// For each value that diverges from the reference, the report contains
+// a header identifying its index followed by the output of Diff for
+// the reference and that value. It returns an empty string if and only if
+// EqualAll returns true for the same input values and options.
+//
+// To use some other value as the reference, move it to the front of values.
+func DiffAll(values []interface{}, opts ...Option) string {
+	var ss []string
+	for i := 1; i < len(values); i++ {
+		if d := Diff(values[0], values[i], opts...); d != "" {
+			ss = append(ss, fmt.Sprintf("values[%d] (-values[0] +values[%d]):\n%s", i, i, d))
+		}
+	}
+	return strings.Join(ss, "")
+}
+
 // rootStep constructs the first path step. If x and y have differing types,
 // then they are stored within an empty interface type.
 func rootStep(x, y interface{}) PathStep {
@@ -168,6 +239,8 @@ type state struct {
 	curPath   Path        // The current path in the value tree
 	curPtrs   pointerPath // The current set of visited pointers
 	reporters []reporter  // Optional reporters
+	tracers   []tracer    // Optional tracers
+	curOpt    Option      // The top-level option currently being applied
 
 	// recChecker checks for infinite cycles applying the same set of
 	// transformers upon the output of itself.
@@ -177,16 +250,36 @@ type state struct {
 	// It is safe for statelessCompare to mutate this value.
 	dynChecker dynChecker
 
+	// progress is the list of OnProgress callbacks, which are called
+	// according to the number of visited nodes counted by numVisited.
+	// It is safe for statelessCompare to mutate numVisited.
+	progress   []progressReporter
+	numVisited int
+
 	// These fields, once set by processOption, will not change.
-	exporters []exporter // List of exporters for structs with unexported fields
-	opts      Options    // List of all fundamental and filter options
+	exporters   []exporter      // List of exporters for structs with unexported fields
+	reportOpts  []*reportOption // List of options for formatting the report
+	opts        Options         // List of all fundamental and filter options
+	failFast    bool            // Whether to stop at the first difference
+	skipPrivate bool            // Whether to ignore inaccessible unexported fields
+	classifiers []classifier    // List of functions to classify differences
+	collectors  []collector     // List of destinations for collected differences
+	stats       []*Stats        // List of destinations for comparison counters
+	stableDiff  bool            // Whether to compute stable edit-scripts
 }
 
 func newState(opts []Option) *state {
 	// Always ensure a validator option exists to validate the inputs.
 	s := &state{opts: Options{validator{}}}
 	s.curPtrs.Init()
+	if defaults, _ := defaultOptions.Load().(Options); len(defaults) > 0 && !hasWithoutDefaults(opts) {
+		s.processOption(defaults)
+	}
 	s.processOption(Options(opts))
+	for _, c := range s.collectors {
+		s.reporters = append(s.reporters, reporter{&diffCollector{diffs: c.diffs, classifiers: s.classifiers}})
+	}
+	s.stableDiff = s.formatOptions().Deterministic
 	return s
 }
 
@@ -205,10 +298,44 @@ func (s *state) processOption(opt Option) {
 			panic(fmt.Sprintf("cannot use an unfiltered option: %v", opt))
 		}
 		s.opts = append(s.opts, opt)
+	case *describedOption:
+		n := len(s.opts)
+		s.processOption(opt.opt)
+		if isCoreOption(opt.opt) {
+			// Keep the description so that Result.Option reports it.
+			s.opts = append(s.opts[:n], opt)
+		}
+	case withoutDefaults:
+	case failFast:
+		s.failFast = true
+	case ignoreAllUnexported:
+		s.skipPrivate = true
+	case classifier:
+		s.classifiers = append(s.classifiers, opt)
+	case collector:
+		s.collectors = append(s.collectors, opt)
+	case equalityCollector:
+		s.reporters = append(s.reporters, reporter{&equalityRecorder{eqs: opt.eqs}})
+	case progressReporter:
+		s.progress = append(s.progress, opt)
+	case tracer:
+		s.tracers = append(s.tracers, opt)
+	case statsCollector:
+		*opt.stats = Stats{}
+		s.stats = append(s.stats, opt.stats)
 	case exporter:
 		s.exporters = append(s.exporters, opt)
 	case reporter:
+		sk, _ := opt.reporterIface.(stepSkipper)
+		st, _ := opt.reporterIface.(statsReporter)
+		if sk != nil || st != nil {
+			opt = reporter{&extendedReporter{reporterIface: opt.reporterIface, skipper: sk, stater: st}}
+		}
 		s.reporters = append(s.reporters, opt)
+	case *reportOption:
+		s.reportOpts = append(s.reportOpts, opt)
+	case valueReportOption:
+		s.reportOpts = append(s.reportOpts, opt.reportOption)
 	default:
 		panic(fmt.Sprintf("unknown option %T", opt))
 	}
@@ -223,16 +350,21 @@ func (s *state) statelessCompare(step PathStep) diff.Result {
 	// It is an implementation bug if the contents of the paths differ from
 	// when calling this function to when returning from it.
 
-	oldResult, oldReporters := s.result, s.reporters
+	oldResult, oldReporters, oldTracers := s.result, s.reporters, s.tracers
 	s.result = diff.Result{} // Reset result
 	s.reporters = nil        // Remove reporters to avoid spurious printouts
+	s.tracers = nil
 	s.compareAny(step)
 	res := s.result
-	s.result, s.reporters = oldResult, oldReporters
+	s.result, s.reporters, s.tracers = oldResult, oldReporters, oldTracers
 	return res
 }
 
 func (s *state) compareAny(step PathStep) {
+	if s.failFast && s.result.NumDiff > 0 {
+		return // Skip all nodes after the first difference
+	}
+
 	// Update the path stack.
 	s.curPath.push(step)
 	defer s.curPath.pop()
@@ -241,9 +373,26 @@ func (s *state) compareAny(step PathStep) {
 		defer r.PopStep()
 	}
 	s.recChecker.Check(s.curPath)
+	for _, st := range s.stats {
+		st.NumVisited++
+		if d := len(s.curPath) - 1; d > st.MaxDepth {
+			st.MaxDepth = d
+		}
+	}
+	if len(s.progress) > 0 {
+		s.numVisited++
+		for _, p := range s.progress {
+			if s.numVisited%p.n == 0 {
+				p.f(Progress{NumVisited: s.numVisited, Path: s.curPath})
+			}
+		}
+	}
 
 	// Cycle-detection for slice elements (see NOTE in compareSlice).
 	t := step.Type()
+	if len(s.tracers) > 0 {
+		s.tracef("visit %v", t)
+	}
 	vx, vy := step.Values()
 	if si, ok := step.(SliceIndex); ok && si.isSlice && vx.IsValid() && vy.IsValid() {
 		px, py := vx.Addr(), vy.Addr()
@@ -299,8 +448,19 @@ func (s *state) compareAny(step PathStep) {
 
 func (s *state) tryOptions(t reflect.Type, vx, vy reflect.Value) bool {
 	// Evaluate all filters and apply the remaining options.
-	if opt := s.opts.filter(s, t, vx, vy); opt != nil {
-		opt.apply(s, vx, vy)
+	if opt, src := s.opts.filterSource(s, t, vx, vy); opt != nil {
+		if len(s.tracers) > 0 {
+			s.tracef("apply %v", opt)
+		}
+		switch opt.(type) {
+		case ignore, *comparer:
+			// These immediately report a result determined by src.
+			s.curOpt = src
+			opt.apply(s, vx, vy)
+			s.curOpt = nil
+		default:
+			opt.apply(s, vx, vy)
+		}
 		return true
 	}
 	return false
@@ -313,6 +473,9 @@ func (s *state) tryMethod(t reflect.Type, vx, vy reflect.Value) bool {
 		return false
 	}
 
+	for _, st := range s.stats {
+		st.NumEqualCalls++
+	}
 	eq := s.callTTBFunc(m.Func, vx, vy)
 	s.report(eq, reportByMethod)
 	return true
@@ -392,10 +555,11 @@ func (s *state) compareStruct(t reflect.Type, vx, vy reflect.Value) {
 	var mayForce, mayForceInit bool
 	step := StructField{&structField{}}
 	for i := 0; i < t.NumField(); i++ {
-		step.typ = t.Field(i).Type
+		step.field = t.Field(i)
+		step.typ = step.field.Type
 		step.vx = vx.Field(i)
 		step.vy = vy.Field(i)
-		step.name = t.Field(i).Name
+		step.name = step.field.Name
 		step.idx = i
 		step.unexported = !isExported(step.name)
 		if step.unexported {
@@ -414,7 +578,7 @@ func (s *state) compareStruct(t reflect.Type, vx, vy reflect.Value) {
 			}
 			if !mayForceInit {
 				for _, xf := range s.exporters {
-					mayForce = mayForce || xf(t)
+					mayForce = mayForce || xf.fnc(t)
 				}
 				mayForceInit = true
 			}
@@ -422,7 +586,6 @@ func (s *state) compareStruct(t reflect.Type, vx, vy reflect.Value) {
 			step.paddr = addr
 			step.pvx = vax
 			step.pvy = vay
-			step.field = t.Field(i)
 		}
 		s.compareAny(step)
 	}
@@ -489,7 +652,11 @@ func (s *state) compareSlice(t reflect.Type, vx, vy reflect.Value) {
 	}
 
 	// Compute an edit-script for slices vx and vy (excluding ignored elements).
-	edits := diff.Difference(len(indexesX), len(indexesY), func(ix, iy int) diff.Result {
+	difference := diff.Difference
+	if s.stableDiff {
+		difference = diff.StableDifference
+	}
+	edits := difference(len(indexesX), len(indexesY), func(ix, iy int) diff.Result {
 		return s.statelessCompare(withIndexes(indexesX[ix], indexesY[iy]))
 	})
 
@@ -593,6 +760,12 @@ func (s *state) compareInterface(t reflect.Type, vx, vy reflect.Value) {
 }
 
 func (s *state) report(eq bool, rf resultFlags) {
+	s.reportBy(eq, rf, nil)
+}
+
+// reportBy is like report, but also records the option that
+// determined the result.
+func (s *state) reportBy(eq bool, rf resultFlags, opt Option) {
 	if rf&reportByIgnore == 0 {
 		if eq {
 			s.result.NumSame++
@@ -602,8 +775,15 @@ func (s *state) report(eq bool, rf resultFlags) {
 			rf |= reportUnequal
 		}
 	}
+	if len(s.tracers) > 0 {
+		s.traceResult(rf, opt)
+	}
+	var src Option
+	if opt != nil {
+		src = s.curOpt
+	}
 	for _, r := range s.reporters {
-		r.Report(Result{flags: rf})
+		r.Report(Result{flags: rf, opt: opt, src: src})
 	}
 }
 
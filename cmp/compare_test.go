@@ -188,6 +188,1098 @@ func TestDiff(t *testing.T) {
 	}
 }
 
+func TestDiffN(t *testing.T) {
+	type S struct{ A, B, C, D int }
+	x := []S{{1, 2, 3, 4}, {5, 6, 7, 8}}
+	y := []S{{1, 0, 3, 0}, {5, 0, 7, 0}}
+
+	tests := []struct {
+		n           int
+		wantDiffs   int
+		wantOmitted string
+	}{
+		{n: 0, wantDiffs: 0, wantOmitted: "... 4 more differences omitted\n"},
+		{n: 1, wantDiffs: 1, wantOmitted: "... 3 more differences omitted\n"},
+		{n: 3, wantDiffs: 3, wantOmitted: "... 1 more difference omitted\n"},
+		{n: 4, wantDiffs: 4},
+		{n: 10, wantDiffs: 4},
+	}
+	for _, tt := range tests {
+		got, total := cmp.DiffN(x, y, tt.n)
+		if total != 4 {
+			t.Errorf("DiffN(%d) total = %d, want 4", tt.n, total)
+		}
+		if tt.wantOmitted == "" {
+			if want := cmp.Diff(x, y); got != want {
+				t.Errorf("DiffN(%d) mismatch:\ngot:\n%s\nwant:\n%s", tt.n, got, want)
+			}
+			continue
+		}
+		if !strings.HasSuffix(got, tt.wantOmitted) {
+			t.Errorf("DiffN(%d) = %q, want suffix %q", tt.n, got, tt.wantOmitted)
+		}
+		if n := strings.Count(got, "\n-"); n != tt.wantDiffs {
+			t.Errorf("DiffN(%d) reported %d differences, want %d:\n%s", tt.n, n, tt.wantDiffs, got)
+		}
+	}
+
+	if got, total := cmp.DiffN(x, x, 1); got != "" || total != 0 {
+		t.Errorf("DiffN(x, x) = (%q, %d), want empty", got, total)
+	}
+
+	func() {
+		defer func() {
+			const want = "invalid number of differences: -1"
+			if got := fmt.Sprint(recover()); got != want {
+				t.Errorf("DiffN(-1) panic = %q, want %q", got, want)
+			}
+		}()
+		cmp.DiffN(x, y, -1)
+	}()
+}
+
+func TestDiff3(t *testing.T) {
+	type Config struct {
+		Name    string
+		Port    int
+		Hosts   []string
+		Labels  map[string]string
+		Enabled bool
+	}
+	base := Config{Name: "a", Port: 80, Hosts: []string{"h1"}, Labels: map[string]string{"k": "v"}}
+	mine := Config{Name: "b", Port: 81, Hosts: []string{"h1"}, Labels: map[string]string{"k": "v"}, Enabled: true}
+	theirs := Config{Name: "a", Port: 82, Hosts: []string{"h2"}, Labels: map[string]string{"k": "v"}, Enabled: true}
+
+	type result struct {
+		Path string
+		Kind cmp.ThreeWayKind
+	}
+	var got []result
+	for _, d := range cmp.Diff3(base, mine, theirs) {
+		got = append(got, result{fmt.Sprintf("%v", d.Path), d.Kind})
+	}
+	want := []result{
+		{"Name", cmp.ChangedInMine},
+		{"Port", cmp.Conflicting},
+		{"Enabled", cmp.ChangedInBoth},
+		{"Hosts", cmp.ChangedInTheirs},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Diff3 mismatch (-want +got):\n%s", diff)
+	}
+
+	// Clearing a slice conflicts with modifying an element of it.
+	mine.Hosts = nil
+	got = nil
+	for _, d := range cmp.Diff3(base, mine, theirs) {
+		got = append(got, result{fmt.Sprintf("%v", d.Path), d.Kind})
+	}
+	want = []result{
+		{"Name", cmp.ChangedInMine},
+		{"Port", cmp.Conflicting},
+		{"Hosts", cmp.Conflicting},
+		{"Enabled", cmp.ChangedInBoth},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Diff3 mismatch (-want +got):\n%s", diff)
+	}
+	var numHosts int
+	for _, d := range cmp.Diff3(base, theirs, mine) {
+		if d.Path.String() == "Hosts" {
+			numHosts++
+			if d.Kind != cmp.Conflicting || d.Mine.Len() != 1 || !d.Theirs.IsNil() {
+				t.Errorf("Diff3(base, theirs, mine) at Hosts = %v (Mine: %v, Theirs: %v), want Conflicting", d.Kind, d.Mine, d.Theirs)
+			}
+		}
+	}
+	if numHosts != 1 {
+		t.Errorf("Diff3(base, theirs, mine) reported %d differences at Hosts, want 1", numHosts)
+	}
+
+	if got := cmp.Diff3(base, base, base); len(got) != 0 {
+		t.Errorf("Diff3(base, base, base) = %v, want empty", got)
+	}
+}
+
+func TestApply(t *testing.T) {
+	type Inner struct {
+		A int
+		B string
+	}
+	type Outer struct {
+		Name   string
+		Ptr    *Inner
+		Iface  interface{}
+		Ints   []int
+		Inners []Inner
+		Array  [3]int
+		Map    map[string]Inner
+	}
+	tests := []struct {
+		label string
+		x, y  interface{}
+		opts  []cmp.Option
+	}{{
+		label: "Identical",
+		x:     Outer{Name: "a"},
+		y:     Outer{Name: "a"},
+	}, {
+		label: "Leaves",
+		x:     Outer{Name: "a", Ptr: &Inner{A: 1}, Iface: 5, Array: [3]int{1, 2, 3}},
+		y:     Outer{Name: "b", Ptr: &Inner{A: 2, B: "b"}, Iface: "five", Array: [3]int{1, 3, 3}},
+	}, {
+		label: "Slices",
+		x:     Outer{Ints: []int{1, 2, 3, 4, 5}, Inners: []Inner{{A: 1}, {A: 2}, {A: 3}}},
+		y:     Outer{Ints: []int{0, 1, 3, 4, 6, 7}, Inners: []Inner{{A: 1}, {A: 2, B: "x"}}},
+	}, {
+		label: "Maps",
+		x:     Outer{Map: map[string]Inner{"a": {A: 1}, "b": {A: 2}}},
+		y:     Outer{Map: map[string]Inner{"a": {A: 1, B: "a"}, "c": {A: 3}}},
+	}, {
+		label: "NilToNonNil",
+		x:     &Outer{},
+		y:     &Outer{Ptr: &Inner{}, Ints: []int{}, Map: map[string]Inner{}},
+	}, {
+		label: "DifferentTypes",
+		x:     5,
+		y:     "five",
+	}, {
+		label: "Ignored",
+		x:     Inner{A: 1, B: "x"},
+		y:     Inner{A: 2, B: "y"},
+		opts:  []cmp.Option{cmpopts.IgnoreFields(Inner{}, "B")},
+	}, {
+		label: "IgnoredSliceElements",
+		x:     []int{1, 2, 3},
+		y:     []int{1, 5, 4},
+		opts:  []cmp.Option{cmpopts.IgnoreSliceElements(func(v int) bool { return v == 2 })},
+	}, {
+		label: "IgnoredSliceElements",
+		x:     []int{2, 1, 3, 2},
+		y:     []int{0, 1, 4, 2, 5},
+		opts:  []cmp.Option{cmpopts.IgnoreSliceElements(func(v int) bool { return v == 2 })},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			xs := fmt.Sprintf("%+v", tt.x)
+			got := cmp.Apply(tt.x, cmp.Compare(tt.x, tt.y, tt.opts...))
+			if diff := cmp.Diff(tt.y, got, tt.opts...); diff != "" {
+				t.Errorf("Apply mismatch (-want +got):\n%s", diff)
+			}
+			if xs2 := fmt.Sprintf("%+v", tt.x); xs != xs2 {
+				t.Errorf("Apply mutated input:\ngot:  %s\nwant: %s", xs2, xs)
+			}
+		})
+	}
+
+	t.Run("Transform", func(t *testing.T) {
+		defer func() {
+			if ex := recover(); ex == nil || !strings.Contains(fmt.Sprint(ex), "unsupported cmp.Transform step") {
+				t.Errorf("Apply panic = %v, want unsupported Transform step", ex)
+			}
+		}()
+		opt := cmpopts.AcyclicTransformer("Split", func(s string) []string { return strings.Split(s, ",") })
+		cmp.Apply("a,b", cmp.Compare("a,b", "a,c", opt))
+	})
+}
+
+func TestCompare(t *testing.T) {
+	type S struct {
+		A int
+		B []string
+		C map[string]int
+	}
+	x := S{A: 1, B: []string{"a", "b"}, C: map[string]int{"k": 1}}
+	y := S{A: 2, B: []string{"a", "c"}, C: map[string]int{"k": 1, "z": 2}}
+
+	got := cmp.Compare(x, y)
+	if want := cmp.Diff(x, y); got.Report != want {
+		t.Errorf("Report mismatch:\ngot:\n%s\nwant:\n%s", got.Report, want)
+	}
+	var gotPaths []string
+	for _, d := range got.Differences {
+		gotPaths = append(gotPaths, fmt.Sprintf("%#v", d.Path))
+	}
+	wantPaths := []string{"{cmp_test.S}.A", "{cmp_test.S}.B[1]", `{cmp_test.S}.C["z"]`}
+	if diff := cmp.Diff(wantPaths, gotPaths); diff != "" {
+		t.Errorf("Differences paths mismatch (-want +got):\n%s", diff)
+	}
+	if d := got.Differences[0]; d.X.Int() != 1 || d.Y.Int() != 2 {
+		t.Errorf("Differences[0] values = (%v, %v), want (1, 2)", d.X, d.Y)
+	}
+	if d := got.Differences[2]; d.X.IsValid() || d.Y.Int() != 2 {
+		t.Errorf("Differences[2] values = (%v, %v), want (<invalid>, 2)", d.X, d.Y)
+	}
+	if got.Equal() {
+		t.Errorf("Equal() = true, want false")
+	}
+	if got := cmp.Compare(x, x); !got.Equal() || got.Report != "" {
+		t.Errorf("Compare(x, x) = %+v, want equal result", got)
+	}
+}
+
+func TestDiffResultInvert(t *testing.T) {
+	type S struct {
+		A int
+		B string
+		C map[string]int
+		D *int
+	}
+	x := S{A: 1, B: "x", C: map[string]int{"k": 1, "x": 2}}
+	y := S{A: 2, B: "y", C: map[string]int{"k": 2, "y": 3}, D: newInt(5)}
+
+	got := cmp.Compare(x, y).Invert()
+	if want := cmp.Diff(y, x); got.Report != want {
+		t.Errorf("Invert().Report mismatch:\ngot:\n%s\nwant:\n%s", got.Report, want)
+	}
+	want := cmp.Compare(y, x)
+	if len(got.Differences) != len(want.Differences) {
+		t.Fatalf("len(Invert().Differences) = %d, want %d", len(got.Differences), len(want.Differences))
+	}
+	for i := range got.Differences {
+		gd, wd := got.Differences[i], want.Differences[i]
+		if gp, wp := fmt.Sprintf("%#v", gd.Path), fmt.Sprintf("%#v", wd.Path); gp != wp {
+			t.Errorf("Differences[%d].Path = %v, want %v", i, gp, wp)
+		}
+		if gx, wx := fmt.Sprint(gd.X), fmt.Sprint(wd.X); gx != wx {
+			t.Errorf("Differences[%d].X = %v, want %v", i, gx, wx)
+		}
+		if gv, _ := gd.Path.Last().Values(); fmt.Sprint(gv) != fmt.Sprint(gd.X) {
+			t.Errorf("Differences[%d].Path.Last().Values() = %v, want %v", i, gv, gd.X)
+		}
+	}
+
+	if orig, inv2 := cmp.Compare(x, y), got.Invert(); orig.Report != inv2.Report {
+		t.Errorf("Invert().Invert().Report mismatch:\ngot:\n%s\nwant:\n%s", inv2.Report, orig.Report)
+	}
+}
+
+func TestDiffResultJSON(t *testing.T) {
+	type S struct {
+		A int
+		B []string
+		C map[string]int
+	}
+	x := S{A: 1, B: []string{"a", "b"}, C: map[string]int{"k": 1}}
+	y := S{A: 2, B: []string{"a", "b"}, C: map[string]int{"k": 1, "n": 2}}
+
+	b, err := json.Marshal(cmp.Compare(x, y))
+	if err != nil {
+		t.Fatalf("json.Marshal error: %v", err)
+	}
+	var got struct {
+		Equal       bool
+		Report      string
+		Differences []map[string]string
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	want := []map[string]string{
+		{"path": "{cmp_test.S}.A", "jsonpath": "$.A", "type": "int", "kind": "modified", "severity": "blocking", "x": "1", "y": "2"},
+		{"path": `{cmp_test.S}.C["n"]`, "jsonpath": "$.C.n", "type": "int", "kind": "inserted", "severity": "blocking", "y": "2"},
+	}
+	if got.Equal || got.Report != cmp.Diff(x, y) || !reflect.DeepEqual(got.Differences, want) {
+		t.Errorf("json.Marshal(Compare(x, y)) = %s", b)
+	}
+
+	if b, _ := json.Marshal(cmp.Compare(x, x)); string(b) != `{"version":1,"equal":true,"severity":"none","report":"","differences":[]}` {
+		t.Errorf("json.Marshal(Compare(x, x)) = %s", b)
+	}
+
+	cx, cy := Credentials{User: "u", Password: "hunter2"}, Credentials{User: "u", Password: "hunter3"}
+	e := cmp.Compare(cx, cy, cmp.ReportRedactFields(Credentials{}, "Password")).Encode()
+	if b, _ := json.Marshal(e); strings.Contains(string(b), "hunter") || len(e.Differences) != 1 || *e.Differences[0].X != "<redacted>" {
+		t.Errorf("json.Marshal(Compare(cx, cy, ReportRedactFields(...))) = %s", b)
+	}
+
+	// Redacted fields must not leak through any other rendering of values.
+	mx := map[string]interface{}{"a": cx, "c": []*Credentials{&cx}}
+	my := map[string]interface{}{"b": cy, "c": []*Credentials{&cy}}
+	for _, opt := range []cmp.Option{cmp.ReportJSON(), cmp.ReportGroupByType(), cmp.ReportUnified(3), cmp.ReportSummary(), cmp.ReportMoves()} {
+		if got := cmp.Diff(mx, my, cmp.ReportRedactFields(Credentials{}, "Password"), opt); strings.Contains(got, "hunter") {
+			t.Errorf("Diff(..., ReportRedactFields(...), %v) leaks a redacted field:\n%s", opt, got)
+		}
+	}
+
+	// Values at redacted paths must not leak either, even when printed
+	// as part of a value that only exists in x or y.
+	hx := map[string]interface{}{"a": map[string]string{"Authorization": "hunter2"}, "c": []map[string]string{{"Authorization": "hunter2"}}}
+	hy := map[string]interface{}{"b": map[string]string{"Authorization": "hunter3"}, "c": []map[string]string{{"Authorization": "hunter3"}}}
+	redact := cmp.ReportRedactPaths(`**["Authorization"]`)
+	if b, _ := json.Marshal(cmp.Compare(hx, hy, redact).Encode()); strings.Contains(string(b), "hunter") {
+		t.Errorf("json.Marshal(Compare(hx, hy, ReportRedactPaths(...))) = %s", b)
+	}
+	for _, opt := range []cmp.Option{nil, cmp.ReportJSON(), cmp.ReportGroupByType(), cmp.ReportUnified(3), cmp.ReportSummary(), cmp.ReportMoves()} {
+		if got := cmp.Diff(hx, hy, redact, opt); strings.Contains(got, "hunter") {
+			t.Errorf("Diff(..., ReportRedactPaths(...), %v) leaks a redacted path:\n%s", opt, got)
+		}
+	}
+}
+
+func TestDecodeResult(t *testing.T) {
+	type S struct{ A, B int }
+	r := cmp.Compare(S{1, 2}, S{1, 3})
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("json.Marshal error: %v", err)
+	}
+	got, err := cmp.DecodeResult(b)
+	if err != nil {
+		t.Fatalf("DecodeResult error: %v", err)
+	}
+	if want := r.Encode(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeResult(json.Marshal(r)) = %+v, want %+v", got, want)
+	}
+	if got.Version != cmp.EncodedVersion || got.Report != r.Report || len(got.Differences) != 1 {
+		t.Errorf("DecodeResult(json.Marshal(r)) = %+v", got)
+	}
+
+	for _, in := range []string{`{}`, `{"version":0}`, `{"version":2}`, `{"version":"1"}`, `[`} {
+		if _, err := cmp.DecodeResult([]byte(in)); err == nil {
+			t.Errorf("DecodeResult(%s) error = nil, want non-nil", in)
+		}
+	}
+}
+
+func TestDiffResultSummary(t *testing.T) {
+	type S struct {
+		A int
+		B []int
+		C string
+	}
+	x := S{A: 1, B: []int{1, 2, 3}, C: "c"}
+	y := S{A: 2, B: []int{1, 2, 4}, C: "C"}
+	r := cmp.Compare(x, y, cmp.FilterPath(func(p cmp.Path) bool { return p.Last().String() == ".C" }, cmp.Ignore()))
+	got := r.Summary()
+	if got.NumDiff != 2 || got.NumIgnored != 1 || got.NumTransformed != 0 {
+		t.Errorf("Summary() = %+v, want 2 differences and 1 ignored", got)
+	}
+	if want := "{cmp_test.S}.B[2]"; got.DeepestDiff.GoString() != want {
+		t.Errorf("Summary().DeepestDiff = %#v, want %v", got.DeepestDiff, want)
+	}
+	if got := r.Invert().Summary(); got.NumIgnored != 1 || got.DeepestDiff.GoString() != "{cmp_test.S}.B[2]" {
+		t.Errorf("Invert().Summary() = %v", got)
+	}
+	if got := cmp.Compare(x, x).Summary(); got.NumDiff != 0 || got.DeepestDiff != nil {
+		t.Errorf("Summary() = %+v, want no differences", got)
+	}
+}
+
+func TestDiffResultFingerprint(t *testing.T) {
+	type S struct {
+		P *int
+		M map[string]int
+		F func()
+	}
+	newS := func(p, m int) S {
+		return S{P: &p, M: map[string]int{"k": m}, F: func() {}}
+	}
+	x := newS(1, 1)
+	want := cmp.Compare(x, newS(2, 1)).Fingerprint()
+	if want == "" {
+		t.Fatalf("Fingerprint() is empty for unequal values")
+	}
+	if got := cmp.Compare(newS(1, 1), newS(2, 1), cmp.Verbosity(0), cmp.ReportAddresses()).Fingerprint(); got != want {
+		t.Errorf("Fingerprint() = %v, want %v", got, want)
+	}
+	if got := cmp.Compare(x, newS(1, 2)).Fingerprint(); got == want {
+		t.Errorf("Fingerprint() = %v, want a different fingerprint for a different diff", got)
+	}
+	if got := cmp.Compare(x, newS(3, 1)).Fingerprint(); got == want {
+		t.Errorf("Fingerprint() = %v, want a different fingerprint for different values", got)
+	}
+	if got := cmp.Compare(S{}, S{}).Fingerprint(); got != "" {
+		t.Errorf("Fingerprint() = %v, want empty for equal values", got)
+	}
+}
+
+func TestCollectDifferences(t *testing.T) {
+	type S struct {
+		A int
+		B []string
+	}
+	x := S{A: 1, B: []string{"a", "b"}}
+	y := S{A: 2, B: []string{"a"}}
+	classify := cmp.ClassifyDifferences(func(p cmp.Path) cmp.Severity { return cmp.Warning })
+
+	var got []cmp.Difference
+	if cmp.Equal(x, y, cmp.CollectDifferences(&got), classify) {
+		t.Fatalf("Equal = true, want false")
+	}
+	want := cmp.Compare(x, y, classify).Differences
+	if len(got) != 2 || len(got) != len(want) {
+		t.Fatalf("got %d differences, want 2", len(got))
+	}
+	for i := range got {
+		if got[i].Path.GoString() != want[i].Path.GoString() || got[i].Severity != cmp.Warning {
+			t.Errorf("difference %d = %#v (%v), want %#v (warning)", i, got[i].Path, got[i].Severity, want[i].Path)
+		}
+	}
+	if vx, vy := got[1].X, got[1].Y; vx.String() != "b" || vy.IsValid() {
+		t.Errorf("difference 1 values = (%v, %v), want (b, <invalid>)", vx, vy)
+	}
+
+	got = nil
+	cmp.Diff(x, x, cmp.CollectDifferences(&got))
+	if len(got) != 0 {
+		t.Errorf("got %d differences, want 0", len(got))
+	}
+}
+
+func TestPathPattern(t *testing.T) {
+	type Container struct {
+		Name, Image string
+	}
+	type Spec struct {
+		Containers []Container
+		Labels     map[string]string
+		Ports      map[int]*int
+	}
+	type Object struct {
+		Metadata map[string]string
+		Spec     *Spec
+		Children []*Object
+	}
+	one, two := 1, 2
+	x := Object{
+		Metadata: map[string]string{"a": "1"},
+		Spec: &Spec{
+			Containers: []Container{{"a", "img:1"}, {"b", "img:1"}},
+			Labels:     map[string]string{"app": "x", "tier": "1"},
+			Ports:      map[int]*int{80: &one},
+		},
+		Children: []*Object{{Metadata: map[string]string{"a": "1"}}},
+	}
+	y := Object{
+		Metadata: map[string]string{"a": "2"},
+		Spec: &Spec{
+			Containers: []Container{{"a", "img:2"}, {"c", "img:1"}},
+			Labels:     map[string]string{"app": "y", "tier": "2"},
+			Ports:      map[int]*int{80: &two},
+		},
+		Children: []*Object{{Metadata: map[string]string{"a": "2"}}},
+	}
+
+	tests := []struct {
+		patterns []string
+		want     []string // Remaining differences
+	}{{
+		patterns: nil,
+		want: []string{
+			`Metadata["a"]`, "Spec.Containers[0].Image", "Spec.Containers[1].Name",
+			`Spec.Labels["app"]`, `Spec.Labels["tier"]`, "Spec.Ports[80]", `Children[0].Metadata["a"]`,
+		},
+	}, {
+		patterns: []string{"Spec.Containers[*].Image", "**.Metadata", `.Spec.Labels["app"]`, "Spec.Ports[80]"},
+		want:     []string{"Spec.Containers[1].Name", `Spec.Labels["tier"]`},
+	}, {
+		patterns: []string{"Spec.Containers[1]", "**.Metadata[*]", "*.Labels.**"},
+		want:     []string{"Spec.Containers[0].Image", "Spec.Ports[80]"},
+	}, {
+		patterns: []string{"**"},
+		want:     nil,
+	}}
+	for _, tt := range tests {
+		var opts cmp.Options
+		for _, s := range tt.patterns {
+			p := cmp.MustCompilePathPattern(s)
+			if p.String() != s {
+				t.Errorf("String() = %q, want %q", p.String(), s)
+			}
+			opts = append(opts, cmp.FilterPath(p.Match, cmp.Ignore()))
+		}
+		var got []string
+		for _, d := range cmp.Compare(x, y, opts).Differences {
+			got = append(got, strings.NewReplacer("*", "", "(", "", ")", "", "{cmp_test.Object}.", "").Replace(d.Path.GoString()))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("patterns %q differences mismatch:\ngot:  %q\nwant: %q", tt.patterns, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"A..B", "A[", "A[]", "A.1", "A]", `A["]"`, "A.B-C", "A.(int)", "{T}.(int"} {
+		if _, err := cmp.CompilePathPattern(s); err == nil {
+			t.Errorf("CompilePathPattern(%q) succeeded, want error", s)
+		}
+	}
+	if _, err := cmp.CompilePathPattern(`M["a]\"b"][pkg.T{A:[]int{1}}]`); err != nil {
+		t.Errorf("CompilePathPattern error: %v", err)
+	}
+}
+
+func TestPathPatternParse(t *testing.T) {
+	type Inner struct {
+		Ints []int
+		Map  map[int]string
+	}
+	type Outer struct {
+		P     *Inner
+		PP    **Inner
+		Iface interface{}
+		Any   map[string]interface{}
+		Strs  []string
+	}
+	newOuter := func(n int) *Outer {
+		in := &Inner{Ints: []int{1, 2, n}, Map: map[int]string{1: "a", n: "b"}}
+		return &Outer{
+			P:     in,
+			PP:    &in,
+			Iface: Inner{Ints: []int{n}},
+			Any:   map[string]interface{}{"a.b]": in, "c": n},
+			Strs:  []string{"a", "b", fmt.Sprint(n), "d"}[:n],
+		}
+	}
+	x, y := newOuter(3), newOuter(4)
+	trOpt := cmp.Transformer("cmp_test.Sort", func(in []int) []int { return append([]int(nil), in...) })
+
+	// stepsKey formats the steps that a pattern considers significant.
+	stepsKey := func(p cmp.Path) string {
+		var ss []string
+		for _, s := range p {
+			switch s.(type) {
+			case cmp.StructField, cmp.SliceIndex, cmp.MapIndex, cmp.TypeAssertion:
+				ss = append(ss, s.String())
+			}
+		}
+		return p.Index(0).Type().String() + ":" + strings.Join(ss, "")
+	}
+	var pats []*cmp.PathPattern
+	var keys []string
+	cmp.Equal(x, y, trOpt, cmp.FilterPath(func(p cmp.Path) bool {
+		pat, err := cmp.CompilePathPattern(p.GoString())
+		if err != nil {
+			t.Errorf("CompilePathPattern(%q) error: %v", p.GoString(), err)
+			return false
+		}
+		pats = append(pats, pat)
+		keys = append(keys, stepsKey(p))
+		return false
+	}, cmp.Ignore()))
+	if len(pats) < 30 {
+		t.Fatalf("only visited %d paths", len(pats))
+	}
+
+	// Only GoString round-trips through slice elements and map entries,
+	// since String omits the index and key.
+	cmp.Equal(x, y, cmp.FilterPath(func(p cmp.Path) bool {
+		if _, ok := p.Last().(cmp.MapIndex); ok && len(p) == 4 {
+			if !cmp.MustCompilePathPattern(p.GoString()).Match(p) {
+				t.Errorf("pattern %q does not match %#v", p.GoString(), p)
+			}
+			if cmp.MustCompilePathPattern(p.String()).Match(p) {
+				t.Errorf("pattern %q unexpectedly matches %#v", p.String(), p)
+			}
+		}
+		return false
+	}, cmp.Ignore()))
+	cmp.Equal(x, y, trOpt, cmp.FilterPath(func(p cmp.Path) bool {
+		key := stepsKey(p)
+		for i, pat := range pats {
+			if got, want := pat.Match(p), keys[i] == key; got != want {
+				t.Errorf("CompilePathPattern(%q).Match(%#v) = %v, want %v", pat, p, got, want)
+			}
+		}
+		return false
+	}, cmp.Ignore()))
+
+	for _, tt := range []struct {
+		pattern string
+		match   bool
+	}{
+		{"{*cmp_test.Outer}.Strs[3->?]", true},
+		{"{*cmp_test.Outer}.Strs[3]", false},
+		{"{*cmp_test.Outer}.Strs[?->3]", false},
+		{"{cmp_test.Outer}.Strs[3->?]", false},
+		{"root.Strs[3->?]", true},
+		{"Strs[3->?]", true},
+		{"Strs[*]", true},
+	} {
+		var got bool
+		cmp.Equal(y, &Outer{Strs: y.Strs[:3]}, cmp.FilterPath(func(p cmp.Path) bool {
+			if p.GoString() == "{*cmp_test.Outer}.Strs[3->?]" {
+				got = cmp.MustCompilePathPattern(tt.pattern).Match(p)
+			}
+			return false
+		}, cmp.Ignore()))
+		if got != tt.match {
+			t.Errorf("CompilePathPattern(%q).Match = %v, want %v", tt.pattern, got, tt.match)
+		}
+	}
+}
+
+func TestPathGoExpr(t *testing.T) {
+	type Spec struct{ Name string }
+	type Item struct {
+		ID    int
+		Spec  *Spec
+		Attrs map[string]interface{}
+	}
+	type List struct {
+		Items *[]Item
+		Tags  []string
+	}
+	newList := func(name string, n int, tags ...string) List {
+		items := []Item{{ID: 1, Spec: &Spec{Name: name}, Attrs: map[string]interface{}{"n": n, "m": 0}}}
+		return List{Items: &items, Tags: tags}
+	}
+	x := newList("a", 1, "t1", "t2", "t3")
+	y := newList("b", 2, "t0", "t1", "t2")
+
+	var got []string
+	for _, d := range cmp.Compare(x, y).Differences {
+		got = append(got, d.Path.GoExpr("got"))
+	}
+	want := []string{
+		"(*got.Items)[0].Spec.Name",
+		`(*got.Items)[0].Attrs["n"].(int)`,
+		"got.Tags[0]",
+		"got.Tags[2]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GoExpr mismatch:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestPathJSONPath(t *testing.T) {
+	type Spec struct {
+		Name  string `json:"name,omitempty"`
+		Image string `json:"-"`
+		Port  int
+	}
+	type Doc struct {
+		Items []*Spec `json:"items"`
+		Extra map[string]interface{}
+		Codes map[int]string
+		YAML  map[interface{}]interface{}
+	}
+	x := Doc{
+		Items: []*Spec{{Name: "a", Image: "i", Port: 1}},
+		Extra: map[string]interface{}{"a.b": []interface{}{1.0}, "it's": "x"},
+		Codes: map[int]string{1: "a"},
+		YAML:  map[interface{}]interface{}{"name": "a", 2: "a", true: "a"},
+	}
+	y := Doc{
+		Items: []*Spec{{Name: "b", Image: "j", Port: 1}},
+		Extra: map[string]interface{}{"a.b": []interface{}{2.0}, "it's": "y"},
+		Codes: map[int]string{1: "b"},
+		YAML:  map[interface{}]interface{}{"name": "b", 2: "b", true: "b"},
+	}
+
+	var got []string
+	for _, d := range cmp.Compare(x, y).Differences {
+		got = append(got, d.Path.JSONPath())
+	}
+	want := []string{
+		"$.items[0].name",
+		"$.items[0].Image",
+		"$.Extra['a.b'][0]",
+		`$.Extra['it\'s']`,
+		"$.Codes[1]",
+		"$.YAML['true']",
+		"$.YAML[2]",
+		"$.YAML.name",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSONPath mismatch:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestStructFieldTag(t *testing.T) {
+	type S struct {
+		A int `cmp:"ignore"`
+		B int `json:"b"`
+		c int `cmp:"ignore"`
+	}
+	ignoreTagged := cmp.FilterPath(func(p cmp.Path) bool {
+		sf, ok := p.Last().(cmp.StructField)
+		return ok && sf.Tag().Get("cmp") == "ignore"
+	}, cmp.Ignore())
+	if !cmp.Equal(S{1, 2, 3}, S{4, 2, 5}, ignoreTagged) {
+		t.Errorf("Equal = false, want true")
+	}
+	if cmp.Equal(S{1, 2, 3}, S{1, 5, 3}, ignoreTagged, cmp.AllowUnexported(S{})) {
+		t.Errorf("Equal = true, want false")
+	}
+	var got []reflect.StructField
+	cmp.Equal(S{}, S{}, cmp.FilterPath(func(p cmp.Path) bool {
+		if sf, ok := p.Last().(cmp.StructField); ok {
+			got = append(got, sf.Field())
+		}
+		return false
+	}, cmp.Ignore()), cmp.AllowUnexported(S{}))
+	if len(got) != 3 || got[1].Name != "B" || got[1].Tag.Get("json") != "b" || got[2].PkgPath == "" {
+		t.Errorf("StructField.Field = %+v", got)
+	}
+}
+
+func TestDifferenceKind(t *testing.T) {
+	x := map[string]int{"apple": 1, "banana": 2, "cherry": 3}
+	y := map[string]int{"apple": 1, "banana": 20, "date": 4}
+	got := map[string]cmp.DifferenceKind{}
+	for _, d := range cmp.Compare(x, y).Differences {
+		got[d.Path.Last().(cmp.MapIndex).Key().String()] = d.Kind()
+	}
+	want := map[string]cmp.DifferenceKind{"banana": cmp.Modified, "cherry": cmp.OnlyInX, "date": cmp.OnlyInY}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Kind mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectStats(t *testing.T) {
+	type S struct {
+		A time.Time
+		B string
+		C map[string]int
+	}
+	x := S{B: "b", C: map[string]int{"k": 1}}
+	y := S{B: "B", C: map[string]int{"k": 1}}
+	opts := []cmp.Option{
+		cmp.Comparer(func(x, y int) bool { return x == y }),
+		cmp.Transformer("Lower", strings.ToLower),
+	}
+
+	var got cmp.Stats
+	if !cmp.Equal(x, y, append(opts, cmp.CollectStats(&got))...) {
+		t.Fatalf("Equal = false, want true")
+	}
+	want := cmp.Stats{
+		NumVisited:          7, // root, A, B, Lower, Lower (to verify determinism), C, C["k"]
+		MaxDepth:            2, // B -> Lower
+		NumComparerCalls:    1,
+		NumEqualCalls:       1,
+		NumTransformerCalls: 2,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Stats mismatch (-want +got):\n%s", diff)
+	}
+
+	// Each call resets the counters.
+	cmp.Equal(S{}, S{}, cmp.CollectStats(&got))
+	if got.NumVisited != 4 || got.MaxDepth != 1 {
+		t.Errorf("Stats = %+v, want 4 visited nodes with a depth of 1", got)
+	}
+}
+
+func TestOnProgress(t *testing.T) {
+	x := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		x[i] = true
+	}
+	var got []string
+	cmp.Equal(x, x, cmp.OnProgress(40, func(p cmp.Progress) {
+		got = append(got, fmt.Sprintf("%d: %#v", p.NumVisited, p.Path))
+	}))
+	want := []string{"40: {map[int]bool}[38]", "80: {map[int]bool}[78]"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("progress mismatch (-want +got):\n%s", diff)
+	}
+
+	// Diff must count each node once, despite its equality pre-pass.
+	got = nil
+	cmp.Diff(x, x, cmp.OnProgress(40, func(p cmp.Progress) {
+		got = append(got, fmt.Sprintf("%d: %#v", p.NumVisited, p.Path))
+	}))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Diff progress mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	type S struct {
+		A int
+		B string
+	}
+	isB := func(p cmp.Path) bool { return p.Last().String() == ".B" }
+	var got bytes.Buffer
+	cmp.Equal(S{1, "x"}, S{2, "y"}, cmp.FilterPath(isB, cmp.Ignore()), cmp.Trace(&got))
+	want := strings.Join([]string{
+		"{cmp_test.S}: visit cmp_test.S",
+		"{cmp_test.S}.A: visit int",
+		"{cmp_test.S}.A: unequal",
+		"{cmp_test.S}.B: visit string",
+		"{cmp_test.S}.B: filter FilterPath(cmp_test.TestTrace.func1, Ignore()) matched",
+		"{cmp_test.S}.B: apply Ignore()",
+		"{cmp_test.S}.B: ignored by Ignore()",
+	}, "\n") + "\n"
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("Trace mismatch (-want +got):\n%s", diff)
+	}
+
+	// Diff must trace each node once, despite its equality pre-pass.
+	got.Reset()
+	cmp.Diff(S{1, "x"}, S{2, "y"}, cmp.FilterPath(isB, cmp.Ignore()), cmp.Trace(&got))
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("Diff trace mismatch (-want +got):\n%s", diff)
+	}
+	got.Reset()
+	cmp.Diff(S{1, "x"}, S{1, "x"}, cmp.Trace(&got))
+	if got.Len() == 0 {
+		t.Errorf("Diff of equal values produced no trace")
+	}
+}
+
+func TestCollectEqualities(t *testing.T) {
+	type S struct {
+		Secret []byte
+		When   time.Time
+		Name   string
+		Note   string
+		Count  int
+	}
+	x := S{[]byte("hunter2"), time.Unix(0, 0), "Alice", "a", 1}
+	y := S{[]byte("hunter2"), time.Unix(0, 0), "alice", "b", 2}
+	secure := cmp.Comparer(func(x, y []byte) bool { return string(x) == string(y) })
+	lower := cmp.Transformer("Lower", strings.ToLower)
+	ignoreNote := cmp.FilterPath(func(p cmp.Path) bool { return p.Last().String() == ".Note" }, cmp.Ignore())
+
+	var got []cmp.Equality
+	if cmp.Equal(x, y, secure, lower, ignoreNote, cmp.CollectEqualities(&got)) {
+		t.Fatalf("Equal = true, want false")
+	}
+	type equality struct {
+		Path   string
+		By     cmp.Provenance
+		Option string
+	}
+	var gotEqs []equality
+	for _, e := range got {
+		var opt string
+		if e.Option != nil {
+			opt = fmt.Sprint(e.Option)
+		}
+		gotEqs = append(gotEqs, equality{e.Path.GoString(), e.By, opt})
+	}
+	wantEqs := []equality{
+		{"{cmp_test.S}.Secret", cmp.EqualByComparer, "Comparer(cmp_test.TestCollectEqualities.func1)"},
+		{"{cmp_test.S}.When", cmp.EqualByMethod, ""},
+		{"Lower({cmp_test.S}.Name)", cmp.EqualByStructure, ""},
+		{"Lower({cmp_test.S}.Name)", cmp.EqualByTransformer, "Transformer(Lower, strings.ToLower)"},
+		{"{cmp_test.S}.Note", cmp.EqualByIgnore, fmt.Sprint(ignoreNote)},
+	}
+	if diff := cmp.Diff(wantEqs, gotEqs); diff != "" {
+		t.Errorf("CollectEqualities mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIgnoreAllUnexported(t *testing.T) {
+	type S struct {
+		A int
+		b int
+	}
+	x, y := S{1, 2}, S{1, 3}
+	if !cmp.Equal(x, y, cmp.IgnoreAllUnexported()) {
+		t.Errorf("Equal = false, want true")
+	}
+	if cmp.Equal(x, y, cmp.IgnoreAllUnexported(), cmp.AllowUnexported(S{})) {
+		t.Errorf("Equal with AllowUnexported = true, want false")
+	}
+}
+
+func TestReportDeterministicPointerKeys(t *testing.T) {
+	type K struct{ A int }
+	newMap := func(reverse bool, v int) map[*K]int {
+		ks := []*K{{1}, {2}, {3}}
+		if reverse {
+			ks = []*K{{3}, {2}, {1}}
+		}
+		m := make(map[*K]int)
+		for _, k := range ks {
+			m[k] = k.A * v
+		}
+		return m
+	}
+	want := cmp.Diff(newMap(false, 1), newMap(false, 2), cmp.ReportDeterministic())
+	if strings.Contains(want, "0x") {
+		t.Errorf("Diff contains a raw address:\n%s", want)
+	}
+	if got := cmp.Diff(newMap(true, 1), newMap(true, 2), cmp.ReportDeterministic()); got != want {
+		t.Errorf("Diff depends on allocation order:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffResultSeverity(t *testing.T) {
+	type S struct {
+		Version  string
+		Comment  string
+		Replicas int
+	}
+	classify := cmp.ClassifyDifferences(func(p cmp.Path) cmp.Severity {
+		switch p.Last().String() {
+		case ".Comment":
+			return cmp.Informational
+		case ".Version":
+			return cmp.Warning
+		}
+		return cmp.Blocking
+	})
+	base := S{"v1", "initial", 3}
+
+	tests := []struct {
+		label      string
+		y          S
+		opts       []cmp.Option
+		wantSev    cmp.Severity
+		wantInfo   bool
+		wantBlocks bool
+	}{
+		{"Equal", base, []cmp.Option{classify}, cmp.NoSeverity, true, false},
+		{"Informational", S{"v1", "changed", 3}, []cmp.Option{classify}, cmp.Informational, true, false},
+		{"Warning", S{"v2", "changed", 3}, []cmp.Option{classify}, cmp.Warning, false, false},
+		{"Blocking", S{"v2", "changed", 4}, []cmp.Option{classify}, cmp.Blocking, false, true},
+		{"Unclassified", S{"v1", "changed", 3}, nil, cmp.Blocking, false, true},
+		{"MostSevere", S{"v1", "changed", 3}, []cmp.Option{classify, cmp.ClassifyDifferences(func(cmp.Path) cmp.Severity {
+			return cmp.Warning
+		})}, cmp.Warning, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			r := cmp.Compare(base, tt.y, tt.opts...)
+			if got := r.Severity(); got != tt.wantSev {
+				t.Errorf("Severity() = %v, want %v", got, tt.wantSev)
+			}
+			if got := r.OnlyInformational(); got != tt.wantInfo {
+				t.Errorf("OnlyInformational() = %v, want %v", got, tt.wantInfo)
+			}
+			if got := r.HasBlocking(); got != tt.wantBlocks {
+				t.Errorf("HasBlocking() = %v, want %v", got, tt.wantBlocks)
+			}
+			if got := r.Invert().Severity(); got != tt.wantSev {
+				t.Errorf("Invert().Severity() = %v, want %v", got, tt.wantSev)
+			}
+		})
+	}
+}
+
+func TestDiffAll(t *testing.T) {
+	type S struct{ A, B int }
+	values := []interface{}{S{1, 2}, S{1, 2}, S{1, 3}, S{1, 2}, S{0, 2}}
+	if cmp.EqualAll(values) {
+		t.Errorf("EqualAll = true, want false")
+	}
+	got := cmp.DiffAll(values)
+	want := "values[2] (-values[0] +values[2]):\n" + cmp.Diff(values[0], values[2]) +
+		"values[4] (-values[0] +values[4]):\n" + cmp.Diff(values[0], values[4])
+	if got != want {
+		t.Errorf("DiffAll mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	for _, vs := range [][]interface{}{nil, {S{}}, {S{1, 2}, S{1, 2}, S{1, 2}}} {
+		if !cmp.EqualAll(vs) {
+			t.Errorf("EqualAll(%v) = false, want true", vs)
+		}
+		if d := cmp.DiffAll(vs); d != "" {
+			t.Errorf("DiffAll(%v) = %q, want empty", vs, d)
+		}
+	}
+
+	opt := cmp.Comparer(func(x, y S) bool { return x.A == y.A })
+	if !cmp.EqualAll(values[:4], opt) || cmp.DiffAll(values[:4], opt) != "" {
+		t.Errorf("EqualAll with Comparer = false, want true")
+	}
+}
+
+type countingReporter struct{ numReports int }
+
+func (r *countingReporter) PushStep(cmp.PathStep) {}
+func (r *countingReporter) Report(cmp.Result)     { r.numReports++ }
+func (r *countingReporter) PopStep()              {}
+
+func TestFailFast(t *testing.T) {
+	x := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	y := []int{0, 1, 9, 3, 4, 9, 6, 7}
+
+	if cmp.Equal(x, y, cmp.FailFast()) {
+		t.Errorf("Equal(x, y, FailFast()) = true, want false")
+	}
+	if !cmp.Equal(x, x, cmp.FailFast()) {
+		t.Errorf("Equal(x, x, FailFast()) = false, want true")
+	}
+
+	var all, fast countingReporter
+	cmp.Equal(x, y, cmp.Reporter(&all))
+	cmp.Equal(x, y, cmp.Reporter(&fast), cmp.FailFast())
+	if fast.numReports >= all.numReports {
+		t.Errorf("FailFast reported %d nodes, want fewer than %d", fast.numReports, all.numReports)
+	}
+
+	got := cmp.Diff(x, y, cmp.FailFast())
+	if !strings.Contains(got, "2,") || strings.Contains(got, "5,") {
+		t.Errorf("Diff with FailFast should only report the first difference:\n%s", got)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	type node struct {
+		Name     string
+		Next     *node
+		Tags     map[string][]int
+		Skip     int
+		Upper    string
+		When     time.Time
+		private  bool
+		Children []interface{}
+	}
+	n := &node{Name: "root", Tags: map[string][]int{"b": {2}, "a": {1}}, Upper: "x", private: true}
+	n.Next = n
+	n.Children = []interface{}{1, "two"}
+
+	var got []string
+	cmp.Walk(n, func(p cmp.Path, v reflect.Value) bool {
+		got = append(got, fmt.Sprintf("%#v", p))
+		return p.Last().Type() != reflect.TypeOf([]int(nil))
+	},
+		cmp.AllowUnexported(node{}),
+		cmp.FilterPath(func(p cmp.Path) bool { return p.Last().String() == ".Skip" }, cmp.Ignore()),
+		cmpopts.AcyclicTransformer("ToUpper", strings.ToUpper),
+	)
+	want := []string{
+		"{*cmp_test.node}",
+		"*{*cmp_test.node}",
+		"{*cmp_test.node}.Name",
+		"ToUpper({*cmp_test.node}.Name)",
+		"{*cmp_test.node}.Next",
+		"{*cmp_test.node}.Tags",
+		`{*cmp_test.node}.Tags["a"]`,
+		`{*cmp_test.node}.Tags["b"]`,
+		"{*cmp_test.node}.Upper",
+		"ToUpper({*cmp_test.node}.Upper)",
+		"{*cmp_test.node}.When",
+		"{*cmp_test.node}.private",
+		"{*cmp_test.node}.Children",
+		"{*cmp_test.node}.Children[0]",
+		"{*cmp_test.node}.Children[0].(int)",
+		"{*cmp_test.node}.Children[1]",
+		"{*cmp_test.node}.Children[1].(string)",
+		"ToUpper({*cmp_test.node}.Children[1].(string))",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Walk paths mismatch (-want +got):\n%s", diff)
+	}
+
+	gotPanic := func() (s string) {
+		defer func() { s, _ = recover().(string) }()
+		cmp.Walk(n, func(cmp.Path, reflect.Value) bool { return true })
+		return ""
+	}()
+	if !strings.Contains(gotPanic, "cannot handle unexported field") {
+		t.Errorf("Walk panic mismatch: got %q, want unexported field panic", gotPanic)
+	}
+
+	got = nil
+	cmp.Walk(struct {
+		Public  int
+		private int
+	}{}, func(p cmp.Path, v reflect.Value) bool {
+		got = append(got, fmt.Sprintf("%#v", p))
+		return true
+	}, cmp.IgnoreAllUnexported())
+	want = []string{"root", "root.Public"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Walk paths with IgnoreAllUnexported mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func comparerTests() []test {
 	const label = "Comparer"
 
@@ -1184,9 +2276,861 @@ using the AllowUnexported option.`, "\n"),
 		y:         MyComposite{},
 		wantEqual: false,
 		reason:    "batched diffing for empty slices and nil slices",
+	}, {
+		label: label + "/UnsignedNegativeHint",
+		x: struct {
+			A uint32
+			B uint64
+			C uint64
+		}{math.MaxUint32 - 3, math.MaxUint64, 5},
+		y: struct {
+			A uint32
+			B uint64
+			C uint64
+		}{3, 1 << 63, math.MaxUint64 - 1<<20},
+		wantEqual: false,
+		reason:    "unsigned values near their maximum should be annotated with the equivalent signed value",
+	}, {
+		label: label + "/BitDiffs",
+		x: struct {
+			Header  [4]byte
+			Flags   *[2]byte
+			Payload []byte
+			Other   []byte
+		}{[4]byte{0x45, 0x00, 0x00, 0x54}, &[2]byte{0x40, 0x00}, []byte("abc"), []byte("ab")},
+		y: struct {
+			Header  [4]byte
+			Flags   *[2]byte
+			Payload []byte
+			Other   []byte
+		}{[4]byte{0x45, 0x00, 0x00, 0x55}, &[2]byte{0x00, 0x00}, []byte("abd"), []byte("abc")},
+		opts:      []cmp.Option{cmp.ReportBitDiffs()},
+		wantEqual: false,
+		reason:    "byte arrays and slices of equal length should be annotated with the differing bits",
+	}, {
+		label:     label + "/BitDiffsRoot",
+		x:         [4]byte{0x45, 0x00, 0x00, 0x54},
+		y:         [4]byte{0x45, 0x00, 0x00, 0xff},
+		opts:      []cmp.Option{cmp.ReportBitDiffs()},
+		wantEqual: false,
+		reason:    "the root node should be annotated with the differing bits",
+	}, {
+		label: label + "/ReportTransformer",
+		x: struct {
+			ID    string
+			Owner string
+			Tags  []string
+		}{"5c9a1f0e-8d4b-4c2a-9f61-3e0b7d2a6c15", "alice", []string{"a"}},
+		y: struct {
+			ID    string
+			Owner string
+			Tags  []string
+		}{"7e3b2d91-1a6f-4f0c-b8d2-94c5e1a07b3e", "bob", []string{"b", "c"}},
+		opts: []cmp.Option{
+			cmp.ReportTransformer(func(s string) string {
+				if len(s) > 8 {
+					return s[:8] + "…"
+				}
+				return s
+			}),
+			cmp.ReportTransformer(func(s []string) int { return len(s) }),
+		},
+		wantEqual: false,
+		reason:    "long identifiers should be shortened for display and slices should be summarized by their length",
+	}, {
+		label: label + "/ReportTransformerIndistinguishable",
+		x:     map[string]string{"alice": "5c9a1f0e-8d4b-4c2a-9f61-3e0b7d2a6c15"},
+		y:     map[string]string{"alice": "5c9a1f0e-1a6f-4f0c-b8d2-94c5e1a07b3e"},
+		opts: []cmp.Option{
+			cmp.ReportTransformer(func(s string) string {
+				if len(s) > 8 {
+					return s[:8]
+				}
+				return s
+			}),
+		},
+		wantEqual: false,
+		reason:    "the original values should be printed if the transformed values are formatted identically",
+	}, {
+		label: label + "/ReportFormatter",
+		x: struct {
+			ID    UUID
+			Owner UUID
+			Count int
+		}{UUID{0x5c, 0x9a, 0x1f, 0x0e}, UUID{0x7e, 0x3b}, 1},
+		y: struct {
+			ID    UUID
+			Owner UUID
+			Count int
+		}{UUID{0x5c, 0x9a, 0x1f, 0x0f}, UUID{0x7e, 0x3b}, 2},
+		opts: []cmp.Option{
+			cmp.ReportFormatter(func(u UUID) string {
+				return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+			}),
+		},
+		wantEqual: false,
+		reason:    "values should be printed using the custom formatter",
+	}, {
+		label: label + "/TimeValues",
+		x: struct {
+			Start, End time.Time
+		}{time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC), time.Date(2009, 11, 10, 23, 0, 0, 1, time.UTC)},
+		y: struct {
+			Start, End time.Time
+		}{time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC), time.Date(2009, 11, 10, 23, 0, 0, 2, time.UTC)},
+		wantEqual: false,
+		reason:    "time values should be formatted using RFC 3339 with nanoseconds",
+	}, {
+		label:     label + "/ReportTimeLayout",
+		x:         []time.Time{time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC)},
+		y:         []time.Time{time.Date(2009, 11, 11, 23, 0, 0, 0, time.UTC)},
+		opts:      []cmp.Option{cmp.ReportTimeLayout(time.Kitchen + " on Jan 2")},
+		wantEqual: false,
+		reason:    "time values should be formatted using the provided layout",
+	}, {
+		label:     label + "/FloatsDefault",
+		x:         []float64{0.30000000000000004, 1.0000000000000002},
+		y:         []float64{0.3, 1},
+		wantEqual: false,
+		reason:    "floats should be printed using fmt.Sprint by default",
+	}, {
+		label:     label + "/ReportFloatFormat",
+		x:         []float64{0.30000000000000004, 1.0000000000000002},
+		y:         []float64{0.3, 1},
+		opts:      []cmp.Option{cmp.ReportFloatFormat('g', 17)},
+		wantEqual: false,
+		reason:    "floats should be printed using the provided format and precision",
+	}, {
+		label: label + "/ReportIntegerBase",
+		x: struct {
+			Mode  uint32
+			Flags uint16
+			Hash  []uint64
+			Count int
+		}{0644, 0x13, []uint64{0xdeadbeef, 0xcafe}, -3},
+		y: struct {
+			Mode  uint32
+			Flags uint16
+			Hash  []uint64
+			Count int
+		}{0755, 0x17, []uint64{0xdeadbeef, 0xf00d}, -2},
+		opts: []cmp.Option{
+			cmp.ReportIntegerBase(8, uint32(0)),
+			cmp.ReportIntegerBase(2, uint16(0)),
+			cmp.ReportIntegerBase(16, uint64(0)),
+		},
+		wantEqual: false,
+		reason:    "integers of the selected types should be printed in the provided bases",
+	}, {
+		label: label + "/ReportIntegerBase/FilterPath",
+		x: struct {
+			Flags uint16
+			Count uint16
+			Masks []uint32
+		}{0x13, 12, []uint32{0xff00, 0x00ff}},
+		y: struct {
+			Flags uint16
+			Count uint16
+			Masks []uint32
+		}{0x17, 13, []uint32{0xff00, 0x0f0f}},
+		opts: []cmp.Option{
+			cmp.FilterPath(cmp.MustCompilePathPattern("Flags").Match, cmp.ReportIntegerBase(2)),
+			cmp.FilterPath(cmp.MustCompilePathPattern("Masks").Match, cmp.ReportIntegerBase(16)),
+		},
+		wantEqual: false,
+		reason:    "integers should only be printed in the provided bases at the matched paths and their descendants",
+	}, {
+		label: label + "/ReportBitmasks",
+		x: struct {
+			Flags uint16
+			Perm  uint32
+			Count int
+		}{0x0013, 0x1ff, 3},
+		y: struct {
+			Flags uint16
+			Perm  uint32
+			Count int
+		}{0x0017, 0x1e5, 4},
+		opts: []cmp.Option{
+			cmp.ReportBitmasks(uint16(0), uint32(0)),
+			cmp.ReportIntegerBase(16, uint16(0)),
+		},
+		wantEqual: false,
+		reason:    "differing bitmasks should be annotated with the set and cleared bits",
+	}, {
+		label:     label + "/ReportEnumNames",
+		x:         map[string]State{"a": StateIdle, "b": StateRunning, "c": 7},
+		y:         map[string]State{"a": StateIdle, "b": StateStarting, "c": 8},
+		opts:      []cmp.Option{cmp.ReportEnumNames(map[State]string{StateIdle: "StateIdle", StateStarting: "StateStarting", StateRunning: "StateRunning"})},
+		wantEqual: false,
+		reason:    "enum values should be printed with their registered names",
+	}, {
+		label:     label + "/TextWithControlBytes",
+		x:         []byte("\x02{\"id\": 1234, \"name\": \"Gopher\", \"status\": \"active\", \"tags\": [\"a\", \"b\"]}\x03"),
+		y:         []byte("\x02{\"id\": 1234, \"name\": \"Gopher\", \"status\": \"paused\", \"tags\": [\"a\", \"b\"]}\x03"),
+		wantEqual: false,
+		reason:    "text with a few control bytes should still be printed as text",
+	}, {
+		label:     label + "/ReportBytesAsBinary",
+		x:         []byte("\x02{\"id\": 1234, \"name\": \"Gopher\", \"status\": \"active\", \"tags\": [\"a\", \"b\"]}\x03"),
+		y:         []byte("\x02{\"id\": 1234, \"name\": \"Gopher\", \"status\": \"paused\", \"tags\": [\"a\", \"b\"]}\x03"),
+		opts:      []cmp.Option{cmp.ReportBytesAsBinary()},
+		wantEqual: false,
+		reason:    "byte slices should be printed as binary data when requested",
+	}, {
+		label:     label + "/ReportBytesAsText",
+		x:         struct{ Short []byte }{[]byte("hello\x00")},
+		y:         struct{ Short []byte }{[]byte("hallo\x00")},
+		opts:      []cmp.Option{cmp.ReportBytesAsText([]byte(nil))},
+		wantEqual: false,
+		reason:    "short byte slices should be printed as text when requested",
+	}, {
+		label:     label + "/ReportBytesAs/FilterPath",
+		x:         struct{ Text, Data []byte }{[]byte("hello\x00"), []byte("hello\x00")},
+		y:         struct{ Text, Data []byte }{[]byte("hallo\x00"), []byte("hallo\x00")},
+		opts:      []cmp.Option{cmp.FilterPath(cmp.MustCompilePathPattern("Text").Match, cmp.ReportBytesAsText())},
+		wantEqual: false,
+		reason:    "byte slices should only be printed as text at the matched paths",
+	}, {
+		label: label + "/WhitespaceDefault",
+		x: struct{ Cmd, Text string }{
+			Cmd:  `echo "a	b"`,
+			Text: "the first line\nthe second line \nthe third line\r\nthe fourth line\nthe fifth line\n",
+		},
+		y: struct{ Cmd, Text string }{
+			Cmd:  `echo "a b"`,
+			Text: "the first line\nthe 2nd line\nthe 3rd line\nthe fourth line\nthe fifth line\n",
+		},
+		wantEqual: false,
+		reason:    "tabs and trailing whitespace may be printed verbatim by default",
+	}, {
+		label: label + "/ReportWhitespace",
+		x: struct{ Cmd, Text string }{
+			Cmd:  `echo "a	b"`,
+			Text: "the first line\nthe second line \nthe third line\r\nthe fourth line\nthe fifth line\n",
+		},
+		y: struct{ Cmd, Text string }{
+			Cmd:  `echo "a b"`,
+			Text: "the first line\nthe 2nd line\nthe 3rd line\nthe fourth line\nthe fifth line\n",
+		},
+		opts:      []cmp.Option{cmp.ReportWhitespace()},
+		wantEqual: false,
+		reason:    "differences only in whitespace should be visible",
+	}, {
+		label: label + "/ConfusableRunes",
+		x: map[string]string{
+			"Cyrillic": "password",
+			"Joiner":   "foobar",
+			"Space":    "hello world",
+		},
+		y: map[string]string{
+			"Cyrillic": "pаssword",
+			"Joiner":   "foo\u200dbar",
+			"Space":    "hello\u00a0world",
+		},
+		wantEqual: false,
+		reason:    "strings differing only by confusable runes should print their code points",
+	}, {
+		label: label + "/ReportRedactFields",
+		x: map[string]Credentials{
+			"alice": {User: "alice", Password: "hunter2", Token: []byte("secret-token")},
+			"bob":   {User: "bob", Password: "123456"},
+		},
+		y: map[string]Credentials{
+			"alice": {User: "Alice", Password: "hunter3", Token: []byte("secret-token")},
+		},
+		opts:      []cmp.Option{cmp.ReportRedactFields(Credentials{}, "Password", "Token")},
+		wantEqual: false,
+		reason:    "redacted fields should never be printed, even if they differ",
+	}, {
+		label: label + "/ReportRedactPaths",
+		x: []struct{ Headers map[string]string }{
+			{Headers: map[string]string{"Accept": "*/*", "Authorization": "Bearer hunter2"}},
+			{},
+		},
+		y: []struct{ Headers map[string]string }{
+			{Headers: map[string]string{"Accept": "text/plain", "Authorization": "Bearer hunter3"}},
+			{Headers: map[string]string{"Authorization": "Bearer hunter4"}},
+		},
+		opts:      []cmp.Option{cmp.ReportRedactPaths(`**.Headers["Authorization"]`)},
+		wantEqual: false,
+		reason:    "values at redacted paths should never be printed, even within a value only in y",
+	}, {
+		label:     label + "/ReportQuotedStrings",
+		x:         map[string]string{`"key"`: `{"name": "foo"}`, "plain": "plain"},
+		y:         map[string]string{`"key"`: `{"name": "bar"}`, "plain": "plainer"},
+		opts:      []cmp.Option{cmp.ReportQuotedStrings()},
+		wantEqual: false,
+		reason:    "strings should always be printed as double-quoted literals",
+	}, {
+		label:     label + "/ReportRawStrings",
+		x:         []string{`{"name": "foo"}`, "plain"},
+		y:         []string{`{"name": "bar"}`, "plainer"},
+		opts:      []cmp.Option{cmp.ReportRawStrings()},
+		wantEqual: false,
+		reason:    "strings should be printed as raw literals whenever possible",
+	}, {
+		label: label + "/MaxReportStringLength",
+		x: []struct{ Payload, ID string }{
+			{strings.Repeat("abcdefghij", 10), "a"},
+			{strings.Repeat("0123456789", 10), "b"},
+		},
+		y: []struct{ Payload, ID string }{
+			{strings.Repeat("abcdefghij", 10), "c"},
+			{strings.Repeat("0123456789", 10), "b"},
+		},
+		opts:      []cmp.Option{cmp.MaxReportStringLength(16)},
+		wantEqual: false,
+		reason:    "equal strings should be truncated to the provided length",
+	}, {
+		label: label + "/MaxReportStringLengthUnlimited",
+		x: []struct{ Payload, ID string }{
+			{strings.Repeat("abcdefghij", 10), "a"},
+			{strings.Repeat("0123456789", 10), "b"},
+		},
+		y: []struct{ Payload, ID string }{
+			{strings.Repeat("abcdefghij", 10), "c"},
+			{strings.Repeat("0123456789", 10), "b"},
+		},
+		opts:      []cmp.Option{cmp.MaxReportStringLength(0)},
+		wantEqual: false,
+		reason:    "equal strings should not be truncated",
+	}, {
+		label: label + "/ReportContext",
+		x: func() (out []struct{ V int }) {
+			for i := 0; i < 40; i++ {
+				out = append(out, struct{ V int }{i})
+			}
+			return out
+		}(),
+		y: func() (out []struct{ V int }) {
+			for i := 0; i < 40; i++ {
+				out = append(out, struct{ V int }{i})
+			}
+			out[10].V, out[30].V = -10, -30
+			return out
+		}(),
+		opts:      []cmp.Option{cmp.ReportContext(5)},
+		wantEqual: false,
+		reason:    "five equal elements should surround each difference",
+	}, {
+		label:     label + "/ReportContextZero",
+		x:         struct{ A, B, C, D, E int }{1, 2, 3, 4, 5},
+		y:         struct{ A, B, C, D, E int }{1, 2, 0, 4, 5},
+		opts:      []cmp.Option{cmp.ReportContext(0)},
+		wantEqual: false,
+		reason:    "no equal fields should surround the difference",
+	}, {
+		label: label + "/ReportFullContext",
+		x: []struct {
+			Name  string
+			Tags  []string
+			Extra map[string]int
+			Zero  *int
+		}{
+			{Name: "a", Tags: []string{"x", "y", "z"}}, {Name: "b"}, {Name: "c"}, {Name: "d"},
+			{Name: "e", Extra: map[string]int{"k1": 1, "k2": 2, "k3": 3, "k4": 4, "k5": 5, "k6": 6}},
+			{Name: strings.Repeat("f", 100)},
+		},
+		y: []struct {
+			Name  string
+			Tags  []string
+			Extra map[string]int
+			Zero  *int
+		}{
+			{Name: "a", Tags: []string{"x", "y", "z"}}, {Name: "b"}, {Name: "c"}, {Name: "D"},
+			{Name: "e", Extra: map[string]int{"k1": 1, "k2": 2, "k3": 3, "k4": 4, "k5": 5, "k6": 6}},
+			{Name: strings.Repeat("f", 100)},
+		},
+		opts:      []cmp.Option{cmp.ReportFullContext()},
+		wantEqual: false,
+		reason:    "all values should be printed in their entirety",
+	}, {
+		label: label + "/ReportCoalesceHunks",
+		x: struct {
+			A, B, C int
+			D       struct{ E, F int }
+			G, H    string
+			I       bool
+		}{1, 2, 3, struct{ E, F int }{4, 5}, "g", "h", true},
+		y: struct {
+			A, B, C int
+			D       struct{ E, F int }
+			G, H    string
+			I       bool
+		}{10, 20, 30, struct{ E, F int }{40, 5}, "gg", "hh", true},
+		opts:      []cmp.Option{cmp.ReportCoalesceHunks()},
+		wantEqual: false,
+		reason:    "adjacent differing fields should be printed as a single hunk",
+	}, {
+		label: label + "/ReportDeterministic",
+		x: func() []*Cyclic {
+			p := &Cyclic{}
+			p.Next = p
+			return []*Cyclic{p, {}}
+		}(),
+		y: func() []*Cyclic {
+			p := &Cyclic{}
+			p.Next = p
+			return []*Cyclic{p}
+		}(),
+		opts:      []cmp.Option{cmp.ReportDeterministic()},
+		wantEqual: false,
+		reason:    "pointers should be printed using labels rather than addresses",
+	}, {
+		label: label + "/ReportSummary",
+		x: struct {
+			A int
+			B struct{ C, D []int }
+			E string
+		}{1, struct{ C, D []int }{[]int{1, 2}, []int{3}}, "e"},
+		y: struct {
+			A int
+			B struct{ C, D []int }
+			E string
+		}{2, struct{ C, D []int }{[]int{1, 3}, []int{4}}, "E"},
+		opts: []cmp.Option{
+			cmp.ReportSummary(),
+			cmp.FilterPath(func(p cmp.Path) bool { return p.Last().String() == ".E" }, cmp.Ignore()),
+			cmp.Transformer("Double", func(in []int) (out []int) {
+				for _, v := range in {
+					out = append(out, 2*v)
+				}
+				return out
+			}),
+		},
+		wantEqual: false,
+		reason:    "a summary of the comparison should be appended",
+	}, {
+		label: label + "/ReportIndentJSON",
+		x: struct {
+			Body []byte
+			Meta string
+		}{
+			[]byte(`{"id":1234,"name":"gopher","roles":["admin","dev"],"active":true}`),
+			`{"version":1}`,
+		},
+		y: struct {
+			Body []byte
+			Meta string
+		}{
+			[]byte(`{"id":1234,"name":"gopher","roles":["admin","ops"],"active":false}`),
+			`{"version":2}`,
+		},
+		opts:      []cmp.Option{cmp.ReportIndentJSON()},
+		wantEqual: false,
+		reason:    "JSON documents should be re-indented before being diffed",
+	}, {
+		label: label + "/ReportCollapsePointers",
+		x: func() []interface{} {
+			p := &struct{ A, B int }{1, 2}
+			pp := &p
+			return []interface{}{&pp, &pp, newInt(1)}
+		}(),
+		y: func() []interface{} {
+			p := &struct{ A, B int }{1, 3}
+			pp := &p
+			return []interface{}{&pp, &pp}
+		}(),
+		opts:      []cmp.Option{cmp.ReportCollapsePointers()},
+		wantEqual: false,
+		reason:    "chains of pointers should be printed with a single prefix",
+	}, {
+		label: label + "/ReportTypeNames",
+		x:     []interface{}{Credentials{User: "alice"}, []*Credentials{{User: "bob"}}},
+		y:     []interface{}{Credentials{User: "carol"}, []*Credentials{{User: "dave"}}},
+		opts: []cmp.Option{cmp.ReportTypes(), cmp.ReportTypeNames(func(t reflect.Type) string {
+			if t == reflect.TypeOf(Credentials{}) {
+				return "Creds"
+			}
+			return ""
+		})},
+		wantEqual: false,
+		reason:    "named types should be printed with the provided names",
+	}, {
+		label:     label + "/AmbiguousTypeNames",
+		x:         struct{ A, B interface{} }{foo1.Bar{S: "fizz"}, &foo1.Bar{S: "buzz"}},
+		y:         struct{ A, B interface{} }{foo2.Bar{S: "fizzy"}, &foo2.Bar{S: "buzz"}},
+		wantEqual: false,
+		reason:    "different types with the same name should be printed with qualified names",
+	}, {
+		label:     label + "/MapPresence",
+		x:         map[string]int{"apple": 1, "banana": 2, "cherry": 3},
+		y:         map[string]int{"apple": 1, "banana": 20, "date": 4},
+		opts:      []cmp.Option{cmp.ReportMapPresence()},
+		wantEqual: false,
+		reason:    "map entries only in x or only in y should be annotated as such",
+	}, {
+		label:     label + "/ReportMoves",
+		x:         []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot"},
+		y:         []string{"bravo", "charlie", "delta", "alpha", "echo", "golf"},
+		opts:      []cmp.Option{cmp.ReportMoves()},
+		wantEqual: false,
+		reason:    "relocated elements should be annotated with the index they moved to or from",
+	}, {
+		label: label + "/ReportRekeyedEntries",
+		x: map[string]struct{ Name string }{
+			"id-1": {"alice"}, "id-2": {"bob"}, "id-3": {"carol"},
+		},
+		y: map[string]struct{ Name string }{
+			"id-1": {"alice"}, "id-7": {"bob"}, "id-8": {"dave"},
+		},
+		opts:      []cmp.Option{cmp.ReportRekeyedEntries()},
+		wantEqual: false,
+		reason:    "values moved to a different key should be annotated with the other key",
+	}, {
+		label: label + "/ReportSetDifference",
+		x:     []struct{ Name, Role string }{{"carol", "dev"}, {"alice", "admin"}, {"bob", "dev"}, {"erin", "ops"}, {"frank", "qa"}},
+		y:     []struct{ Name, Role string }{{"zoe", "sales"}, {"bob", "ops"}, {"alice", "admin"}, {"erin", "ops"}, {"frank", "qa"}},
+		opts: []cmp.Option{
+			cmpopts.SortSlices(func(x, y struct{ Name, Role string }) bool { return x.Name < y.Name }),
+			cmp.ReportSetDifference(),
+		},
+		wantEqual: false,
+		reason:    "unordered slices should be preceded by a summary of the set difference",
+	}, {
+		label: label + "/ReportGroupByType",
+		x: struct {
+			Name             string
+			Created, Updated time.Time
+			Tags             []string
+			Extra            interface{}
+		}{"a", time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC), time.Date(2009, time.November, 11, 23, 0, 0, 0, time.UTC), []string{"x", "y"}, 1},
+		y: struct {
+			Name             string
+			Created, Updated time.Time
+			Tags             []string
+			Extra            interface{}
+		}{"b", time.Date(2009, time.November, 11, 0, 0, 0, 0, time.UTC), time.Date(2009, time.November, 12, 0, 0, 0, 0, time.UTC), []string{"x", "z", "w"}, 2},
+		opts:      []cmp.Option{cmp.ReportGroupByType()},
+		wantEqual: false,
+		reason:    "differences should be grouped by the type of the differing values",
+	}, {
+		label:     label + "/IgnoreAllUnexported",
+		x:         struct{ A, b, c int }{1, 2, 3},
+		y:         struct{ A, b, c int }{4, 5, 6},
+		opts:      []cmp.Option{cmp.IgnoreAllUnexported()},
+		wantEqual: false,
+		reason:    "unexported fields should be reported as ignored rather than causing a panic",
+	}, {
+		label: label + "/AppliedOptions",
+		x: struct {
+			A, B, C, D int
+			Ratio      float64
+			When       time.Time
+			Name       string
+			Tags       []string
+		}{1, 2, 3, 4, 0.3333, time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC), "Gopher", []string{"a", "b"}},
+		y: struct {
+			A, B, C, D int
+			Ratio      float64
+			When       time.Time
+			Name       string
+			Tags       []string
+		}{1, 2, 3, 4, 1.0 / 3, time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC), "gopher", []string{"a", "c"}},
+		opts: []cmp.Option{
+			cmp.ReportAppliedOptions(),
+			cmpopts.EquateApprox(0.001, 0),
+			cmpopts.AcyclicTransformer("ToLower", strings.ToLower),
+		},
+		wantEqual: false,
+		reason:    "equal nodes should be annotated with the option that determined equality",
+	}, {
+		label: label + "/MapSummary",
+		x: map[string]int{
+			"apple": 1, "banana": 2, "cherry": 3, "date": 4, "elderberry": 5,
+			"fig": 6, "grape": 7,
+		},
+		y: map[string]int{
+			"apple": 1, "banana": 20, "cherry": 3, "date": 40,
+			"fig": 6, "honeydew": 8, "kiwi": 9,
+		},
+		opts:      []cmp.Option{cmp.ReportMapSummary()},
+		wantEqual: false,
+		reason:    "map differences should be preceded by a summary of the keys involved",
+	}, {
+		label:     label + "/ReportIndent",
+		x:         map[string][]int{"a": {1, 2}, "b": {3}},
+		y:         map[string][]int{"a": {1, 3}, "b": {3}},
+		opts:      []cmp.Option{cmp.ReportIndent("  ")},
+		wantEqual: false,
+		reason:    "each level should be indented with two spaces",
+	}, {
+		label:     label + "/ReportCompact",
+		x:         struct{ A, B int }{1, 2},
+		y:         struct{ A, B int }{1, 3},
+		opts:      []cmp.Option{cmp.ReportCompact(80)},
+		wantEqual: false,
+		reason:    "a short report should be printed on a single line",
+	}, {
+		label:     label + "/ReportCompactTooLong",
+		x:         struct{ A, B string }{strings.Repeat("a", 40), "b"},
+		y:         struct{ A, B string }{strings.Repeat("a", 40), "c"},
+		opts:      []cmp.Option{cmp.ReportCompact(40)},
+		wantEqual: false,
+		reason:    "a long report should still be printed on multiple lines",
+	}, {
+		label:     label + "/ReportColor",
+		x:         struct{ A, B int }{1, 2},
+		y:         struct{ A, B int }{1, 3},
+		opts:      []cmp.Option{cmp.ReportColor(true)},
+		wantEqual: false,
+		reason:    "removed and inserted lines should be colored",
+	}, {
+		label:     label + "/ReportUnified",
+		x:         []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19},
+		y:         []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20},
+		opts:      []cmp.Option{cmp.ReportUnified(1)},
+		wantEqual: false,
+		reason:    "the report should be printed in the unified diff format",
+	}, {
+		label:     label + "/ReportUnifiedMultipleHunks",
+		x:         "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\nline 7\nline 8\nline 9\nline 10\nline 11\nline 12\n",
+		y:         "line 1\nline two\nline 3\nline 4\nline 5\nline 6\nline 7\nline 8\nline 9\nline 10\nline eleven\nline 12\n",
+		opts:      []cmp.Option{cmp.ReportUnified(1)},
+		wantEqual: false,
+		reason:    "separate changes should be printed in separate hunks",
+	}, {
+		label: label + "/ReportTableOfContents",
+		x: &struct {
+			Name    string
+			Enabled bool
+			Ports   []int
+			Limits  map[string]int
+		}{"server", true, []int{80, 443, 8080}, map[string]int{"cpu": 1, "mem": 2}},
+		y: &struct {
+			Name    string
+			Enabled bool
+			Ports   []int
+			Limits  map[string]int
+		}{"server", false, []int{80, 444, 8081}, map[string]int{"cpu": 1, "mem": 2}},
+		opts:      []cmp.Option{cmp.ReportTableOfContents()},
+		wantEqual: false,
+		reason:    "the fields with differences should be listed before the report",
+	}, {
+		label: label + "/ReportJSON",
+		x: struct {
+			Name   string
+			Tags   []string
+			Limits map[string]float64
+			Owner  *struct{ ID int }
+			Notify chan int
+		}{"server", []string{"a", "b"}, map[string]float64{"cpu": 0.5}, &struct{ ID int }{1}, nil},
+		y: struct {
+			Name   string
+			Tags   []string
+			Limits map[string]float64
+			Owner  *struct{ ID int }
+			Notify chan int
+		}{"server\t2", []string{"a", "c"}, map[string]float64{"cpu": 1.5}, nil, make(chan int)},
+		opts:      []cmp.Option{cmp.ReportJSON()},
+		wantEqual: false,
+		reason:    "differing leaf values should be annotated with their JSON representation",
+	}, {
+		label:     label + "/VerbosityTerse",
+		x:         verbosityValue(1),
+		y:         verbosityValue(2),
+		opts:      []cmp.Option{cmp.Verbosity(-1)},
+		wantEqual: false,
+		reason:    "equal values should be elided more aggressively at a lower verbosity",
+	}, {
+		label:     label + "/VerbosityExhaustive",
+		x:         verbosityValue(1),
+		y:         verbosityValue(2),
+		opts:      []cmp.Option{cmp.Verbosity(16)},
+		wantEqual: false,
+		reason:    "equal values should be printed in full at a high verbosity",
+	}, {
+		label:     label + "/VerbosityDefault",
+		x:         verbosityValue(1),
+		y:         verbosityValue(2),
+		wantEqual: false,
+		reason:    "equal values should be partially elided at the default verbosity",
+	}, {
+		label:     label + "/MaxReportBytes",
+		x:         verbosityValue(1),
+		y:         verbosityValue(2),
+		opts:      []cmp.Option{cmp.MaxReportBytes(200)},
+		wantEqual: false,
+		reason:    "the report should be truncated to the complete lines that fit",
+	}, {
+		label:     label + "/MaxReportDiffs",
+		x:         map[string][]int{"a": {1, 2, 3, 4, 5, 6}, "b": {1, 2, 3}, "c": {1}},
+		y:         map[string][]int{"a": {1, 0, 3, 0, 5, 0}, "b": {0, 2, 0}, "c": {0}},
+		opts:      []cmp.Option{cmp.MaxReportDiffs(4)},
+		wantEqual: false,
+		reason:    "only the first differences should be reported",
+	}, {
+		label: label + "/MaxReportDiffsPerContainer",
+		x: struct {
+			Map  map[string]int
+			Ints []int
+			Name string
+		}{map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, "x"},
+		y: struct {
+			Map  map[string]int
+			Ints []int
+			Name string
+		}{map[string]int{"a": 0, "b": 0, "c": 0, "d": 0}, []int{0, 2, 0, 4, 0, 6, 0, 8, 0}, "y"},
+		opts:      []cmp.Option{cmp.MaxReportDiffsPerContainer(2)},
+		wantEqual: false,
+		reason:    "only the first differing records within each container should be printed",
+	}, {
+		label:     label + "/ReportLineWidth",
+		x:         verbosityValue(1),
+		y:         verbosityValue(2),
+		opts:      []cmp.Option{cmp.Verbosity(1), cmp.ReportLineWidth(40)},
+		wantEqual: false,
+		reason:    "values longer than the line width should be printed across multiple lines",
+	}, {
+		label: label + "/ReportIndentEscapesTabs",
+		x: struct {
+			Name string
+			Text string
+		}{"a\tb", strings.Repeat("\tcontext line\n", 4) + "\tline 3\n" + strings.Repeat("\tcontext line\n", 4)},
+		y: struct {
+			Name string
+			Text string
+		}{"a\tc", strings.Repeat("\tcontext line\n", 4) + "\tline three\n" + strings.Repeat("\tcontext line\n", 4)},
+		opts:      []cmp.Option{cmp.ReportIndent("    ")},
+		wantEqual: false,
+		reason:    "tabs within strings should be escaped when indenting with spaces",
+	}, {
+		label: label + "/ReportLogSafe",
+		x: struct {
+			Name  string
+			Error error
+			Text  string
+		}{"a\tb", fmt.Errorf("progress\r50%%\x1b[0m"), strings.Repeat("\tcontext line\n", 4) + "\tline 3\n" + strings.Repeat("\tcontext line\n", 4)},
+		y: struct {
+			Name  string
+			Error error
+			Text  string
+		}{"a\tc", fmt.Errorf("progress\r99%%\x1b[0m"), strings.Repeat("\tcontext line\n", 4) + "\tline three\n" + strings.Repeat("\tcontext line\n", 4)},
+		opts:      []cmp.Option{cmp.ReportLogSafe(), cmp.ReportColor(true), cmpopts.EquateErrors()},
+		wantEqual: false,
+		reason:    "the report should contain no tabs or control characters other than newlines",
+	}, {
+		label: label + "/ReportPointerLabels",
+		x: func() interface{} {
+			p := newInt(0)
+			return struct{ A, B *int }{p, p}
+		}(),
+		y: func() interface{} {
+			p := newInt(0)
+			return struct{ A, B *int }{p, newInt(0)}
+		}(),
+		opts: []cmp.Option{
+			cmp.Comparer(func(x, y *int) bool { return x == y }),
+			cmp.ReportPointerLabels(),
+		},
+		wantEqual: false,
+		reason:    "the same pointer should be printed with the same label at every occurrence",
+	}, {
+		label: label + "/ReportPointerLabels/MapKeys",
+		x: func() interface{} {
+			k := &struct{ A int }{1}
+			return map[*struct{ A int }]*struct{ A int }{k: k}
+		}(),
+		y: func() interface{} {
+			k := &struct{ A int }{1}
+			return map[*struct{ A int }]*struct{ A int }{k: {2}}
+		}(),
+		opts:      []cmp.Option{cmp.ReportPointerLabels()},
+		wantEqual: false,
+		reason:    "pointer map keys should be printed with the same labels as other pointers",
+	}, {
+		label: label + "/ReportAddresses",
+		x: func() interface{} {
+			ints := make([]int, 2, 4)
+			return &struct {
+				Ints []int
+				Map  map[string]*int
+			}{ints, map[string]*int{"a": newInt(1)}}
+		}(),
+		y: func() interface{} {
+			ints := make([]int, 2, 4)
+			ints[1] = 1
+			return &struct {
+				Ints []int
+				Map  map[string]*int
+			}{ints[:2:2], map[string]*int{"a": newInt(2)}}
+		}(),
+		opts:      []cmp.Option{cmp.ReportAddresses(), cmp.ReportPointerLabels()},
+		wantEqual: false,
+		reason:    "the addresses of all pointers, slices, and maps should be printed",
+	}, {
+		label: label + "/ReportTypes",
+		x: map[string]interface{}{
+			"name":  "server",
+			"port":  float64(80),
+			"tags":  []interface{}{"a", "b"},
+			"limit": nil,
+		},
+		y: map[string]interface{}{
+			"name":  "server",
+			"port":  "80",
+			"tags":  []interface{}{"a", "c"},
+			"limit": nil,
+		},
+		opts:      []cmp.Option{cmp.ReportTypes()},
+		wantEqual: false,
+		reason:    "every value should be printed with its type",
+	}, {
+		label: label + "/ReportQualifiedNames",
+		x: struct {
+			Foo foo1.Bar
+			Ts  *ts.Dish
+		}{foo1.Bar{}, new(ts.Dish)},
+		y: struct {
+			Foo foo1.Bar
+			Ts  *ts.Dish
+		}{foo1.Bar{}, nil},
+		opts:      []cmp.Option{cmp.ReportQualifiedNames(), cmp.ReportTypes()},
+		wantEqual: false,
+		reason:    "type names should be qualified by the full package path",
 	}}
 }
 
+// UUID is an opaque identifier used to test custom formatters.
+type UUID [16]byte
+
+// Credentials is a struct with secrets used to test redaction.
+type Credentials struct {
+	User     string
+	Password string
+	Token    []byte
+}
+
+// Cyclic is a type that may point to itself.
+type Cyclic struct{ Next *Cyclic }
+
+// State is an enum without a String method used to test enum names.
+type State int
+
+const (
+	StateIdle State = iota
+	StateStarting
+	StateRunning
+)
+
+// verbosityValue returns a value with much equal content and
+// a single difference depending on n.
+func verbosityValue(n int) interface{} {
+	type Inner struct{ A, B, C int }
+	return struct {
+		Name   string
+		Ints   []int
+		Inners map[string]Inner
+		Value  int
+	}{
+		Name:   strings.Repeat("a", 100),
+		Ints:   []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20},
+		Inners: map[string]Inner{"a": {1, 2, 3}, "b": {4, 5, 6}, "c": {7, 8, 9}, "d": {10, 11, 12}, "e": {13, 14, 15}},
+		Value:  n,
+	}
+}
+
 func embeddedTests() []test {
 	const label = "EmbeddedStruct/"
 
@@ -0,0 +1,77 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	defaultOptionsMu sync.Mutex   // Serializes calls to RegisterDefaultOptions
+	defaultOptions   atomic.Value // Options; only ever appended to
+)
+
+// RegisterDefaultOptions registers options that are implicitly used by all
+// functions in this package that accept options (e.g., Equal and Diff) for the
+// remainder of the program. It is intended for establishing conventions
+// across an entire test binary (e.g., treating empty and nil slices as equal)
+// without needing to pass the same options to every call.
+// The default options are applied before any explicitly provided options.
+// To opt out of the default options for a specific call, use WithoutDefaults.
+//
+// RegisterDefaultOptions is not restricted to test binaries, but it must
+// only be called from test code (typically TestMain or an init function in
+// a _test.go file), since implicitly changing the behavior of Equal in
+// production code is surprising to other users of this package.
+//
+// For example:
+//	func TestMain(m *testing.M) {
+//		cmp.RegisterDefaultOptions(cmpopts.EquateEmpty())
+//		os.Exit(m.Run())
+//	}
+func RegisterDefaultOptions(opts ...Option) {
+	newState(opts) // Check that the options are valid
+
+	defaultOptionsMu.Lock()
+	defer defaultOptionsMu.Unlock()
+	prev, _ := defaultOptions.Load().(Options)
+	next := append(Options{}, prev...)
+	defaultOptions.Store(append(next, Options(opts)))
+}
+
+// WithoutDefaults returns an Option that disables all options registered
+// with RegisterDefaultOptions for the call that it is passed to.
+func WithoutDefaults() Option {
+	return withoutDefaults{}
+}
+
+type withoutDefaults struct{}
+
+func (withoutDefaults) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+func (withoutDefaults) String() string { return "WithoutDefaults()" }
+
+// hasWithoutDefaults reports whether opts contains WithoutDefaults.
+func hasWithoutDefaults(opts Options) bool {
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case withoutDefaults:
+			return true
+		case Options:
+			if hasWithoutDefaults(opt) {
+				return true
+			}
+		case *describedOption:
+			if hasWithoutDefaults(Options{opt.opt}) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ThreeWayKind classifies a difference detected by Diff3.
+type ThreeWayKind int
+
+const (
+	_ ThreeWayKind = iota
+
+	// ChangedInMine indicates that only mine differs from base.
+	ChangedInMine
+	// ChangedInTheirs indicates that only theirs differs from base.
+	ChangedInTheirs
+	// ChangedInBoth indicates that both mine and theirs differ from base,
+	// but were changed in the same way.
+	ChangedInBoth
+	// Conflicting indicates that both mine and theirs differ from base,
+	// and were changed in different ways.
+	Conflicting
+)
+
+func (k ThreeWayKind) String() string {
+	switch k {
+	case ChangedInMine:
+		return "ChangedInMine"
+	case ChangedInTheirs:
+		return "ChangedInTheirs"
+	case ChangedInBoth:
+		return "ChangedInBoth"
+	case Conflicting:
+		return "Conflicting"
+	default:
+		return fmt.Sprintf("ThreeWayKind(%d)", int(k))
+	}
+}
+
+// ThreeWayDifference is a single difference detected by Diff3.
+type ThreeWayDifference struct {
+	// Path is the path from the root to the differing node.
+	Path Path
+
+	// Kind classifies how mine and theirs differ from base.
+	Kind ThreeWayKind
+
+	// Base, Mine, and Theirs are the values of the node.
+	// If the node is unchanged on one side, then the value for that side
+	// is reported as the base value. A value is invalid if the node is a
+	// slice element or map entry that does not exist on that side.
+	Base, Mine, Theirs reflect.Value
+}
+
+// Diff3 performs a three-way comparison between a common base value and
+// two values derived from it (mine and theirs), classifying each difference
+// as changed only in mine, changed only in theirs, changed identically in both,
+// or conflicting. The options are applied to each pairwise comparison
+// and have the same semantics as with Equal.
+//
+// Differences from base are matched between mine and theirs according to
+// Path.GoString. When both sides changed the same node, the changed values are
+// compared (with the same options) to determine whether they conflict.
+// Since the changed values are compared as new roots, path-based filters
+// do not apply to that comparison. When one side changed a node and the other
+// side changed a node nested within it (e.g., one side set a slice to nil
+// while the other side modified an element), the changes are reported as a
+// single Conflicting difference at the outer node.
+//
+// The differences are returned in the order that they are encountered when
+// comparing base with mine, followed by those only found in theirs.
+func Diff3(base, mine, theirs interface{}, opts ...Option) []ThreeWayDifference {
+	dm := Compare(base, mine, opts...).Differences
+	dt := Compare(base, theirs, opts...).Differences
+
+	theirsByPath := make(map[string]int)
+	for i, d := range dt {
+		theirsByPath[fmt.Sprintf("%#v", d.Path)] = i
+	}
+
+	var out []ThreeWayDifference
+	matched := make(map[int]bool)
+	for _, d := range dm {
+		td := ThreeWayDifference{Path: d.Path, Kind: ChangedInMine, Base: d.X, Mine: d.Y, Theirs: d.X}
+		if i, ok := theirsByPath[fmt.Sprintf("%#v", d.Path)]; ok {
+			matched[i] = true
+			td.Theirs = dt[i].Y
+			if equalValues(td.Mine, td.Theirs, opts) {
+				td.Kind = ChangedInBoth
+			} else {
+				td.Kind = Conflicting
+			}
+			out = append(out, td)
+			continue
+		}
+		var reported bool
+		for i, t := range dt {
+			switch {
+			case pathHasPrefix(d.Path, t.Path):
+				// Theirs changed an ancestor of this node.
+				if matched[i] {
+					reported = true // by a prior sibling in mine
+				}
+				_, vy := d.Path[len(t.Path)-1].Values()
+				td = ThreeWayDifference{Path: t.Path, Kind: Conflicting, Base: t.X, Mine: vy, Theirs: t.Y}
+				matched[i] = true
+			case pathHasPrefix(t.Path, d.Path):
+				// Theirs changed a descendant of this node.
+				if td.Kind != Conflicting {
+					_, td.Theirs = t.Path[len(d.Path)-1].Values()
+					td.Kind = Conflicting
+				}
+				matched[i] = true
+			}
+		}
+		if !reported {
+			out = append(out, td)
+		}
+	}
+	for i, d := range dt {
+		if !matched[i] {
+			out = append(out, ThreeWayDifference{Path: d.Path, Kind: ChangedInTheirs, Base: d.X, Mine: d.X, Theirs: d.Y})
+		}
+	}
+	return out
+}
+
+// pathHasPrefix reports whether q is a strict prefix of p.
+func pathHasPrefix(p, q Path) bool {
+	return len(p) > len(q) && pathEqual(p[:len(q)], q)
+}
+
+// pathEqual reports whether p and q have the same Path.GoString.
+func pathEqual(p, q Path) bool {
+	return fmt.Sprintf("%#v", p) == fmt.Sprintf("%#v", q)
+}
+
+// equalValues reports whether vx and vy are equal according to Equal.
+// Values that are missing are only equal to other missing values,
+// while values that cannot be interfaced are never equal.
+func equalValues(vx, vy reflect.Value, opts []Option) bool {
+	switch {
+	case !vx.IsValid() || !vy.IsValid():
+		return vx.IsValid() == vy.IsValid()
+	case !vx.CanInterface() || !vy.CanInterface():
+		return false
+	default:
+		return Equal(vx.Interface(), vy.Interface(), opts...)
+	}
+}
@@ -40,18 +40,18 @@ func ExampleDiff_testing() {
 	//   	NetMask:   {0xff, 0xff, 0x00, 0x00},
 	//   	Clients: []cmp_test.Client{
 	//   		... // 2 identical elements
-	//   		{Hostname: "macchiato", IPAddress: s"192.168.0.153", LastSeen: s"2009-11-10 23:39:43 +0000 UTC"},
+	//   		{Hostname: "macchiato", IPAddress: s"192.168.0.153", LastSeen: 2009-11-10T23:39:43Z},
 	//   		{Hostname: "espresso", IPAddress: s"192.168.0.121"},
 	//   		{
 	//   			Hostname:  "latte",
 	// - 			IPAddress: s"192.168.0.221",
 	// + 			IPAddress: s"192.168.0.219",
-	//   			LastSeen:  s"2009-11-10 23:00:23 +0000 UTC",
+	//   			LastSeen:  2009-11-10T23:00:23Z,
 	//   		},
 	// + 		{
 	// + 			Hostname:  "americano",
 	// + 			IPAddress: s"192.168.0.188",
-	// + 			LastSeen:  s"2009-11-10 23:03:05 +0000 UTC",
+	// + 			LastSeen:  time.Time(2009-11-10T23:03:05Z),
 	// + 		},
 	//   	},
 	//   }
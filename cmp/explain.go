@@ -0,0 +1,207 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp/internal/function"
+)
+
+// Explain returns a human-readable explanation of how the options are used.
+// The Ignore, Comparer, and Transformer options are grouped by the type of
+// values that they may apply to, where each option is listed along with
+// the filters that must pass for it to apply and, if it was constructed
+// by a helper (see Describe), the description of that helper.
+// All other options (e.g., Exporter or Reporter options) are listed last.
+//
+// At each node in the value tree, the filters of all options are evaluated.
+// If any applicable option is an Ignore, then the node is ignored.
+// Otherwise, at most one Comparer or Transformer may apply, where the presence
+// of multiple options causes Equal to panic. If no option applies,
+// then the Equal method is used if present, otherwise the values are
+// compared according to their kind (see Equal).
+func (opts Options) Explain() string {
+	var e explainer
+	e.explain(opts, nil, nil, "")
+
+	var lines []string
+	lines = append(lines, "Precedence: Ignore, then a single Comparer or Transformer, then the Equal method, then comparison by kind.")
+	for _, t := range e.types {
+		lines = append(lines, "", fmt.Sprintf("%s:", t))
+		for _, kind := range []string{"Ignore", "Comparer", "Transformer"} {
+			for _, x := range e.entries {
+				if x.target == t && x.kind == kind {
+					lines = append(lines, "\t"+x.String())
+				}
+			}
+		}
+	}
+	if len(e.others) > 0 {
+		lines = append(lines, "", "Other options:")
+		for _, s := range e.others {
+			lines = append(lines, "\t"+s)
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Conflicts returns a description of every pair of Comparer or Transformer
+// options that may both apply to the same node, which causes Equal to panic.
+// Two options may conflict if the input type of one is assignable to the other
+// and neither takes precedence over the other through Override.
+// Each description lists the type, both options, and the filters involved.
+// A conflict that is reported as possible may never occur in practice if the
+// filters involved are mutually exclusive, which cannot be determined
+// without comparing values.
+func (opts Options) Conflicts() []string {
+	var e explainer
+	e.explain(opts, nil, nil, "")
+
+	var ss []string
+	for i, x := range e.entries {
+		for _, y := range e.entries[i+1:] {
+			if x.kind == "Ignore" || y.kind == "Ignore" || overridden(x, y) {
+				continue
+			}
+			t, ok := overlappingType(x.typ, y.typ)
+			if !ok {
+				continue
+			}
+			certainty := "possibly"
+			if len(x.filters) == 0 && len(y.filters) == 0 {
+				certainty = "always"
+			}
+			ss = append(ss, fmt.Sprintf("%s ambiguous for %s:\n\t%v\n\t%v", certainty, typeString(t), x, y))
+		}
+	}
+	return ss
+}
+
+// overridden reports whether x and y are on opposite sides of an Override.
+func overridden(x, y explainEntry) bool {
+	for _, sx := range x.scopes {
+		for _, sy := range y.scopes {
+			if sx.opt == sy.opt && sx.inner != sy.inner {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// overlappingType reports whether values of some type may be applicable to
+// both tx and ty, returning the more specific of the two types.
+func overlappingType(tx, ty reflect.Type) (reflect.Type, bool) {
+	switch {
+	case tx == nil:
+		return ty, true
+	case ty == nil:
+		return tx, true
+	case tx.AssignableTo(ty):
+		return tx, true
+	case ty.AssignableTo(tx):
+		return ty, true
+	}
+	return nil, false
+}
+
+type explainer struct {
+	types   []string // Target types in the order first encountered
+	entries []explainEntry
+	others  []string
+	scopes  []overrideScope // Enclosing Override options
+}
+
+type overrideScope struct {
+	opt   *override
+	inner bool // Whether within the inner option of opt
+}
+
+type explainEntry struct {
+	kind    string          // Ignore, Comparer, or Transformer
+	typ     reflect.Type    // Type that the option may apply to; nil if any type
+	target  string          // String representation of typ
+	opt     string          // Description of the fundamental option
+	filters []string        // Filters that must pass, from outermost to innermost
+	source  string          // Description of the outermost described option, if any
+	scopes  []overrideScope // Enclosing Override options
+}
+
+func (x explainEntry) String() string {
+	s := x.opt
+	if len(x.filters) > 0 {
+		s += " if " + strings.Join(x.filters, " and ")
+	}
+	if x.source != "" {
+		s += " (from " + x.source + ")"
+	}
+	return s
+}
+
+func (e *explainer) explain(opt Option, typ reflect.Type, filters []string, source string) {
+	switch opt := opt.(type) {
+	case nil:
+	case Options:
+		for _, o := range opt {
+			e.explain(o, typ, filters, source)
+		}
+	case *describedOption:
+		if source == "" {
+			source = opt.desc
+		}
+		e.explain(opt.opt, typ, filters, source)
+	case *pathFilter:
+		filter := fmt.Sprintf("FilterPath(%s)", function.NameOf(reflect.ValueOf(opt.fnc)))
+		e.explain(opt.opt, typ, append(filters[:len(filters):len(filters)], filter), source)
+	case *valuesFilter:
+		filter := fmt.Sprintf("FilterValues(%s)", function.NameOf(opt.fnc))
+		if opt.typ != nil {
+			typ = opt.typ // The innermost type is the most specific
+		}
+		e.explain(opt.opt, typ, append(filters[:len(filters):len(filters)], filter), source)
+	case *override:
+		e.scopes = append(e.scopes, overrideScope{opt, true})
+		e.explain(opt.inner, typ, filters, source)
+		e.scopes[len(e.scopes)-1].inner = false
+		filter := fmt.Sprintf("not overridden by %v", opt.inner)
+		e.explain(opt.outer, typ, append(filters[:len(filters):len(filters)], filter), source)
+		e.scopes = e.scopes[:len(e.scopes)-1]
+	case ignore:
+		e.add(explainEntry{kind: "Ignore", typ: typ, opt: opt.String(), filters: filters, source: source})
+	case *comparer:
+		e.add(explainEntry{kind: "Comparer", typ: opt.typ, opt: opt.String(), filters: filters, source: source})
+	case *transformer:
+		e.add(explainEntry{kind: "Transformer", typ: opt.typ, opt: opt.String(), filters: filters, source: source})
+	default:
+		s := fmt.Sprint(opt)
+		if source != "" {
+			s += " (from " + source + ")"
+		}
+		e.others = append(e.others, s)
+	}
+}
+
+func (e *explainer) add(x explainEntry) {
+	x.target = typeString(x.typ)
+	x.scopes = append([]overrideScope(nil), e.scopes...)
+	var seen bool
+	for _, t := range e.types {
+		seen = seen || t == x.target
+	}
+	if !seen {
+		e.types = append(e.types, x.target)
+	}
+	e.entries = append(e.entries, x)
+}
+
+func typeString(t reflect.Type) string {
+	if t == nil {
+		return "any type"
+	}
+	return t.String()
+}
@@ -136,6 +136,16 @@ var randInt = rand.New(rand.NewSource(time.Now().Unix())).Intn(2)
 // favors performance over optimality. The exact output is not guaranteed to
 // be stable and may change over time.
 func Difference(nx, ny int, f EqualFunc) (es EditScript) {
+	return difference(nx, ny, f, randInt)
+}
+
+// StableDifference is like Difference, but always produces the same
+// edit-script for the same inputs within any process.
+func StableDifference(nx, ny int, f EqualFunc) (es EditScript) {
+	return difference(nx, ny, f, 0)
+}
+
+func difference(nx, ny int, f EqualFunc, zigzagInit int) (es EditScript) {
 	// This algorithm is based on traversing what is known as an "edit-graph".
 	// See Figure 1 from "An O(ND) Difference Algorithm and Its Variations"
 	// by Eugene W. Myers. Since D can be as large as N itself, this is
@@ -174,7 +184,7 @@ func Difference(nx, ny int, f EqualFunc) (es EditScript) {
 	// the graph starting from the bottom-right versus than the top-left.
 	// The result may differ depending on the starting search location,
 	// but still produces a valid edit script.
-	zigzagInit := randInt // either 0 or 1
+	// The zigzagInit is either 0 or 1.
 	if flags.Deterministic {
 		zigzagInit = 0
 	}
@@ -12,17 +12,35 @@ import (
 // TypeString is nearly identical to reflect.Type.String,
 // but has an additional option to specify that full type names be used.
 func TypeString(t reflect.Type, qualified bool) string {
-	return string(appendTypeName(nil, t, qualified, false))
+	return TypeStringFunc(t, qualified, nil)
 }
 
-func appendTypeName(b []byte, t reflect.Type, qualified, elideFunc bool) []byte {
+// TypeStringFunc is identical to TypeString, but calls rename (if non-nil)
+// for every named type to obtain the name to print in its place.
+// If rename returns the empty string, then the default name is used.
+func TypeStringFunc(t reflect.Type, qualified bool, rename func(reflect.Type) string) string {
+	n := typeNamer{qualified: qualified, rename: rename}
+	return string(n.appendTypeName(nil, t, false))
+}
+
+type typeNamer struct {
+	qualified bool
+	rename    func(reflect.Type) string
+}
+
+func (n typeNamer) appendTypeName(b []byte, t reflect.Type, elideFunc bool) []byte {
 	// BUG: Go reflection provides no way to disambiguate two named types
 	// of the same name and within the same package,
 	// but declared within the namespace of different functions.
 
 	// Named type.
 	if t.Name() != "" {
-		if qualified && t.PkgPath() != "" {
+		if n.rename != nil {
+			if name := n.rename(t); name != "" {
+				return append(b, name...)
+			}
+		}
+		if n.qualified && t.PkgPath() != "" {
 			b = append(b, '"')
 			b = append(b, t.PkgPath()...)
 			b = append(b, '"')
@@ -50,7 +68,7 @@ func appendTypeName(b []byte, t reflect.Type, qualified, elideFunc bool) []byte
 			b = append(b, "<-"...)
 		}
 		b = append(b, ' ')
-		b = appendTypeName(b, t.Elem(), qualified, false)
+		b = n.appendTypeName(b, t.Elem(), false)
 	case reflect.Func:
 		if !elideFunc {
 			b = append(b, "func"...)
@@ -62,9 +80,9 @@ func appendTypeName(b []byte, t reflect.Type, qualified, elideFunc bool) []byte
 			}
 			if i == t.NumIn()-1 && t.IsVariadic() {
 				b = append(b, "..."...)
-				b = appendTypeName(b, t.In(i).Elem(), qualified, false)
+				b = n.appendTypeName(b, t.In(i).Elem(), false)
 			} else {
-				b = appendTypeName(b, t.In(i), qualified, false)
+				b = n.appendTypeName(b, t.In(i), false)
 			}
 		}
 		b = append(b, ')')
@@ -73,14 +91,14 @@ func appendTypeName(b []byte, t reflect.Type, qualified, elideFunc bool) []byte
 			// Do nothing
 		case 1:
 			b = append(b, ' ')
-			b = appendTypeName(b, t.Out(0), qualified, false)
+			b = n.appendTypeName(b, t.Out(0), false)
 		default:
 			b = append(b, " ("...)
 			for i := 0; i < t.NumOut(); i++ {
 				if i > 0 {
 					b = append(b, ", "...)
 				}
-				b = appendTypeName(b, t.Out(i), qualified, false)
+				b = n.appendTypeName(b, t.Out(i), false)
 			}
 			b = append(b, ')')
 		}
@@ -92,7 +110,7 @@ func appendTypeName(b []byte, t reflect.Type, qualified, elideFunc bool) []byte
 			}
 			sf := t.Field(i)
 			if !sf.Anonymous {
-				if qualified && sf.PkgPath != "" {
+				if n.qualified && sf.PkgPath != "" {
 					b = append(b, '"')
 					b = append(b, sf.PkgPath...)
 					b = append(b, '"')
@@ -101,7 +119,7 @@ func appendTypeName(b []byte, t reflect.Type, qualified, elideFunc bool) []byte
 				b = append(b, sf.Name...)
 				b = append(b, ' ')
 			}
-			b = appendTypeName(b, sf.Type, qualified, false)
+			b = n.appendTypeName(b, sf.Type, false)
 			if sf.Tag != "" {
 				b = append(b, ' ')
 				b = strconv.AppendQuote(b, string(sf.Tag))
@@ -119,15 +137,15 @@ func appendTypeName(b []byte, t reflect.Type, qualified, elideFunc bool) []byte
 			b = strconv.AppendUint(b, uint64(t.Len()), 10)
 		}
 		b = append(b, ']')
-		b = appendTypeName(b, t.Elem(), qualified, false)
+		b = n.appendTypeName(b, t.Elem(), false)
 	case reflect.Map:
 		b = append(b, "map["...)
-		b = appendTypeName(b, t.Key(), qualified, false)
+		b = n.appendTypeName(b, t.Key(), false)
 		b = append(b, ']')
-		b = appendTypeName(b, t.Elem(), qualified, false)
+		b = n.appendTypeName(b, t.Elem(), false)
 	case reflect.Ptr:
 		b = append(b, '*')
-		b = appendTypeName(b, t.Elem(), qualified, false)
+		b = n.appendTypeName(b, t.Elem(), false)
 	case reflect.Interface:
 		b = append(b, "interface{ "...)
 		for i := 0; i < t.NumMethod(); i++ {
@@ -135,14 +153,14 @@ func appendTypeName(b []byte, t reflect.Type, qualified, elideFunc bool) []byte
 				b = append(b, "; "...)
 			}
 			m := t.Method(i)
-			if qualified && m.PkgPath != "" {
+			if n.qualified && m.PkgPath != "" {
 				b = append(b, '"')
 				b = append(b, m.PkgPath...)
 				b = append(b, '"')
 				b = append(b, '.')
 			}
 			b = append(b, m.Name...)
-			b = appendTypeName(b, m.Type, qualified, true)
+			b = n.appendTypeName(b, m.Type, true)
 		}
 		if b[len(b)-1] == ' ' {
 			b = b[:len(b)-1]
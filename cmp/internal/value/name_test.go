@@ -142,3 +142,28 @@ func TestTypeString(t *testing.T) {
 		}
 	}
 }
+
+func TestTypeStringFunc(t *testing.T) {
+	rename := func(t reflect.Type) string {
+		if t == reflect.TypeOf(Named{}) {
+			return "N"
+		}
+		return ""
+	}
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{in: Named{}, want: "N"},
+		{in: map[Named][]*Named(nil), want: "map[N][]*N"},
+		{in: struct{ A Named }{}, want: "struct{ A N }"},
+		{in: func(Named, ...Named) error { return nil }, want: "func(N, ...N) error"},
+		{in: reflect.Value{}, want: "reflect.Value"},
+	}
+	for _, tt := range tests {
+		typ := reflect.TypeOf(tt.in)
+		if got := TypeStringFunc(typ, false, rename); got != tt.want {
+			t.Errorf("TypeStringFunc(%v) = %v, want %v", typ, got, tt.want)
+		}
+	}
+}
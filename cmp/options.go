@@ -5,6 +5,7 @@
 package cmp
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -63,25 +64,32 @@ func (core) isCore() {}
 // on all individual options held within.
 type Options []Option
 
-func (opts Options) filter(s *state, t reflect.Type, vx, vy reflect.Value) (out applicableOption) {
+func (opts Options) filter(s *state, t reflect.Type, vx, vy reflect.Value) applicableOption {
+	out, _ := opts.filterSource(s, t, vx, vy)
+	return out
+}
+
+// filterSource is like filter, but also returns the option within opts
+// that the applicable option originated from, if there is exactly one.
+func (opts Options) filterSource(s *state, t reflect.Type, vx, vy reflect.Value) (out applicableOption, src Option) {
 	for _, opt := range opts {
-		switch opt := opt.filter(s, t, vx, vy); opt.(type) {
+		switch ao := opt.filter(s, t, vx, vy); ao.(type) {
 		case ignore:
-			return ignore{} // Only ignore can short-circuit evaluation
+			return ignore{}, opt // Only ignore can short-circuit evaluation
 		case validator:
-			out = validator{} // Takes precedence over comparer or transformer
+			out, src = validator{}, nil // Takes precedence over comparer or transformer
 		case *comparer, *transformer, Options:
 			switch out.(type) {
 			case nil:
-				out = opt
+				out, src = ao, opt
 			case validator:
 				// Keep validator
 			case *comparer, *transformer, Options:
-				out = Options{out, opt} // Conflicting comparers or transformers
+				out, src = Options{out, ao}, nil // Conflicting comparers or transformers
 			}
 		}
 	}
-	return out
+	return out, src
 }
 
 func (opts Options) apply(s *state, _, _ reflect.Value) {
@@ -103,6 +111,213 @@ func (opts Options) String() string {
 	return fmt.Sprintf("Options{%s}", strings.Join(ss, ", "))
 }
 
+// Describe returns an Option that behaves identically to opt,
+// but is described as desc when formatted as a string.
+// It is intended for helper packages that construct options from
+// unexported functions, so that the option is described in terms of
+// how it was constructed (e.g., "cmpopts.EquateApprox(0.01, 0)").
+//
+// The option passed in may be any Option, including an Options group.
+func Describe(desc string, opt Option) Option {
+	if opt == nil {
+		return nil
+	}
+	return &describedOption{desc: desc, opt: opt}
+}
+
+type describedOption struct {
+	desc string
+	opt  Option
+}
+
+func (o *describedOption) filter(s *state, t reflect.Type, vx, vy reflect.Value) applicableOption {
+	return o.opt.filter(s, t, vx, vy)
+}
+
+func (o *describedOption) String() string { return o.desc }
+
+// DescribeOptions returns a human-readable description of the options,
+// where each option is described on a separate line.
+// Groups of Options are expanded into the options they contain,
+// unless the group was given a description using Describe.
+func DescribeOptions(opts ...Option) string {
+	var ss []string
+	var describe func(Options)
+	describe = func(opts Options) {
+		for _, opt := range opts {
+			switch opt := opt.(type) {
+			case nil:
+			case Options:
+				describe(opt)
+			default:
+				ss = append(ss, fmt.Sprint(opt))
+			}
+		}
+	}
+	describe(opts)
+	return strings.Join(ss, "\n")
+}
+
+// ValidateOptions reports an error if the options are invalid for use with
+// Equal, Diff, or any other function that accepts options.
+// It performs the same sanity checks that would otherwise only panic
+// once the options are used, such as using an option without any filter,
+// or using an unknown option type.
+//
+// It also detects Comparer and Transformer options that are ambiguous
+// for every value of some type, which occurs if two such options are not
+// wrapped by any filter and the input type of one is assignable to the
+// input type of the other (such that both apply to any value of that type).
+// Options wrapped by a filter (e.g., FilterPath or FilterValues) are never
+// reported, since ambiguities that depend on filters or on the values being
+// compared cannot be detected in advance.
+func ValidateOptions(opts ...Option) (err error) {
+	defer func() {
+		if ex := recover(); ex != nil {
+			s, ok := ex.(string)
+			if !ok {
+				panic(ex)
+			}
+			err = errors.New(s)
+		}
+	}()
+	s := newState(opts)
+
+	// Expand described groups, which are otherwise kept intact.
+	var leaves Options
+	var expand func(Option)
+	expand = func(opt Option) {
+		switch opt := opt.(type) {
+		case Options:
+			for _, o := range opt {
+				expand(o)
+			}
+		case *describedOption:
+			expand(opt.opt)
+		default:
+			leaves = append(leaves, opt)
+		}
+	}
+	expand(s.opts)
+
+	typeOf := func(opt Option) reflect.Type {
+		switch opt := opt.(type) {
+		case *comparer:
+			return opt.typ
+		case *transformer:
+			return opt.typ
+		}
+		return nil
+	}
+	for i, opt1 := range leaves {
+		t1 := typeOf(opt1)
+		if t1 == nil {
+			continue
+		}
+		for _, opt2 := range leaves[i+1:] {
+			t2 := typeOf(opt2)
+			if t2 == nil || !(t1.AssignableTo(t2) || t2.AssignableTo(t1)) {
+				continue
+			}
+			const help = "consider using filters to ensure at most one Comparer or Transformer may apply"
+			return fmt.Errorf("ambiguous set of applicable options:\n\t%v\n\t%v\n%s", opt1, opt2, help)
+		}
+	}
+	return nil
+}
+
+// ValidateOptionsUsed compares x and y as Equal does and reports an error
+// listing every Ignore, Comparer, or Transformer option in opts that never
+// applied to any node in the value tree. It is intended for detecting stale
+// options (e.g., IgnoreFields for a field that was since removed) that
+// otherwise silently have no effect. Options within an Options group are
+// checked individually unless the group was given a description using Describe.
+//
+// An option that was never evaluated at a node because an earlier option
+// ignored that node is not considered to have applied there.
+// Other options (e.g., Exporter or Reporter options) are never reported,
+// including described groups that contain any such option.
+func ValidateOptionsUsed(x, y interface{}, opts ...Option) error {
+	newState(opts) // Panic for invalid options as Equal does
+
+	var used []*usedOption
+	var track func(Options) Options
+	track = func(opts Options) (out Options) {
+		for _, opt := range opts {
+			switch opt := opt.(type) {
+			case Options:
+				out = append(out, track(opt))
+			case coreOption:
+				u := &usedOption{opt: opt}
+				used = append(used, u)
+				out = append(out, u)
+			case *describedOption:
+				if !isCoreOption(opt.opt) {
+					out = append(out, opt) // e.g., a described Exporter
+					break
+				}
+				u := &usedOption{opt: opt}
+				used = append(used, u)
+				out = append(out, u)
+			default:
+				out = append(out, opt)
+			}
+		}
+		return out
+	}
+	Equal(x, y, track(opts))
+
+	var ss []string
+	for _, u := range used {
+		if !u.used {
+			ss = append(ss, fmt.Sprint(u.opt))
+		}
+	}
+	if len(ss) > 0 {
+		return fmt.Errorf("unused options:\n\t%s", strings.Join(ss, "\n\t"))
+	}
+	return nil
+}
+
+// isCoreOption reports whether opt is a core option or a non-empty group
+// consisting only of core options (which may themselves be described).
+func isCoreOption(opt Option) bool {
+	switch opt := opt.(type) {
+	case coreOption:
+		return true
+	case *describedOption:
+		return isCoreOption(opt.opt)
+	case Options:
+		for _, o := range opt {
+			if !isCoreOption(o) {
+				return false
+			}
+		}
+		return len(opt) > 0
+	default:
+		return false
+	}
+}
+
+// usedOption wraps an option and records whether it ever applied.
+type usedOption struct {
+	core
+	opt  Option
+	used bool
+}
+
+func (u *usedOption) filter(s *state, t reflect.Type, vx, vy reflect.Value) applicableOption {
+	opt := u.opt.filter(s, t, vx, vy)
+	switch opt.(type) {
+	case nil, validator:
+	default:
+		u.used = true
+	}
+	return opt
+}
+
+func (u *usedOption) String() string { return fmt.Sprint(u.opt) }
+
 // FilterPath returns a new Option where opt is only evaluated if filter f
 // returns true for the current Path in the value tree.
 //
@@ -112,11 +327,16 @@ func (opts Options) String() string {
 // missing value is from x or y.
 //
 // The option passed in may be an Ignore, Transformer, Comparer, Options, or
-// a previously filtered Option.
+// a previously filtered Option. It may also be a report option that formats
+// individual values (e.g., ReportIntegerBase), in which case the option
+// only applies to the values at the matched paths and their descendants.
 func FilterPath(f func(Path) bool, opt Option) Option {
 	if f == nil {
 		panic("invalid path filter function")
 	}
+	if o, ok := opt.(valueReportOption); ok {
+		return o.filterPath(f)
+	}
 	if opt := normalizeOption(opt); opt != nil {
 		return &pathFilter{fnc: f, opt: opt}
 	}
@@ -131,6 +351,9 @@ type pathFilter struct {
 
 func (f pathFilter) filter(s *state, t reflect.Type, vx, vy reflect.Value) applicableOption {
 	if f.fnc(s.curPath) {
+		if len(s.tracers) > 0 {
+			s.tracef("filter %v matched", f)
+		}
 		return f.opt.filter(s, t, vx, vy)
 	}
 	return nil
@@ -181,6 +404,9 @@ func (f valuesFilter) filter(s *state, t reflect.Type, vx, vy reflect.Value) app
 		return nil
 	}
 	if (f.typ == nil || t.AssignableTo(f.typ)) && s.callTTBFunc(f.fnc, vx, vy) {
+		if len(s.tracers) > 0 {
+			s.tracef("filter %v matched", f)
+		}
 		return f.opt.filter(s, t, vx, vy)
 	}
 	return nil
@@ -190,6 +416,83 @@ func (f valuesFilter) String() string {
 	return fmt.Sprintf("FilterValues(%s, %v)", function.NameOf(f.fnc), f.opt)
 }
 
+// Override returns a new Option where inner takes precedence over outer.
+// At each node, the inner option is evaluated first. If it applies,
+// then it is used and the outer option is not evaluated at all.
+// Otherwise, the outer option is evaluated as usual.
+//
+// This permits a narrowly scoped option to intentionally supersede a broader
+// one, which would otherwise result in an ambiguous set of options.
+// For example, the following compares all float64 values approximately,
+// except for the Exact field, which is compared exactly:
+//
+//	cmp.Override(
+//		cmp.FilterPath(isExactField, cmp.Comparer(func(x, y float64) bool { return x == y })),
+//		cmpopts.EquateApprox(0.01, 0),
+//	)
+//
+// The precedence only holds between inner and outer. Other options passed
+// alongside the returned Option are evaluated together with it as usual.
+// The options passed in may be an Ignore, Transformer, Comparer, Options, or
+// a previously filtered Option.
+func Override(inner, outer Option) Option {
+	inner, outer = normalizeOption(inner), normalizeOption(outer)
+	switch {
+	case inner == nil:
+		return outer
+	case outer == nil:
+		return inner
+	}
+	return &override{inner: inner, outer: outer}
+}
+
+type override struct {
+	core
+	inner, outer Option
+}
+
+func (o override) isFiltered() bool {
+	type filtered interface {
+		isFiltered() bool
+	}
+	for _, opt := range []Option{o.inner, o.outer} {
+		if fopt, ok := opt.(filtered); ok && !fopt.isFiltered() {
+			return false
+		}
+	}
+	return true
+}
+
+func (o override) filter(s *state, t reflect.Type, vx, vy reflect.Value) applicableOption {
+	if opt := o.inner.filter(s, t, vx, vy); opt != nil {
+		return opt
+	}
+	return o.outer.filter(s, t, vx, vy)
+}
+
+func (o override) String() string {
+	return fmt.Sprintf("Override(%v, %v)", o.inner, o.outer)
+}
+
+// DescendFilter returns an Option that prevents descending into nodes in the
+// value tree for which f reports false given the type of the node and the path
+// to it. Such nodes are treated as leaf values and compared in their entirety
+// using reflect.DeepEqual. This guards against accidentally traversing values
+// that are large or internal to some other package (e.g., caches),
+// where a difference within them is not worth reporting in detail.
+//
+// Since the node is compared as if by a Comparer, DescendFilter is ambiguous
+// with any Comparer or Transformer that also applies to the node.
+// As with a Comparer, nodes within unexported fields still require an Exporter.
+func DescendFilter(f func(reflect.Type, Path) bool) Option {
+	if f == nil {
+		panic("invalid descend filter function")
+	}
+	filter := func(p Path) bool { return !f(p.Last().Type(), p) }
+	opt := FilterPath(filter, Comparer(reflect.DeepEqual))
+	return Describe(fmt.Sprintf("DescendFilter(%s)", function.NameOf(reflect.ValueOf(f))), opt)
+}
+
 // Ignore is an Option that causes all comparisons to be ignored.
 // This value is intended to be combined with FilterPath or FilterValues.
 // It is an error to pass an unfiltered Ignore option to Equal.
@@ -199,7 +502,7 @@ type ignore struct{ core }
 
 func (ignore) isFiltered() bool                                                     { return false }
 func (ignore) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption { return ignore{} }
-func (ignore) apply(s *state, _, _ reflect.Value)                                   { s.report(true, reportByIgnore) }
+func (ignore) apply(s *state, _, _ reflect.Value)                                   { s.reportBy(true, reportByIgnore, ignore{}) }
 func (ignore) String() string                                                       { return "Ignore()" }
 
 // validator is a sentinel Option type to indicate that some options could not
@@ -225,6 +528,10 @@ func (validator) apply(s *state, vx, vy reflect.Value) {
 
 	// Unable to Interface implies unexported field without visibility access.
 	if !vx.CanInterface() || !vy.CanInterface() {
+		if s.skipPrivate {
+			s.reportBy(true, reportByIgnore, ignoreAllUnexported{})
+			return
+		}
 		const help = "consider using a custom Comparer; if you control the implementation of type, you can also consider using an Exporter, AllowUnexported, or cmpopts.IgnoreUnexported"
 		var name string
 		if t := s.curPath.Index(-2).Type(); t.Name() != "" {
@@ -313,6 +620,9 @@ func (tr *transformer) filter(s *state, t reflect.Type, _, _ reflect.Value) appl
 }
 
 func (tr *transformer) apply(s *state, vx, vy reflect.Value) {
+	for _, st := range s.stats {
+		st.NumTransformerCalls += 2
+	}
 	step := Transform{&transform{pathStep{typ: tr.fnc.Type().Out(0)}, tr}}
 	vvx := s.callTRFunc(tr.fnc, vx, step)
 	vvy := s.callTRFunc(tr.fnc, vy, step)
@@ -352,6 +662,10 @@ type comparer struct {
 	core
 	typ reflect.Type  // T
 	fnc reflect.Value // func(T, T) bool
+
+	// annotate is an optional func(R, R) string that describes values of
+	// type R that this comparer determined to be unequal (see AnnotateComparer).
+	annotate reflect.Value
 }
 
 func (cm *comparer) isFiltered() bool { return cm.typ != nil }
@@ -364,14 +678,77 @@ func (cm *comparer) filter(_ *state, t reflect.Type, _, _ reflect.Value) applica
 }
 
 func (cm *comparer) apply(s *state, vx, vy reflect.Value) {
+	for _, st := range s.stats {
+		st.NumComparerCalls++
+	}
 	eq := s.callTTBFunc(cm.fnc, vx, vy)
-	s.report(eq, reportByFunc)
+	s.reportBy(eq, reportByFunc, cm)
 }
 
 func (cm comparer) String() string {
 	return fmt.Sprintf("Comparer(%s)", function.NameOf(cm.fnc))
 }
 
+// AnnotateComparer returns a new Option where every Comparer within opt
+// annotates values that it determines to be unequal with a comment in the
+// report produced by Diff. The annotation function f must be of the form
+// "func(T, T) string", which is called with the unequal x and y values
+// (if assignable to T) to produce the comment. It has no effect on Equal.
+//
+// This is useful for explaining why two values are unequal when that is not
+// obvious from the values alone (e.g., the distance between them exceeds a
+// certain tolerance). The option passed in may be a Comparer, Options, or
+// any filtered Option containing Comparers. Other options are unaffected.
+func AnnotateComparer(f interface{}, opt Option) Option {
+	v := reflect.ValueOf(f)
+	if !v.IsValid() || v.Kind() != reflect.Func {
+		panic(fmt.Sprintf("invalid annotation function: %T", f))
+	}
+	if t := v.Type(); t.NumIn() != 2 || t.In(0) != t.In(1) ||
+		t.IsVariadic() || t.NumOut() != 1 || t.Out(0).Kind() != reflect.String || v.IsNil() {
+		panic(fmt.Sprintf("invalid annotation function: %T", f))
+	}
+	return annotateComparers(normalizeOption(opt), v)
+}
+
+// annotateComparers returns a copy of opt where every comparer uses f
+// as the annotation function.
+func annotateComparers(opt Option, f reflect.Value) Option {
+	switch opt := opt.(type) {
+	case Options:
+		var opts Options
+		for _, o := range opt {
+			opts = append(opts, annotateComparers(o, f))
+		}
+		return opts
+	case *describedOption:
+		return &describedOption{desc: opt.desc, opt: annotateComparers(opt.opt, f)}
+	case *pathFilter:
+		return &pathFilter{fnc: opt.fnc, opt: annotateComparers(opt.opt, f)}
+	case *valuesFilter:
+		return &valuesFilter{typ: opt.typ, fnc: opt.fnc, opt: annotateComparers(opt.opt, f)}
+	case *override:
+		return &override{inner: annotateComparers(opt.inner, f), outer: annotateComparers(opt.outer, f)}
+	case *comparer:
+		return &comparer{typ: opt.typ, fnc: opt.fnc, annotate: f}
+	default:
+		return opt
+	}
+}
+
+// annotation returns the annotation for unequal values vx and vy of type t,
+// or an empty string if there is none.
+func (cm *comparer) annotation(t reflect.Type, vx, vy reflect.Value) string {
+	if !cm.annotate.IsValid() || !vx.IsValid() || !vy.IsValid() {
+		return ""
+	}
+	ti := cm.annotate.Type().In(0)
+	if !t.AssignableTo(ti) {
+		return ""
+	}
+	return cm.annotate.Call([]reflect.Value{sanitizeValue(vx, ti), sanitizeValue(vy, ti)})[0].String()
+}
+
 // Exporter returns an Option that specifies whether Equal is allowed to
 // introspect into the unexported fields of certain struct types.
 //
@@ -401,29 +778,37 @@ func Exporter(f func(reflect.Type) bool) Option {
 	if !supportExporters {
 		panic("Exporter is not supported on purego builds")
 	}
-	return exporter(f)
+	return exporter{fmt.Sprintf("Exporter(%s)", function.NameOf(reflect.ValueOf(f))), f}
 }
 
-type exporter func(reflect.Type) bool
+type exporter struct {
+	name string
+	fnc  func(reflect.Type) bool
+}
 
 func (exporter) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
 	panic("not implemented")
 }
 
+func (xf exporter) String() string { return xf.name }
+
 // AllowUnexported returns an Options that allows Equal to forcibly introspect
 // unexported fields of the specified struct types.
 //
 // See Exporter for the proper use of this option.
 func AllowUnexported(types ...interface{}) Option {
 	m := make(map[reflect.Type]bool)
+	var names []string
 	for _, typ := range types {
 		t := reflect.TypeOf(typ)
 		if t.Kind() != reflect.Struct {
 			panic(fmt.Sprintf("invalid struct type: %T", typ))
 		}
 		m[t] = true
+		names = append(names, t.String())
 	}
-	return exporter(func(t reflect.Type) bool { return m[t] })
+	name := fmt.Sprintf("AllowUnexported(%s)", strings.Join(names, ", "))
+	return exporter{name, func(t reflect.Type) bool { return m[t] }}
 }
 
 // Result represents the comparison result for a single node and
@@ -431,6 +816,8 @@ func AllowUnexported(types ...interface{}) Option {
 type Result struct {
 	_     [0]func() // Make Result incomparable
 	flags resultFlags
+	opt   Option // The Comparer or Ignore option that determined the result, if any
+	src   Option // The user-provided option that opt originated from, if known
 }
 
 // Equal reports whether the node was determined to be equal or not.
@@ -455,6 +842,18 @@ func (r Result) ByFunc() bool {
 	return r.flags&reportByFunc != 0
 }
 
+// Option returns the option that determined the result as it was passed
+// to Equal (e.g., the FilterPath option wrapping a Comparer or Ignore),
+// or nil if the result was determined by an Equal method or by the default
+// comparison of the values. The Transformer applied to a value is instead
+// provided by the Transform step (see Transform.Option).
+func (r Result) Option() Option {
+	if r.src != nil {
+		return r.src
+	}
+	return r.opt
+}
+
 // ByCycle reports whether a reference cycle was detected.
 func (r Result) ByCycle() bool {
 	return r.flags&reportByCycle != 0
@@ -473,11 +872,56 @@ const (
 	reportByCycle
 )
 
+// DiffStats are statistics about the immediate children of a node
+// in the value tree (see Reporter), where each child is classified
+// according to the comparison of all of its descendants.
+type DiffStats struct {
+	// NumIgnored is the number of children that were entirely ignored.
+	NumIgnored int
+	// NumIdentical is the number of children that are equal.
+	NumIdentical int
+	// NumRemoved is the number of children only present in x
+	// (e.g., a slice element or map entry missing from y).
+	NumRemoved int
+	// NumInserted is the number of children only present in y.
+	NumInserted int
+	// NumModified is the number of children present in both x and y
+	// that are not equal.
+	NumModified int
+}
+
+// NumDiff is the number of children that are not equal.
+func (s DiffStats) NumDiff() int {
+	return s.NumRemoved + s.NumInserted + s.NumModified
+}
+
 // Reporter is an Option that can be passed to Equal. When Equal traverses
 // the value trees, it calls PushStep as it descends into each node in the
 // tree and PopStep as it ascend out of the node. The leaves of the tree are
 // either compared (determined to be equal or not equal) or ignored and reported
 // as such by calling the Report method.
+//
+// The reporter may optionally implement a SkipStep method:
+//
+//	SkipStep(PathStep) bool
+//
+// If implemented, it is called after every call to PushStep. If it reports
+// true, then the reporter is not called for any descendants of that node
+// (nor Report if it is a leaf node) until the matching PopStep.
+// This allows an expensive reporter to avoid processing subtrees that
+// it has no interest in. It has no effect on the comparison itself,
+// which still descends into the subtree to determine equality.
+//
+// The reporter may also optionally implement a ReportStats method:
+//
+//	ReportStats(DiffStats)
+//
+// If implemented, it is called before the PopStep of every non-leaf node
+// with statistics about the immediate children of that node
+// (e.g., the number of modified fields of a struct).
+// These are the same statistics that Diff uses to decide whether to
+// summarize a run of records (e.g., "... // 5 identical elements").
+// It is also called for a node whose descendants were skipped by SkipStep.
 func Reporter(r interface {
 	// PushStep is called when a tree-traversal operation is performed.
 	// The PathStep itself is only valid until the step is popped.
@@ -516,6 +960,166 @@ func (reporter) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableO
 	panic("not implemented")
 }
 
+func (r reporter) String() string { return fmt.Sprintf("Reporter(%T)", r.reporterIface) }
+
+type stepSkipper interface {
+	SkipStep(PathStep) bool
+}
+type statsReporter interface {
+	ReportStats(DiffStats)
+}
+
+// extendedReporter wraps a reporter that implements the optional
+// SkipStep or ReportStats methods.
+type extendedReporter struct {
+	reporterIface
+	skipper stepSkipper   // May be nil
+	stater  statsReporter // May be nil
+
+	skip  int             // Number of steps pushed since skipping; zero if not skipping
+	stack []reporterFrame // Only populated if stater is non-nil
+}
+type reporterFrame struct {
+	vx, vy                       reflect.Value
+	numSame, numDiff, numIgnored int
+	hasChildren                  bool
+	children                     DiffStats
+}
+
+func (r *extendedReporter) PushStep(ps PathStep) {
+	if r.stater != nil {
+		vx, vy := ps.Values()
+		r.stack = append(r.stack, reporterFrame{vx: vx, vy: vy})
+	}
+	if r.skip > 0 {
+		r.skip++
+		return
+	}
+	r.reporterIface.PushStep(ps)
+	if r.skipper != nil && r.skipper.SkipStep(ps) {
+		r.skip = 1
+	}
+}
+func (r *extendedReporter) Report(rs Result) {
+	if r.stater != nil {
+		f := &r.stack[len(r.stack)-1]
+		switch {
+		case rs.ByIgnore():
+			f.numIgnored++
+		case rs.Equal():
+			f.numSame++
+		default:
+			f.numDiff++
+		}
+	}
+	if r.skip == 0 {
+		r.reporterIface.Report(rs)
+	}
+}
+func (r *extendedReporter) PopStep() {
+	if r.stater != nil {
+		f := r.stack[len(r.stack)-1]
+		r.stack = r.stack[:len(r.stack)-1]
+		if f.hasChildren && r.skip <= 1 {
+			r.stater.ReportStats(f.children)
+		}
+		if len(r.stack) > 0 {
+			p := &r.stack[len(r.stack)-1]
+			p.hasChildren = true
+			p.numSame += f.numSame
+			p.numDiff += f.numDiff
+			p.numIgnored += f.numIgnored
+			switch {
+			case f.numIgnored > 0 && f.numSame+f.numDiff == 0:
+				p.children.NumIgnored++
+			case f.numDiff == 0:
+				p.children.NumIdentical++
+			case !f.vy.IsValid():
+				p.children.NumRemoved++
+			case !f.vx.IsValid():
+				p.children.NumInserted++
+			default:
+				p.children.NumModified++
+			}
+		}
+	}
+	if r.skip > 0 {
+		if r.skip--; r.skip > 0 {
+			return
+		}
+	}
+	r.reporterIface.PopStep()
+}
+
+// FailFast returns an Option that stops the comparison at the first detected
+// difference, leaving the remainder of the value tree unvisited. This reduces
+// the cost of Equal when the values are often unequal and only the boolean
+// result is needed. When used with Diff or a Reporter, only the nodes visited
+// up to and including the first difference are reported.
+//
+// Since the remaining nodes are never visited, options that would otherwise
+// panic upon being applied to those nodes (e.g., an ambiguous set of options)
+// may go undetected.
+func FailFast() Option {
+	return failFast{}
+}
+
+type failFast struct{}
+
+func (failFast) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+func (failFast) String() string { return "FailFast()" }
+
+// IgnoreAllUnexported returns an Option that ignores every unexported field
+// that would otherwise cause Equal to panic, regardless of the struct type,
+// which is useful for quick exploratory comparisons of third-party types.
+// Unexported fields that an Exporter (or AllowUnexported) permits access to
+// are still compared. The ignored fields are counted as ignored in the report
+// produced by Diff (e.g., "... // 2 ignored fields").
+//
+// Since this silently ignores state that may be significant to equality,
+// prefer cmpopts.IgnoreUnexported for the specific types in long-lived tests.
+func IgnoreAllUnexported() Option {
+	return ignoreAllUnexported{}
+}
+
+type ignoreAllUnexported struct{}
+
+func (ignoreAllUnexported) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+func (ignoreAllUnexported) String() string { return "IgnoreAllUnexported()" }
+
+// ClassifyDifferences returns an Option that assigns a Severity to each
+// difference recorded in the DiffResult returned by Compare.
+// The function f is called with the path to each unequal node and must
+// return Informational, Warning, or Blocking.
+// If multiple ClassifyDifferences options are provided, then each difference
+// is assigned the most severe of the classifications. Differences are
+// Blocking if no ClassifyDifferences option is provided.
+//
+// This option only affects the result of Compare and has no effect on
+// whether the values are equal.
+func ClassifyDifferences(f func(Path) Severity) Option {
+	if f == nil {
+		panic("invalid classifier function: <nil>")
+	}
+	return classifier(f)
+}
+
+type classifier func(Path) Severity
+
+func (classifier) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+func (c classifier) String() string {
+	return fmt.Sprintf("ClassifyDifferences(%s)", function.NameOf(reflect.ValueOf(c)))
+}
+
 // normalizeOption normalizes the input options such that all Options groups
 // are flattened and groups with a single element are reduced to that element.
 // Only coreOptions and Options containing coreOptions are allowed.
@@ -539,6 +1143,9 @@ func flattenOptions(dst, src Options) Options {
 			continue
 		case Options:
 			dst = flattenOptions(dst, opt)
+		case *describedOption:
+			flattenOptions(nil, Options{opt.opt}) // Check that opt only contains coreOptions
+			dst = append(dst, opt)
 		case coreOption:
 			dst = append(dst, opt)
 		default:
@@ -5,6 +5,7 @@
 package cmp
 
 import (
+	"fmt"
 	"io"
 	"reflect"
 	"strings"
@@ -17,6 +18,7 @@ import (
 // a run-time panic with a decent error message
 func TestOptionPanic(t *testing.T) {
 	type myBool bool
+	type Secret struct{ Password string }
 	tests := []struct {
 		label     string        // Test description
 		fnc       interface{}   // Option function to call
@@ -116,6 +118,196 @@ func TestOptionPanic(t *testing.T) {
 		label: "Transformer",
 		fnc:   Transformer,
 		args:  []interface{}{"_", func(int) bool { return true }},
+	}, {
+		label: "ReportTransformer",
+		fnc:   ReportTransformer,
+		args:  []interface{}{func(string) int { return 0 }},
+	}, {
+		label:     "ReportTransformer",
+		fnc:       ReportTransformer,
+		args:      []interface{}{func(int, int) int { return 0 }},
+		wantPanic: "invalid transformer function",
+	}, {
+		label:     "ReportTransformer",
+		fnc:       ReportTransformer,
+		args:      []interface{}{(func(int) uint)(nil)},
+		wantPanic: "invalid transformer function",
+	}, {
+		label: "ReportFormatter",
+		fnc:   ReportFormatter,
+		args:  []interface{}{func(int) string { return "" }},
+	}, {
+		label:     "ReportFormatter",
+		fnc:       ReportFormatter,
+		args:      []interface{}{func(int) int { return 0 }},
+		wantPanic: "invalid formatter function",
+	}, {
+		label:     "ReportFormatter",
+		fnc:       ReportFormatter,
+		args:      []interface{}{(func(int) string)(nil)},
+		wantPanic: "invalid formatter function",
+	}, {
+		label: "ReportIndent",
+		fnc:   ReportIndent,
+		args:  []interface{}{"  "},
+	}, {
+		label:     "ReportIndent",
+		fnc:       ReportIndent,
+		args:      []interface{}{"->"},
+		wantPanic: "invalid indent",
+	}, {
+		label: "ReportCompact",
+		fnc:   ReportCompact,
+		args:  []interface{}{80},
+	}, {
+		label:     "ReportCompact",
+		fnc:       ReportCompact,
+		args:      []interface{}{0},
+		wantPanic: "invalid maximum length",
+	}, {
+		label:     "MaxReportBytes",
+		fnc:       MaxReportBytes,
+		args:      []interface{}{-1},
+		wantPanic: "invalid maximum report size",
+	}, {
+		label:     "ReportLineWidth",
+		fnc:       ReportLineWidth,
+		args:      []interface{}{0},
+		wantPanic: "invalid line width",
+	}, {
+		label:     "ReportTimeLayout",
+		fnc:       ReportTimeLayout,
+		args:      []interface{}{""},
+		wantPanic: "invalid time layout",
+	}, {
+		label:     "ReportIntegerBase",
+		fnc:       ReportIntegerBase,
+		args:      []interface{}{3},
+		wantPanic: "invalid integer base",
+	}, {
+		label:     "ReportIntegerBase",
+		fnc:       ReportIntegerBase,
+		args:      []interface{}{16, "foo"},
+		wantPanic: "invalid integer type",
+	}, {
+		label:     "ReportBitmasks",
+		fnc:       ReportBitmasks,
+		args:      []interface{}{1.0},
+		wantPanic: "invalid integer type",
+	}, {
+		label:     "ReportEnumNames",
+		fnc:       ReportEnumNames,
+		args:      []interface{}{map[string]string{}},
+		wantPanic: "invalid enum names",
+	}, {
+		label:     "ReportBytesAsText",
+		fnc:       ReportBytesAsText,
+		args:      []interface{}{[]int{}},
+		wantPanic: "invalid string or byte slice type",
+	}, {
+		label:     "ReportRedactFields",
+		fnc:       ReportRedactFields,
+		args:      []interface{}{"", "Password"},
+		wantPanic: "must be a struct",
+	}, {
+		label:     "ReportRedactFields",
+		fnc:       ReportRedactFields,
+		args:      []interface{}{struct{ A int }{}, "B"},
+		wantPanic: "has no field",
+	}, {
+		label:     "ReportRedactFields",
+		fnc:       ReportRedactFields,
+		args:      []interface{}{struct{ Secret }{}, "Password"},
+		wantPanic: "is promoted from embedded",
+	}, {
+		label:     "ReportRedactPaths",
+		fnc:       ReportRedactPaths,
+		args:      []interface{}{`Headers["Authorization"`},
+		wantPanic: "invalid path pattern",
+	}, {
+		label:     "MaxReportStringLength",
+		fnc:       MaxReportStringLength,
+		args:      []interface{}{-1},
+		wantPanic: "invalid maximum string length",
+	}, {
+		label:     "ReportContext",
+		fnc:       ReportContext,
+		args:      []interface{}{-1},
+		wantPanic: "invalid number of context records",
+	}, {
+		label:     "ReportTypeNames",
+		fnc:       ReportTypeNames,
+		args:      []interface{}{(func(reflect.Type) string)(nil)},
+		wantPanic: "invalid type names function",
+	}, {
+		label:     "CollectDifferences",
+		fnc:       CollectDifferences,
+		args:      []interface{}{(*[]Difference)(nil)},
+		wantPanic: "invalid differences pointer",
+	}, {
+		label:     "CollectStats",
+		fnc:       CollectStats,
+		args:      []interface{}{(*Stats)(nil)},
+		wantPanic: "invalid stats pointer",
+	}, {
+		label:     "OnProgress",
+		fnc:       OnProgress,
+		args:      []interface{}{0, func(Progress) {}},
+		wantPanic: "invalid progress interval",
+	}, {
+		label:     "OnProgress",
+		fnc:       OnProgress,
+		args:      []interface{}{1, (func(Progress))(nil)},
+		wantPanic: "invalid progress function",
+	}, {
+		label:     "CollectEqualities",
+		fnc:       CollectEqualities,
+		args:      []interface{}{(*[]Equality)(nil)},
+		wantPanic: "invalid equalities pointer",
+	}, {
+		label:     "ReportFloatFormat",
+		fnc:       ReportFloatFormat,
+		args:      []interface{}{byte('v'), -1},
+		wantPanic: "invalid float format",
+	}, {
+		label:     "MaxReportDiffs",
+		fnc:       MaxReportDiffs,
+		args:      []interface{}{0},
+		wantPanic: "invalid maximum number of differences",
+	}, {
+		label:     "MaxReportDiffsPerContainer",
+		fnc:       MaxReportDiffsPerContainer,
+		args:      []interface{}{0},
+		wantPanic: "invalid maximum number of differences",
+	}, {
+		label:     "DescendFilter",
+		fnc:       DescendFilter,
+		args:      []interface{}{(func(reflect.Type, Path) bool)(nil)},
+		wantPanic: "invalid descend filter function",
+	}, {
+		label:     "ClassifyDifferences",
+		fnc:       ClassifyDifferences,
+		args:      []interface{}{(func(Path) Severity)(nil)},
+		wantPanic: "invalid classifier function",
+	}, {
+		label: "AnnotateComparer",
+		fnc:   AnnotateComparer,
+		args:  []interface{}{func(x, y int) string { return "" }, Comparer(func(x, y int) bool { return true })},
+	}, {
+		label:     "AnnotateComparer",
+		fnc:       AnnotateComparer,
+		args:      []interface{}{func(x, y int) bool { return true }, Comparer(func(x, y int) bool { return true })},
+		wantPanic: "invalid annotation function",
+	}, {
+		label:     "AnnotateComparer",
+		fnc:       AnnotateComparer,
+		args:      []interface{}{nil, Comparer(func(x, y int) bool { return true })},
+		wantPanic: "invalid annotation function",
+	}, {
+		label:     "ReportUnified",
+		fnc:       ReportUnified,
+		args:      []interface{}{-1},
+		wantPanic: "invalid number of context lines",
 	}, {
 		label:     "FilterPath",
 		fnc:       FilterPath,
@@ -197,8 +389,12 @@ func TestOptionPanic(t *testing.T) {
 					}
 				}()
 				var vargs []reflect.Value
-				for _, arg := range tt.args {
-					vargs = append(vargs, reflect.ValueOf(arg))
+				for i, arg := range tt.args {
+					v := reflect.ValueOf(arg)
+					if arg == nil {
+						v = reflect.Zero(reflect.TypeOf(tt.fnc).In(i)) // e.g., a nil interface{}
+					}
+					vargs = append(vargs, v)
 				}
 				reflect.ValueOf(tt.fnc).Call(vargs)
 			}()
@@ -214,3 +410,452 @@ func TestOptionPanic(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateOptions(t *testing.T) {
+	type myReader struct{ io.Reader }
+	tests := []struct {
+		label   string   // Test description
+		opts    []Option // Options to validate
+		wantErr string   // Expected error message
+	}{{
+		label: "NoOptions",
+	}, {
+		label: "Comparer",
+		opts:  []Option{Comparer(func(x, y int) bool { return true }), nil, Options{}},
+	}, {
+		label: "DistinctTypes",
+		opts: []Option{
+			Comparer(func(x, y int) bool { return true }),
+			Transformer("T", func(int8) int { return 0 }),
+		},
+	}, {
+		label: "AmbiguousComparers",
+		opts: []Option{
+			Comparer(func(x, y int) bool { return true }),
+			Options{Comparer(func(x, y int) bool { return false })},
+		},
+		wantErr: "ambiguous set of applicable options",
+	}, {
+		label: "AmbiguousComparerTransformer",
+		opts: []Option{
+			Comparer(func(x, y io.Reader) bool { return true }),
+			Transformer("T", func(myReader) int { return 0 }),
+		},
+		wantErr: "ambiguous set of applicable options",
+	}, {
+		label: "FilteredComparers",
+		opts: []Option{
+			Comparer(func(x, y int) bool { return true }),
+			FilterPath(func(Path) bool { return false }, Comparer(func(x, y int) bool { return false })),
+		},
+	}, {
+		label: "DisjointFilteredComparers",
+		opts: []Option{
+			FilterValues(func(x, y int) bool { return x < 0 }, Comparer(func(x, y int) bool { return true })),
+			FilterValues(func(x, y int) bool { return x >= 0 }, Comparer(func(x, y int) bool { return false })),
+		},
+	}, {
+		label: "AmbiguousDescribedComparers",
+		opts: []Option{
+			Comparer(func(x, y int) bool { return true }),
+			Describe("int", Comparer(func(x, y int) bool { return false })),
+		},
+		wantErr: "ambiguous set of applicable options",
+	}, {
+		label:   "UnfilteredIgnore",
+		opts:    []Option{Ignore()},
+		wantErr: "cannot use an unfiltered option",
+	}, {
+		label:   "UnfilteredComparer",
+		opts:    []Option{Comparer(func(x, y interface{}) bool { return true })},
+		wantErr: "cannot use an unfiltered option",
+	}, {
+		label: "OverriddenComparer",
+		opts: []Option{
+			Override(
+				FilterPath(func(Path) bool { return false }, Comparer(func(x, y int) bool { return false })),
+				Comparer(func(x, y int) bool { return true }),
+			),
+		},
+	}, {
+		label:   "OverrideUnfilteredIgnore",
+		opts:    []Option{Override(Comparer(func(x, y int) bool { return true }), Ignore())},
+		wantErr: "cannot use an unfiltered option",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			err := ValidateOptions(tt.opts...)
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else {
+				if !strings.Contains(gotErr, tt.wantErr) {
+					t.Fatalf("error message:\ngot:  %s\nwant: %s", gotErr, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribeOptions(t *testing.T) {
+	type myStruct struct{ a int }
+	isZero := func(x, y int) bool { return x == 0 && y == 0 }
+	opts := []Option{
+		Comparer(strings.EqualFold),
+		Options{Ignore(), nil, Options{Transformer("Upper", strings.ToUpper)}},
+		FilterValues(isZero, Describe("EquateZero", Comparer(func(x, y int) bool { return true }))),
+		Describe("Grouped", Options{Ignore(), Ignore()}),
+		AllowUnexported(myStruct{}),
+		Reporter(&defaultReporter{}),
+		ReportBitDiffs(),
+		nil,
+	}
+	got := DescribeOptions(opts...)
+	want := strings.Join([]string{
+		"Comparer(strings.EqualFold)",
+		"Ignore()",
+		"Transformer(Upper, strings.ToUpper)",
+		"FilterValues(cmp.TestDescribeOptions.func1, EquateZero)",
+		"Grouped",
+		"AllowUnexported(cmp.myStruct)",
+		"Reporter(*cmp.defaultReporter)",
+		"ReportBitDiffs()",
+	}, "\n")
+	if got != want {
+		t.Errorf("DescribeOptions mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Described options must behave identically to the original option.
+	opt := Describe("EqualFold", Comparer(strings.EqualFold))
+	if !Equal("hello", "HELLO", opt) || !Equal([]string{"a"}, []string{"A"}, FilterPath(func(Path) bool { return true }, opt)) {
+		t.Errorf("Equal with described Comparer = false, want true")
+	}
+	if Describe("nil", nil) != nil {
+		t.Errorf("Describe(nil) != nil")
+	}
+}
+
+func TestOptionsExplain(t *testing.T) {
+	isZero := func(x, y int) bool { return x == 0 && y == 0 }
+	isRoot := func(p Path) bool { return len(p) == 1 }
+	opts := Options{
+		Transformer("Upper", strings.ToUpper),
+		Comparer(strings.EqualFold),
+		FilterPath(isRoot, Ignore()),
+		FilterValues(isZero, Describe("IgnoreZero", Ignore())),
+		ReportBitDiffs(),
+	}
+	got := opts.Explain()
+	want := strings.Join([]string{
+		"Precedence: Ignore, then a single Comparer or Transformer, then the Equal method, then comparison by kind.",
+		"",
+		"string:",
+		"\tComparer(strings.EqualFold)",
+		"\tTransformer(Upper, strings.ToUpper)",
+		"",
+		"any type:",
+		"\tIgnore() if FilterPath(cmp.TestOptionsExplain.func2)",
+		"",
+		"int:",
+		"\tIgnore() if FilterValues(cmp.TestOptionsExplain.func1) (from IgnoreZero)",
+		"",
+		"Other options:",
+		"\tReportBitDiffs()",
+	}, "\n") + "\n"
+	if got != want {
+		t.Errorf("Explain mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	type S struct{ Approx, Exact float64 }
+	isExact := func(p Path) bool { return p.Last().String() == ".Exact" }
+	approx := Comparer(func(x, y float64) bool { return x-y < 0.5 && y-x < 0.5 })
+	exact := FilterPath(isExact, Comparer(func(x, y float64) bool { return x == y }))
+	opt := Override(exact, approx)
+
+	if !Equal(S{1, 1}, S{1.1, 1}, opt) {
+		t.Errorf("Equal(Approx differs) = false, want true")
+	}
+	if Equal(S{1, 1}, S{1, 1.1}, opt) {
+		t.Errorf("Equal(Exact differs) = true, want false")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Equal without Override did not panic")
+			}
+		}()
+		Equal(S{1, 1}, S{1, 1.1}, exact, approx)
+	}()
+
+	if Override(nil, approx) != approx || Override(exact, nil) != exact {
+		t.Errorf("Override with a nil option did not return the other option")
+	}
+}
+
+func TestValidateOptionsUsed(t *testing.T) {
+	type S struct{ A, B int }
+	isField := func(name string) func(Path) bool {
+		return func(p Path) bool { return p.Last().String() == "."+name }
+	}
+	ignoreA := FilterPath(isField("A"), Ignore())
+	ignoreC := Describe("IgnoreC", FilterPath(isField("C"), Ignore()))
+	cmpStrings := Comparer(strings.EqualFold)
+
+	if err := ValidateOptionsUsed(S{1, 2}, S{3, 2}, ignoreA); err != nil {
+		t.Errorf("ValidateOptionsUsed() error = %v, want nil", err)
+	}
+	err := ValidateOptionsUsed(S{1, 2}, S{3, 2}, Options{ignoreA, ignoreC}, cmpStrings, Exporter(func(reflect.Type) bool { return true }))
+	want := "unused options:\n\tIgnoreC\n\tComparer(strings.EqualFold)"
+	if err == nil || err.Error() != want {
+		t.Errorf("ValidateOptionsUsed() error = %v, want %q", err, want)
+	}
+
+	type U struct{ a int }
+	allowU := Describe("allow U", AllowUnexported(U{}))
+	if err := ValidateOptionsUsed(U{1}, U{1}, allowU); err != nil {
+		t.Errorf("ValidateOptionsUsed() error = %v, want nil", err)
+	}
+	err = ValidateOptionsUsed(S{1, 2}, S{3, 2}, allowU, Describe("ignore", Options{ignoreA, ignoreC}), Describe("stale", Options{ignoreC}))
+	if want := "unused options:\n\tstale"; err == nil || err.Error() != want {
+		t.Errorf("ValidateOptionsUsed() error = %v, want %q", err, want)
+	}
+}
+
+func TestOptionsConflicts(t *testing.T) {
+	isRoot := func(p Path) bool { return len(p) == 1 }
+	anyEqual := Comparer(func(x, y interface{}) bool { return true })
+	opts := Options{
+		Comparer(strings.EqualFold),
+		Transformer("Upper", strings.ToUpper),
+		FilterPath(isRoot, anyEqual),
+		FilterPath(isRoot, Ignore()),
+		Override(FilterPath(isRoot, Comparer(func(x, y int) bool { return true })), Comparer(func(x, y int) bool { return false })),
+	}
+	got := opts.Conflicts()
+	want := []string{
+		"always ambiguous for string:\n\tComparer(strings.EqualFold)\n\tTransformer(Upper, strings.ToUpper)",
+		"possibly ambiguous for string:\n\tComparer(strings.EqualFold)\n\tComparer(cmp.TestOptionsConflicts.func2) if FilterPath(cmp.TestOptionsConflicts.func1)",
+		"possibly ambiguous for string:\n\tTransformer(Upper, strings.ToUpper)\n\tComparer(cmp.TestOptionsConflicts.func2) if FilterPath(cmp.TestOptionsConflicts.func1)",
+		"possibly ambiguous for int:\n\tComparer(cmp.TestOptionsConflicts.func2) if FilterPath(cmp.TestOptionsConflicts.func1)\n\tComparer(cmp.TestOptionsConflicts.func3) if FilterPath(cmp.TestOptionsConflicts.func1)",
+		"possibly ambiguous for int:\n\tComparer(cmp.TestOptionsConflicts.func2) if FilterPath(cmp.TestOptionsConflicts.func1)\n\tComparer(cmp.TestOptionsConflicts.func4) if not overridden by FilterPath(cmp.TestOptionsConflicts.func1, Comparer(cmp.TestOptionsConflicts.func3))",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts mismatch:\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := (Options{Comparer(strings.EqualFold), FilterPath(isRoot, Ignore())}).Conflicts(); len(got) > 0 {
+		t.Errorf("Conflicts() = %q, want none", got)
+	}
+}
+
+func TestDescendFilter(t *testing.T) {
+	type Cache struct{ Entries map[string][]int }
+	type S struct {
+		Name  string
+		Cache *Cache
+	}
+	var visited []string
+	notCache := func(t reflect.Type, p Path) bool {
+		visited = append(visited, p.GoString())
+		return t != reflect.TypeOf(&Cache{})
+	}
+	x := S{"a", &Cache{map[string][]int{"k": {1, 2}}}}
+	y := S{"a", &Cache{map[string][]int{"k": {1, 3}}}}
+
+	opt := DescendFilter(notCache)
+	if Equal(x, y, opt) {
+		t.Errorf("Equal(x, y) = true, want false")
+	}
+	if !Equal(x, S{"a", &Cache{map[string][]int{"k": {1, 2}}}}, opt) {
+		t.Errorf("Equal(x, copy of x) = false, want true")
+	}
+	for _, p := range visited {
+		if strings.Contains(p, "Entries") {
+			t.Errorf("descended into %v", p)
+		}
+	}
+
+	got := Diff(x, y, opt)
+	if !strings.Contains(got, `Cache: &cmp.Cache{Entries: map[string][]int{"k": {1, 2}}}`) {
+		t.Errorf("Diff should report the cache as a whole:\n%s", got)
+	}
+}
+
+type optionReporter struct {
+	path Path
+	got  map[string]string
+}
+
+func (r *optionReporter) PushStep(ps PathStep) { r.path = append(r.path, ps) }
+func (r *optionReporter) PopStep()             { r.path = r.path[:len(r.path)-1] }
+func (r *optionReporter) Report(rs Result) {
+	r.got[r.path.String()] = fmt.Sprint(rs.Option())
+}
+
+func TestResultOption(t *testing.T) {
+	type S struct {
+		A int
+		B string
+		C float64
+	}
+	eqFold := Describe("EqualFold", FilterValues(func(x, y string) bool { return true }, Comparer(strings.EqualFold)))
+	ignoreA := FilterPath(func(p Path) bool { return p.String() == "A" }, Ignore())
+	r := &optionReporter{got: make(map[string]string)}
+	Equal(S{1, "a", 1}, S{2, "A", 1}, eqFold, ignoreA, Reporter(r))
+	want := map[string]string{"A": fmt.Sprint(ignoreA), "B": "EqualFold", "C": "<nil>"}
+	if !reflect.DeepEqual(r.got, want) {
+		t.Errorf("Result.Option mismatch:\ngot:  %v\nwant: %v", r.got, want)
+	}
+}
+
+type skipReporter struct {
+	optionReporter
+	depth, maxDepth int
+}
+
+func (r *skipReporter) PushStep(ps PathStep) {
+	r.optionReporter.PushStep(ps)
+	if r.depth++; r.depth > r.maxDepth {
+		r.maxDepth = r.depth
+	}
+}
+func (r *skipReporter) PopStep() {
+	r.optionReporter.PopStep()
+	r.depth--
+}
+func (r *skipReporter) SkipStep(ps PathStep) bool {
+	return ps.String() == ".B"
+}
+
+func TestReporterSkipStep(t *testing.T) {
+	type S struct {
+		A int
+		B []map[string]int
+	}
+	x := S{1, []map[string]int{{"k": 1}}}
+	y := S{1, []map[string]int{{"k": 2}}}
+	r := &skipReporter{optionReporter: optionReporter{got: make(map[string]string)}}
+	if Equal(x, y, Reporter(r)) {
+		t.Errorf("Equal = true, want false")
+	}
+	if want := map[string]string{"A": "<nil>"}; !reflect.DeepEqual(r.got, want) {
+		t.Errorf("reported nodes mismatch:\ngot:  %v\nwant: %v", r.got, want)
+	}
+	if r.depth != 0 || r.maxDepth != 2 {
+		t.Errorf("depth = %d, maxDepth = %d, want 0 and 2", r.depth, r.maxDepth)
+	}
+}
+
+type statsRecorder struct {
+	skipReporter
+	stats map[string]DiffStats
+}
+
+func (r *statsRecorder) ReportStats(ds DiffStats) {
+	r.stats[r.path.String()] = ds
+}
+
+func TestReporterStats(t *testing.T) {
+	type S struct {
+		A int
+		B []int
+		C map[string]int
+		D string
+	}
+	x := S{1, []int{1, 2, 3}, map[string]int{"a": 1, "b": 2}, "d"}
+	y := S{1, []int{1, 2, 3, 4}, map[string]int{"a": 1, "c": 3}, "D"}
+	r := &statsRecorder{
+		skipReporter: skipReporter{optionReporter: optionReporter{got: make(map[string]string)}},
+		stats:        make(map[string]DiffStats),
+	}
+	Equal(x, y, FilterPath(func(p Path) bool { return p.String() == "D" }, Ignore()), Reporter(r))
+	want := map[string]DiffStats{
+		"":  {NumIgnored: 1, NumIdentical: 1, NumModified: 2},
+		"B": {NumIdentical: 3, NumInserted: 1}, // Reported even though skipped
+		"C": {NumIdentical: 1, NumRemoved: 1, NumInserted: 1},
+	}
+	if !reflect.DeepEqual(r.stats, want) {
+		t.Errorf("ReportStats mismatch:\ngot:  %+v\nwant: %+v", r.stats, want)
+	}
+	if got := want["C"].NumDiff(); got != 2 {
+		t.Errorf("NumDiff = %d, want 2", got)
+	}
+}
+
+func TestAnnotateComparer(t *testing.T) {
+	type S struct {
+		A, B int
+		C    string
+	}
+	within1 := func(x, y int) bool { return x-y <= 1 && y-x <= 1 }
+	describe := func(x, y int) string { return fmt.Sprintf("off by %d", y-x) }
+	opt := AnnotateComparer(describe, Options{
+		FilterPath(func(p Path) bool { return p.Last().String() == ".A" }, Comparer(within1)),
+		Comparer(strings.EqualFold),
+	})
+
+	x, y := S{1, 1, "a"}, S{3, 5, "b"}
+	if !Equal(S{1, 1, "a"}, S{2, 1, "A"}, opt) {
+		t.Errorf("Equal = false, want true")
+	}
+	got := Diff(x, y, opt, FilterPath(func(p Path) bool { return p.Last().String() == ".B" }, Comparer(within1)))
+	if !strings.Contains(got, "// off by 2") {
+		t.Errorf("Diff should annotate field A:\n%s", got)
+	}
+	if strings.Count(got, "//") != 1 {
+		t.Errorf("Diff should only annotate field A:\n%s", got)
+	}
+	if inv := Compare(x, y, opt).Invert().Report; !strings.Contains(inv, "// off by -2") {
+		t.Errorf("Invert().Report should annotate the swapped values:\n%s", inv)
+	}
+}
+
+func TestRegisterDefaultOptions(t *testing.T) {
+	defer defaultOptions.Store(Options(nil))
+
+	equateInts := Comparer(func(x, y int) bool { return true })
+	RegisterDefaultOptions(equateInts)
+	RegisterDefaultOptions(nil)
+	if !Equal(1, 2) {
+		t.Errorf("Equal(1, 2) = false, want true with default options")
+	}
+	if Diff(1, 2) != "" {
+		t.Errorf("Diff(1, 2) is non-empty, want empty with default options")
+	}
+	if Equal(1, 2, WithoutDefaults()) {
+		t.Errorf("Equal(1, 2, WithoutDefaults()) = true, want false")
+	}
+	if Equal(1, 2, Options{nil, Options{WithoutDefaults()}}) {
+		t.Errorf("Equal(1, 2, Options{WithoutDefaults()}) = true, want false")
+	}
+
+	// Explicit options are combined with the default options.
+	gotPanic := func() (s string) {
+		defer func() { s, _ = recover().(string) }()
+		Equal(1, 2, Comparer(func(x, y int) bool { return false }))
+		return ""
+	}()
+	if !strings.Contains(gotPanic, "ambiguous set of applicable options") {
+		t.Errorf("Equal with conflicting options: got panic %q, want ambiguity panic", gotPanic)
+	}
+}
+
+func TestElideTypeArgs(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"pkg.Name", "pkg.Name"},
+		{"pkg.Map[string,pkg.Value[int]]", "pkg.Map[...]"},
+		{`"example.com/pkg".Map[string,example.com/pkg.Value[int]]`, `"example.com/pkg".Map[...]`},
+	}
+	for _, tt := range tests {
+		if got := elideTypeArgs(tt.in); got != tt.want {
+			t.Errorf("elideTypeArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
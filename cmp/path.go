@@ -110,6 +110,92 @@ func (pa Path) String() string {
 // For example:
 //	(*root.MyMap["key"].(*mypkg.MyStruct).MySlices)[2][3].MyField
 func (pa Path) GoString() string {
+	return pa.goString("")
+}
+
+// GoExpr returns the path to a specific node as a Go expression that
+// accesses the node from a variable named root, such that it may be pasted
+// into code for further debugging (e.g., "got.Items[3].Spec.Name").
+//
+// The expression accesses the node within the x value. Slice elements that
+// were moved are accessed using their index in x, or using their index in y
+// if they are missing from x. Map keys are formatted as by %#v,
+// and transforms are formatted as a call to a function named after the
+// transformer, which may not be a valid expression.
+func (pa Path) GoExpr(root string) string {
+	return pa.goString(root)
+}
+
+// JSONPath returns the path to a specific node using JSONPath syntax,
+// which is intended for values that originated from JSON or YAML documents
+// (e.g., maps and slices of interface{}) and for consumers of the path
+// that are unfamiliar with Go.
+//
+// For example:
+//	$.items[3].spec.name
+//
+// Struct fields are named according to their "json" struct tag, if any.
+// Map entries with string keys are formatted as a field (e.g., ".name")
+// or in bracket notation if the key is not an identifier (e.g., "['a.b']").
+// Keys within an interface are formatted according to their dynamic value.
+// Numeric keys are formatted as an index (e.g., "[1]"), while other keys
+// are always quoted (e.g., "['true']").
+// Slice elements that were moved are formatted as in GoExpr.
+// Indirections, type assertions, and transforms are not printed.
+func (pa Path) JSONPath() string {
+	b := []byte("$")
+	for _, s := range pa {
+		switch s := s.(type) {
+		case StructField:
+			name := s.Name()
+			if n := strings.Split(s.Tag().Get("json"), ",")[0]; n != "" && n != "-" {
+				name = n
+			}
+			b = appendJSONPathKey(b, name)
+		case MapIndex:
+			k := s.Key()
+			if k.Kind() == reflect.Interface && !k.IsNil() {
+				k = k.Elem() // e.g., YAML documents with map[interface{}]interface{}
+			}
+			switch k.Kind() {
+			case reflect.String:
+				b = appendJSONPathKey(b, k.String())
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+				reflect.Float32, reflect.Float64:
+				b = append(b, fmt.Sprintf("[%v]", k)...)
+			default:
+				b = appendJSONPathQuoted(b, fmt.Sprint(k))
+			}
+		case SliceIndex:
+			ix, iy := s.SplitKeys()
+			if ix < 0 {
+				ix = iy
+			}
+			b = append(b, fmt.Sprintf("[%d]", ix)...)
+		}
+	}
+	return string(b)
+}
+
+// appendJSONPathKey appends a member name in JSONPath dot notation,
+// or in bracket notation if the name is not an identifier.
+func appendJSONPathKey(b []byte, name string) []byte {
+	if isIdentifier(name) {
+		return append(append(b, '.'), name...)
+	}
+	return appendJSONPathQuoted(b, name)
+}
+
+// appendJSONPathQuoted appends a member name in JSONPath bracket notation.
+func appendJSONPathQuoted(b []byte, name string) []byte {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return append(append(append(b, "['"...), r.Replace(name)...), "']"...)
+}
+
+// goString formats the path using Go syntax, where the root step is
+// formatted as root if non-empty.
+func (pa Path) goString(root string) string {
 	var ssPre, ssPost []string
 	var numIndirect int
 	for i, s := range pa {
@@ -139,6 +225,19 @@ func (pa Path) GoString() string {
 			ssPre = append(ssPre, s.trans.name+"(")
 			ssPost = append(ssPost, ")")
 			continue
+		case SliceIndex:
+			if ix, iy := s.SplitKeys(); root != "" && ix != iy {
+				if ix < 0 {
+					ix = iy
+				}
+				ssPost = append(ssPost, fmt.Sprintf("[%d]", ix))
+				continue
+			}
+		case *pathStep:
+			if root != "" {
+				ssPost = append(ssPost, root)
+				continue
+			}
 		}
 		ssPost = append(ssPost, s.String())
 	}
@@ -148,6 +247,69 @@ func (pa Path) GoString() string {
 	return strings.Join(ssPre, "") + strings.Join(ssPost, "")
 }
 
+// clone returns a deep copy of the path such that it remains valid even after
+// the traversal pops or mutates any of the underlying steps.
+func (pa Path) clone() Path {
+	pa2 := make(Path, len(pa))
+	for i, s := range pa {
+		pa2[i] = cloneStep(s)
+	}
+	return pa2
+}
+
+// cloneStep returns a copy of s that is not aliased with the original.
+func cloneStep(s PathStep) PathStep {
+	switch s := s.(type) {
+	case StructField:
+		sf := *s.structField
+		return StructField{&sf}
+	case SliceIndex:
+		si := *s.sliceIndex
+		return SliceIndex{&si}
+	case MapIndex:
+		mi := *s.mapIndex
+		return MapIndex{&mi}
+	case Indirect:
+		in := *s.indirect
+		return Indirect{&in}
+	case TypeAssertion:
+		ta := *s.typeAssertion
+		return TypeAssertion{&ta}
+	case Transform:
+		tf := *s.transform
+		return Transform{&tf}
+	case *pathStep:
+		ps := *s
+		return &ps
+	default:
+		return s
+	}
+}
+
+// invertStep returns a copy of s as if the x and y values were swapped.
+func invertStep(s PathStep) PathStep {
+	s = cloneStep(s)
+	switch s := s.(type) {
+	case StructField:
+		s.vx, s.vy = s.vy, s.vx
+		s.pvx, s.pvy = s.pvy, s.pvx
+	case SliceIndex:
+		s.vx, s.vy = s.vy, s.vx
+		s.xkey, s.ykey = s.ykey, s.xkey
+	case MapIndex:
+		s.vx, s.vy = s.vy, s.vx
+	case Indirect:
+		s.vx, s.vy = s.vy, s.vx
+	case TypeAssertion:
+		s.vx, s.vy = s.vy, s.vx
+	case Transform:
+		s.vx, s.vy = s.vy, s.vx
+	case *pathStep:
+		s.vx, s.vy = s.vy, s.vx
+	}
+	return s
+}
+
 type pathStep struct {
 	typ    reflect.Type
 	vx, vy reflect.Value
@@ -173,13 +335,14 @@ type structField struct {
 	name string
 	idx  int
 
+	field reflect.StructField // Field information
+
 	// These fields are used for forcibly accessing an unexported field.
-	// pvx, pvy, and field are only valid if unexported is true.
+	// pvx and pvy are only valid if unexported is true.
 	unexported bool
-	mayForce   bool                // Forcibly allow visibility
-	paddr      bool                // Was parent addressable?
-	pvx, pvy   reflect.Value       // Parent values (always addressible)
-	field      reflect.StructField // Field information
+	mayForce   bool          // Forcibly allow visibility
+	paddr      bool          // Was parent addressable?
+	pvx, pvy   reflect.Value // Parent values (always addressible)
 }
 
 func (sf StructField) Type() reflect.Type { return sf.typ }
@@ -205,6 +368,15 @@ func (sf StructField) Name() string { return sf.name }
 // See reflect.Type.Field.
 func (sf StructField) Index() int { return sf.idx }
 
+// Tag is the struct tag of the field, which allows filters and reporters
+// to make decisions based on tags (e.g., `json:"name"` or `cmp:"ignore"`).
+// See reflect.StructField.Tag.
+func (sf StructField) Tag() reflect.StructTag { return sf.field.Tag }
+
+// Field is the description of the field in the parent struct type.
+// See reflect.Type.Field.
+func (sf StructField) Field() reflect.StructField { return sf.field }
+
 // SliceIndex is an index operation on a slice or array at some index Key.
 type SliceIndex struct{ *sliceIndex }
 type sliceIndex struct {
@@ -0,0 +1,282 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PathPattern is a compiled glob-style pattern that matches a Path
+// (see CompilePathPattern). It is safe for concurrent use.
+type PathPattern struct {
+	pattern string
+	elems   []patternElem
+	root    *string // Type of the root step if specified; empty if any
+}
+
+type patternKind int
+
+const (
+	patternField  patternKind = iota // .Name or .*
+	patternIndex                     // [N], ["key"], or [*]
+	patternAny                       // **
+	patternAssert                    // .(T)
+)
+
+type patternElem struct {
+	kind  patternKind
+	name  string // Field name, slice index or map key literal, or type; "*" matches any
+	index int    // Slice index if name is an integer; otherwise -1
+
+	// split reports whether the element is a slice index of the form
+	// "[5->3]", where xkey and ykey are the split keys (or -1 for "?").
+	split      bool
+	xkey, ykey int
+}
+
+// pathRootRx matches the prefix of the output of Path.GoString up to and
+// including the root step, which is preceded by indirections and transforms.
+var pathRootRx = regexp.MustCompile(`^(?:[(*]|` + identRx + `(?:\.` + identRx + `)*\()*(?:\{([^}]*)\}|root)`)
+
+// splitIndexRx matches a split slice index (e.g., "5->3", "5->?", or "?->3").
+var splitIndexRx = regexp.MustCompile(`^(\d+|\?)->(\d+|\?)$`)
+
+// CompilePathPattern compiles a glob-style pattern that matches a Path,
+// which is useful for constructing a filter for FilterPath
+// (e.g., FilterPath(MustCompilePathPattern("Spec.Containers[*].Image").Match, ...)).
+//
+// A pattern is a sequence of the following elements,
+// where the leading period of the first element may be omitted:
+//
+//	.Name    matches a struct field with that name
+//	.*       matches any struct field
+//	[N]      matches a slice or array element at index N, or a map entry with key N
+//	["key"]  matches a map entry with the key formatted as by %#v (e.g., "key")
+//	[*]      matches any slice or array element or map entry
+//	.**      matches any sequence of zero or more steps
+//
+// A pattern matches the entire path, where the root step and any Indirect,
+// TypeAssertion, and Transform steps are disregarded. For example,
+// "A.B[*]" matches the elements of the B field within the A field of the root,
+// but neither A.B itself nor the descendants of its elements,
+// while "**.Metadata" matches every field named Metadata at any depth.
+// A slice element matches an index if it has that index in either x or y.
+//
+// The output of Path.GoString is also a valid pattern that matches
+// the original path, such that a path may be read from a file or flag and
+// matched later. The output of Path.String is a valid pattern as well,
+// but since it omits slice indexes and map keys, it only matches the
+// containing struct field (e.g., "Hosts" rather than "Hosts[*]").
+// Thus, a pattern may also start with the root step, formatted as either
+// "{T}" to only match values of type T, or as "root" to match any type,
+// in which case the following elements are also permitted:
+//
+//	.(T)     matches a type assertion to T
+//	[N->M]   matches a slice element at index N in x and index M in y,
+//	         where either index may be "?" to indicate that it is missing
+//
+// Such a pattern matches a path more exactly: TypeAssertion steps are not
+// disregarded, and "[N]" only matches a slice element at index N
+// in both x and y.
+//
+// The "*", "(", ")", and "Name(" of indirections and transforms
+// surrounding the root step are disregarded, such that
+// "(*{*pkg.T}.Field[2].(*pkg.U))[3]" matches the same paths as
+// "{*pkg.T}.Field[2].(*pkg.U)[3]".
+func CompilePathPattern(pattern string) (*PathPattern, error) {
+	p := &PathPattern{pattern: pattern}
+	s := pattern
+	if m := pathRootRx.FindStringSubmatch(s); m != nil && !startsWithIdent(s[len(m[0]):]) {
+		root := m[1]
+		p.root = &root
+		s = s[len(m[0]):]
+	}
+	for len(s) > 0 {
+		switch {
+		case s[0] == ')' && p.root != nil:
+			s = s[1:] // Closing parenthesis for an indirection or transform
+		case strings.HasPrefix(s, ".(") && p.root != nil:
+			n := indexClosingBracket(s[1:], '(', ')')
+			if n < 0 {
+				return nil, fmt.Errorf("invalid path pattern %q: missing closing parenthesis", pattern)
+			}
+			p.elems = append(p.elems, patternElem{kind: patternAssert, name: s[2 : n+1]})
+			s = s[n+2:]
+		case s[0] == '[':
+			n := indexClosingBracket(s, '[', ']')
+			if n < 0 {
+				return nil, fmt.Errorf("invalid path pattern %q: missing closing bracket", pattern)
+			}
+			lit := s[1:n]
+			if lit == "" {
+				return nil, fmt.Errorf("invalid path pattern %q: empty index", pattern)
+			}
+			e := patternElem{kind: patternIndex, name: lit, index: -1}
+			if i, err := strconv.Atoi(lit); err == nil && i >= 0 {
+				e.index = i
+			}
+			if e.index >= 0 && p.root != nil {
+				e.split, e.xkey, e.ykey = true, e.index, e.index
+			}
+			if m := splitIndexRx.FindStringSubmatch(lit); m != nil {
+				e.split = true
+				e.xkey, _ = strconv.Atoi(m[1])
+				e.ykey, _ = strconv.Atoi(m[2])
+				if m[1] == "?" {
+					e.xkey = -1
+				}
+				if m[2] == "?" {
+					e.ykey = -1
+				}
+			}
+			p.elems = append(p.elems, e)
+			s = s[n+1:]
+		case s[0] == '.' || (len(s) == len(pattern) && p.root == nil):
+			if s[0] == '.' {
+				s = s[1:]
+			}
+			n := 0
+			for n < len(s) && s[n] != '.' && s[n] != '[' && s[n] != ')' {
+				n++
+			}
+			switch name := s[:n]; {
+			case name == "**":
+				p.elems = append(p.elems, patternElem{kind: patternAny})
+			case name == "*" || isIdentifier(name):
+				p.elems = append(p.elems, patternElem{kind: patternField, name: name})
+			default:
+				return nil, fmt.Errorf("invalid path pattern %q: invalid field name %q", pattern, name)
+			}
+			s = s[n:]
+		default:
+			return nil, fmt.Errorf("invalid path pattern %q: unexpected %q", pattern, s[0])
+		}
+	}
+	return p, nil
+}
+
+// MustCompilePathPattern is like CompilePathPattern, but panics if the
+// pattern is invalid. It is intended for patterns that are constants.
+func MustCompilePathPattern(pattern string) *PathPattern {
+	p, err := CompilePathPattern(pattern)
+	if err != nil {
+		panic(err.Error())
+	}
+	return p
+}
+
+// String returns the source text of the pattern.
+func (p *PathPattern) String() string {
+	return p.pattern
+}
+
+// Match reports whether the path matches the pattern.
+func (p *PathPattern) Match(pa Path) bool {
+	if p.root != nil && *p.root != "" {
+		if len(pa) == 0 || pa[0].Type() == nil || pa[0].Type().String() != *p.root {
+			return false
+		}
+	}
+	return p.match(p.elems, pa)
+}
+
+func (p *PathPattern) match(elems []patternElem, pa Path) bool {
+	pa = p.skipUnmatchedSteps(pa)
+	if len(elems) == 0 {
+		return len(pa) == 0
+	}
+	if elems[0].kind == patternAny {
+		for {
+			if p.match(elems[1:], pa) {
+				return true
+			}
+			if len(pa) == 0 {
+				return false
+			}
+			pa = p.skipUnmatchedSteps(pa[1:])
+		}
+	}
+	return len(pa) > 0 && elems[0].matchStep(pa[0]) && p.match(elems[1:], pa[1:])
+}
+
+// skipUnmatchedSteps skips leading steps that the pattern disregards.
+func (p *PathPattern) skipUnmatchedSteps(pa Path) Path {
+	for len(pa) > 0 {
+		switch pa[0].(type) {
+		case StructField, SliceIndex, MapIndex:
+			return pa
+		case TypeAssertion:
+			if p.root != nil {
+				return pa
+			}
+		}
+		pa = pa[1:]
+	}
+	return pa
+}
+
+func (e patternElem) matchStep(ps PathStep) bool {
+	switch ps := ps.(type) {
+	case StructField:
+		return e.kind == patternField && (e.name == "*" || e.name == ps.Name())
+	case SliceIndex:
+		ix, iy := ps.SplitKeys()
+		if e.split {
+			return e.kind == patternIndex && e.xkey == ix && e.ykey == iy
+		}
+		return e.kind == patternIndex && (e.name == "*" || (e.index >= 0 && (e.index == ix || e.index == iy)))
+	case MapIndex:
+		return e.kind == patternIndex && (e.name == "*" || e.name == fmt.Sprintf("%#v", ps.Key()))
+	case TypeAssertion:
+		return e.kind == patternAssert && e.name == ps.Type().String()
+	}
+	return false
+}
+
+// indexClosingBracket returns the index of the close bracket that closes the
+// open bracket at the start of s, skipping over nested brackets and
+// quoted strings. It returns -1 if there is no closing bracket.
+func indexClosingBracket(s string, open, close byte) int {
+	var depth int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case open:
+			depth++
+		case close:
+			if depth--; depth == 0 {
+				return i
+			}
+		case '"', '\'', '`':
+			q := s[i]
+			for i++; i < len(s) && s[i] != q; i++ {
+				if s[i] == '\\' && q != '`' {
+					i++ // Skip the escaped character
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// startsWithIdent reports whether s starts with an identifier character.
+func startsWithIdent(s string) bool {
+	for _, r := range s {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+	}
+	return false
+}
+
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,46 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Progress describes the progress of a comparison (see OnProgress).
+type Progress struct {
+	// NumVisited is the number of nodes visited so far.
+	NumVisited int
+	// Path is the path to the node currently being visited.
+	// It is only valid for the duration of the callback.
+	Path Path
+}
+
+// OnProgress returns an Option that calls f after every n nodes
+// visited in the value trees, which allows long running comparisons of
+// very large values to log their progress or to implement a watchdog
+// (e.g., by panicking if some deadline has passed).
+// The function f is called synchronously from the comparison and
+// must not retain the Path.
+func OnProgress(n int, f func(Progress)) Option {
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid progress interval: %d", n))
+	}
+	if f == nil {
+		panic("invalid progress function: <nil>")
+	}
+	return progressReporter{n, f}
+}
+
+type progressReporter struct {
+	n int
+	f func(Progress)
+}
+
+func (progressReporter) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+func (progressReporter) String() string { return "OnProgress(...)" }
@@ -0,0 +1,127 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Provenance describes how a node was determined to be equal.
+type Provenance int
+
+const (
+	// EqualByStructure indicates that the values were equal according to
+	// the rules for their basic kind (e.g., the == operator for integers),
+	// or were pointers that form an equal cycle.
+	EqualByStructure Provenance = iota
+	// EqualByMethod indicates that the Equal method of the values reported
+	// that they were equal.
+	EqualByMethod
+	// EqualByComparer indicates that a Comparer reported that the values
+	// were equal.
+	EqualByComparer
+	// EqualByTransformer indicates that the values were equal after being
+	// transformed by a Transformer.
+	EqualByTransformer
+	// EqualByIgnore indicates that the values were ignored by an Ignore option.
+	EqualByIgnore
+)
+
+func (p Provenance) String() string {
+	switch p {
+	case EqualByStructure:
+		return "structure"
+	case EqualByMethod:
+		return "Equal method"
+	case EqualByComparer:
+		return "Comparer"
+	case EqualByTransformer:
+		return "Transformer"
+	case EqualByIgnore:
+		return "Ignore"
+	default:
+		return fmt.Sprintf("Provenance(%d)", int(p))
+	}
+}
+
+// Equality describes a node in the value tree that was determined to be equal.
+type Equality struct {
+	// Path is the path from the root to the equal node.
+	// It remains valid after the comparison has completed.
+	Path Path
+
+	// By is how the node was determined to be equal.
+	By Provenance
+
+	// Option is the Comparer, Transformer, or Ignore option that determined
+	// the node to be equal, if any.
+	Option Option
+}
+
+// CollectEqualities returns an Option that appends to the slice pointed to
+// by eqs a description of every node that was determined to be equal,
+// which is useful for asserting that a particular Comparer actually applied
+// (e.g., to a security sensitive field).
+//
+// An Equality is recorded for every equal leaf node (i.e., a node compared
+// by an Equal method, Comparer, Ignore option, or the rules for its
+// basic kind) and for every Transform node whose transformed values are
+// equal, where the Transform node is recorded after its descendants.
+// Equal structs, slices, maps, pointers, and interfaces are implied by
+// the equality of their descendants and are not recorded.
+func CollectEqualities(eqs *[]Equality) Option {
+	if eqs == nil {
+		panic("invalid equalities pointer: <nil>")
+	}
+	return equalityCollector{eqs}
+}
+
+type equalityCollector struct{ eqs *[]Equality }
+
+func (equalityCollector) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+func (equalityCollector) String() string { return "CollectEqualities(...)" }
+
+// equalityRecorder is a reporter that records every equal leaf node
+// and every equal Transform node.
+type equalityRecorder struct {
+	path    Path
+	unequal []bool // Whether each node in path has an unequal descendant
+	eqs     *[]Equality
+}
+
+func (r *equalityRecorder) PushStep(ps PathStep) {
+	r.path = append(r.path, ps)
+	r.unequal = append(r.unequal, false)
+}
+func (r *equalityRecorder) Report(rs Result) {
+	if !rs.Equal() {
+		r.unequal[len(r.unequal)-1] = true
+		return
+	}
+	e := Equality{Path: r.path.clone(), By: EqualByStructure, Option: rs.Option()}
+	switch {
+	case rs.ByIgnore():
+		e.By = EqualByIgnore
+	case rs.ByMethod():
+		e.By = EqualByMethod
+	case rs.ByFunc():
+		e.By = EqualByComparer
+	}
+	*r.eqs = append(*r.eqs, e)
+}
+func (r *equalityRecorder) PopStep() {
+	n := len(r.path) - 1
+	if tf, ok := r.path[n].(Transform); ok && !r.unequal[n] {
+		*r.eqs = append(*r.eqs, Equality{Path: r.path.clone(), By: EqualByTransformer, Option: tf.Option()})
+	}
+	if n > 0 && r.unequal[n] {
+		r.unequal[n-1] = true
+	}
+	r.path, r.unequal = r.path[:n], r.unequal[:n]
+}
@@ -4,6 +4,13 @@
 
 package cmp
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
 // defaultReporter implements the reporter interface.
 //
 // As Equal serially calls the PushStep, Report, and PopStep methods, the
@@ -18,13 +25,21 @@ package cmp
 type defaultReporter struct {
 	root *valueNode
 	curr *valueNode
+	opts formatOptions // Initial options as configured by report options
+
+	summary *Summary // Summary of the tree before it was pruned, if any
 }
 
 func (r *defaultReporter) PushStep(ps PathStep) {
+	var redacted bool
+	if sf, ok := ps.(StructField); ok && r.curr != nil {
+		redacted = r.opts.isRedacted(r.curr.Type, sf.Name())
+	}
 	r.curr = r.curr.PushStep(ps)
 	if r.root == nil {
 		r.root = r.curr
 	}
+	r.curr.Redacted = redacted || len(r.opts.RedactedPaths) > 0 && r.opts.matchesRedactedPath(r.curr.Path())
 }
 func (r *defaultReporter) Report(rs Result) {
 	r.curr.Report(rs)
@@ -38,10 +53,74 @@ func (r *defaultReporter) PopStep() {
 // has been traversed.
 func (r *defaultReporter) String() string {
 	assert(r.root != nil && r.curr == nil)
+	if r.opts.MaxDiffs > 0 {
+		return r.StringN(r.opts.MaxDiffs)
+	}
+	return r.format()
+}
+
+func (r *defaultReporter) format() string {
 	if r.root.NumDiff == 0 {
 		return ""
 	}
-	return formatOptions{}.FormatDiff(r.root).String()
+	opts := r.opts
+	if opts.PointerLabels {
+		opts.labels = make(map[value.Pointer]int)
+	}
+	var s string
+	if opts.GroupByType {
+		s = opts.formatGroupedByType(r.root)
+	} else {
+		s = opts.formatText(opts.FormatDiff(r.root))
+	}
+	if r.opts.TableOfContents {
+		s = formatTableOfContents(r.root) + s
+	}
+	if r.opts.Summary {
+		if r.summary == nil {
+			sum := summarize(r.root)
+			r.summary = &sum
+		}
+		s += "// Summary: " + r.summary.String() + "\n"
+	}
+	if r.opts.MaxBytes > 0 && len(s) > r.opts.MaxBytes {
+		s = truncateReport(s, r.opts.MaxBytes)
+	}
+	return s
+}
+
+// StringN is like String, but only reports the first n differences and
+// appends a summary of how many differences were omitted.
+// StringN mutates the tree and may only be called once.
+func (r *defaultReporter) StringN(n int) string {
+	assert(r.root != nil && r.curr == nil)
+	if r.opts.MaxDiffs > 0 && r.opts.MaxDiffs < n {
+		n = r.opts.MaxDiffs
+	}
+	numDiff := r.root.NumDiff
+	if numDiff <= n {
+		return r.format()
+	}
+	var s string
+	if n > 0 {
+		sum := summarize(r.root)
+		r.summary = &sum
+		r.root.LimitDiffs(n)
+		s = r.format()
+	}
+	return s + fmt.Sprintf("... %d more %s omitted\n", numDiff-n, pluralize("difference", numDiff-n))
+}
+
+// truncateReport truncates the report to the complete lines that fit within
+// n bytes, followed by a summary of how much was omitted.
+func truncateReport(s string, n int) string {
+	i := strings.LastIndexByte(s[:n+1], '\n') + 1
+	numLines := strings.Count(s[i:], "\n")
+	if !strings.HasSuffix(s, "\n") {
+		numLines++
+	}
+	return s[:i] + fmt.Sprintf("... %d more %s (%d %s) omitted\n",
+		len(s)-i, pluralize("byte", len(s)-i), numLines, pluralize("line", numLines))
 }
 
 func assert(ok bool) {
@@ -5,8 +5,11 @@
 package cmp
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/google/go-cmp/cmp/internal/value"
 )
@@ -35,6 +38,29 @@ const (
 	autoType
 )
 
+type bytesMode int
+
+const (
+	// autoBytes prints strings and byte slices as text or binary data
+	// depending on their content.
+	autoBytes bytesMode = iota
+	// textBytes always prints them as text.
+	textBytes
+	// binaryBytes always prints them as binary data.
+	binaryBytes
+)
+
+type quoteMode int
+
+const (
+	// autoQuote prints strings as raw string literals if that is shorter.
+	autoQuote quoteMode = iota
+	// doubleQuote always prints strings as double-quoted string literals.
+	doubleQuote
+	// rawQuote prints strings as raw string literals whenever possible.
+	rawQuote
+)
+
 type formatOptions struct {
 	// DiffMode controls the output mode of FormatDiff.
 	//
@@ -51,6 +77,166 @@ type formatOptions struct {
 	// a slice or map node.
 	TypeMode typeMode
 
+	// BitDiffs controls whether to annotate differing byte arrays and
+	// byte slices of equal length with the positions of the differing bits.
+	BitDiffs bool
+
+	// PathOptions is the list of report options limited to certain paths
+	// (see FilterPath), which are applied in FormatDiff.
+	PathOptions []pathReportOption
+
+	// BytesModes maps string and byte slice types to how their differences
+	// are printed, where the entry for a nil type applies to all such types.
+	BytesModes map[reflect.Type]bytesMode
+
+	// RedactedFields is the set of struct fields (by struct type and
+	// field name) whose values are printed as "<redacted>".
+	RedactedFields map[reflect.Type]map[string]bool
+
+	// RedactedPaths is the list of path patterns whose values (and their
+	// descendants) are printed as "<redacted>".
+	RedactedPaths []*PathPattern
+	// valuePath is the path of the value being formatted, which is only
+	// tracked if RedactedPaths is non-empty.
+	valuePath Path
+
+	// Bitmasks is the set of integer types whose differing values are
+	// annotated with the bits that were set or cleared,
+	// where the entry for a nil type applies to all integer types.
+	Bitmasks map[reflect.Type]bool
+
+	// JSONValues controls whether to annotate differing leaf values
+	// with their JSON representation.
+	JSONValues bool
+
+	// AppliedOptions controls whether to annotate equal nodes with the
+	// Equal method, Comparer, or Transformer that determined equality.
+	AppliedOptions bool
+
+	// MapSummary controls whether to print a summary of the keys only in x,
+	// only in y, and changed before the entries of a differing map.
+	MapSummary bool
+
+	// MapPresence controls whether to annotate map entries that are
+	// only in x or only in y.
+	MapPresence bool
+
+	// DetectMoves controls whether to annotate slice elements that were
+	// removed from one index and inserted at another.
+	DetectMoves bool
+
+	// DetectRekeys controls whether to annotate map entries that were
+	// removed from one key and inserted under another.
+	DetectRekeys bool
+
+	// SetTransformers is the set of names of Transformers that produce
+	// slices whose order is insignificant. The differing results of such
+	// transformations are preceded by a summary of the set difference.
+	SetTransformers map[string]bool
+	// WithinSet reports whether the current node is the result of
+	// a transformation in SetTransformers.
+	WithinSet bool
+
+	// Indent is the string used to indent each level of the report.
+	// If empty, each level is indented with a single tab.
+	Indent string
+
+	// CompactLength is the maximum length of a report printed on a single
+	// line. If zero, reports are always printed across multiple lines.
+	CompactLength int
+
+	// Color controls whether to print removed and inserted lines
+	// in red and green using ANSI escape sequences.
+	Color bool
+
+	// CollapsePointers controls whether to print a chain of pointers
+	// with a single prefix (e.g., "&³") rather than repeated "&" prefixes.
+	CollapsePointers bool
+
+	// IndentJSON controls whether to re-indent differing strings and
+	// byte slices that are both JSON objects or arrays before diffing them.
+	IndentJSON bool
+
+	// Summary controls whether to append a summary of the comparison.
+	Summary bool
+
+	// Deterministic controls whether the report is byte-for-byte identical
+	// for the same inputs across different runs of the same program.
+	Deterministic bool
+
+	// CoalesceHunks controls whether to print all removed records
+	// before all inserted records within each group of adjacent
+	// differing records, rather than interleaving them.
+	CoalesceHunks bool
+
+	// FullContext controls whether to print all fields, elements,
+	// and entries, including those that are equal or zero values,
+	// without limiting the verbosity.
+	FullContext bool
+
+	// ContextRecords is the number of surrounding equal records to print
+	// around each group of differing records if HasContextRecords is set.
+	// Otherwise, numContextRecords is used.
+	HasContextRecords bool
+	ContextRecords    int
+
+	// Unified controls whether to print the report in the unified diff format
+	// with UnifiedContext lines of context surrounding each hunk.
+	Unified        bool
+	UnifiedContext int
+
+	// TableOfContents controls whether to list the top-level struct fields
+	// that contain differences before the report.
+	TableOfContents bool
+
+	// GroupByType controls whether to print the differing leaf values
+	// grouped by their concrete type instead of as a tree.
+	GroupByType bool
+
+	// MaxStringLen is the length that strings and the results of String
+	// and Error methods are truncated to where the verbosity is limited,
+	// where zero means a length depending on the verbosity level and
+	// a negative value means no truncation.
+	MaxStringLen int
+
+	// QuoteMode controls whether strings are printed as
+	// double-quoted or raw string literals.
+	QuoteMode quoteMode
+
+	// ShowWhitespace controls whether to print strings such that
+	// differences only in whitespace are visible.
+	ShowWhitespace bool
+
+	// EscapeUnicode controls whether to escape all non-ASCII characters
+	// in strings, which is used to distinguish strings that only differ
+	// by invisible or confusable characters.
+	EscapeUnicode bool
+
+	// LogSafe controls whether to avoid tabs and control characters
+	// in the report.
+	LogSafe bool
+
+	// LineWidth is the length at which lists are printed across multiple
+	// lines, where zero means that the default heuristics are used.
+	LineWidth int
+
+	// MaxDiffs is the maximum number of differences to report,
+	// where zero means that the number is unlimited.
+	MaxDiffs int
+
+	// MaxContainerDiffs is the maximum number of differing records to print
+	// within each struct, slice, or map, where zero means that the number
+	// is limited only by the verbosity.
+	MaxContainerDiffs int
+
+	// MaxBytes is the maximum size of the report in bytes, where zero means
+	// that the size is unlimited.
+	MaxBytes int
+
+	// VerbosityOffset is added to the verbosity level used for
+	// each node in the report.
+	VerbosityOffset int
+
 	// formatValueOptions are options specific to printing reflect.Values.
 	formatValueOptions
 }
@@ -65,9 +251,19 @@ func (opts formatOptions) WithTypeMode(t typeMode) formatOptions {
 }
 func (opts formatOptions) WithVerbosity(level int) formatOptions {
 	opts.VerbosityLevel = level
-	opts.LimitVerbosity = true
+	opts.LimitVerbosity = !opts.FullContext
 	return opts
 }
+
+// maxDiffRecords returns the maximum number of differing records to print
+// within a single container, given the maximum derived from the verbosity.
+// A negative value means that the number is unlimited.
+func (opts formatOptions) maxDiffRecords(maxLen int) int {
+	if opts.MaxContainerDiffs > 0 && (maxLen < 0 || maxLen > opts.MaxContainerDiffs) {
+		return opts.MaxContainerDiffs
+	}
+	return maxLen
+}
 func (opts formatOptions) verbosity() uint {
 	switch {
 	case opts.VerbosityLevel < 0:
@@ -79,6 +275,29 @@ func (opts formatOptions) verbosity() uint {
 	}
 }
 
+// disambiguateTypes returns options that print types using fully qualified
+// names with type arguments if the dynamic types of vx and vy differ,
+// but would otherwise be printed with the same name
+// (e.g., two types named "foo.Bar" declared in different packages).
+func (opts formatOptions) disambiguateTypes(vx, vy reflect.Value) formatOptions {
+	if !vx.IsValid() || !vy.IsValid() {
+		return opts
+	}
+	tx, ty := vx.Type(), vy.Type()
+	if vx.Kind() == reflect.Interface && !vx.IsNil() {
+		tx = vx.Elem().Type()
+	}
+	if vy.Kind() == reflect.Interface && !vy.IsNil() {
+		ty = vy.Elem().Type()
+	}
+	if tx != ty && opts.formatTypeName(tx) == opts.formatTypeName(ty) {
+		opts.QualifiedNames = true
+		opts.TypeNames = nil
+		opts.ElideTypeArgs = false
+	}
+	return opts
+}
+
 const maxVerbosityPreset = 3
 
 // verbosityPreset modifies the verbosity settings given an index
@@ -97,13 +316,32 @@ func verbosityPreset(opts formatOptions, i int) formatOptions {
 
 // FormatDiff converts a valueNode tree into a textNode tree, where the later
 // is a textual representation of the differences detected in the former.
-func (opts formatOptions) FormatDiff(v *valueNode) textNode {
+func (opts formatOptions) FormatDiff(v *valueNode) (out textNode) {
+	// Annotate the root node, since it is not a record within any list.
+	if v.parent == nil && opts.DiffMode == diffUnknown {
+		defer func() {
+			if c := opts.formatRecordComment(v); c != nil {
+				out = textWrap{"", out, " // " + c.String()}
+			}
+		}()
+	}
+
+	if v.Redacted {
+		return textRedacted
+	}
+	if len(opts.PathOptions) > 0 || len(opts.RedactedPaths) > 0 {
+		opts = opts.withPath(v.Path())
+	}
+
 	if opts.DiffMode == diffIdentical {
-		opts = opts.WithVerbosity(1)
+		opts = opts.WithVerbosity(1 + opts.VerbosityOffset)
 	} else {
-		opts = opts.WithVerbosity(3)
+		opts = opts.WithVerbosity(3 + opts.VerbosityOffset)
 	}
 
+	// Values rewritten by a report transformer are formatted as a whole.
+	isLeaf := v.MaxDepth == 0 || opts.transformer(v.Type) != nil
+
 	// Check whether we have specialized formatting for this node.
 	// This is not necessary, but helpful for producing more readable outputs.
 	if opts.CanFormatDiffSlice(v) {
@@ -116,7 +354,7 @@ func (opts formatOptions) FormatDiff(v *valueNode) textNode {
 	}
 
 	// For leaf nodes, format the value based on the reflect.Values alone.
-	if v.MaxDepth == 0 {
+	if isLeaf {
 		switch opts.DiffMode {
 		case diffUnknown, diffIdentical:
 			// Format Equal.
@@ -135,6 +373,7 @@ func (opts formatOptions) FormatDiff(v *valueNode) textNode {
 			// Format unequal.
 			assert(opts.DiffMode == diffUnknown)
 			var list textList
+			opts := opts.disambiguateTypes(v.ValueX, v.ValueY)
 			outx := opts.WithTypeMode(elideType).FormatValue(v.ValueX, withinSlice, visitedPointers{})
 			outy := opts.WithTypeMode(elideType).FormatValue(v.ValueY, withinSlice, visitedPointers{})
 			for i := 0; i <= maxVerbosityPreset && outx != nil && outy != nil && outx.Equal(outy); i++ {
@@ -142,6 +381,14 @@ func (opts formatOptions) FormatDiff(v *valueNode) textNode {
 				outx = opts2.FormatValue(v.ValueX, withinSlice, visitedPointers{})
 				outy = opts2.FormatValue(v.ValueY, withinSlice, visitedPointers{})
 			}
+			if outx != nil && outy != nil && outx.Equal(outy) && len(opts.Transformers) > 0 {
+				// The transformed values are indistinguishable,
+				// so print the original values instead.
+				opts2 := opts.WithTypeMode(elideType)
+				opts2.Transformers = nil
+				outx = opts2.FormatValue(v.ValueX, withinSlice, visitedPointers{})
+				outy = opts2.FormatValue(v.ValueY, withinSlice, visitedPointers{})
+			}
 			if outx != nil {
 				list = append(list, textRecord{Diff: '-', Value: outx})
 			}
@@ -162,15 +409,21 @@ func (opts formatOptions) FormatDiff(v *valueNode) textNode {
 
 	// Descend into the child value node.
 	if v.TransformerName != "" {
+		opts := opts
+		opts.WithinSet = opts.SetTransformers[v.TransformerName]
 		out := opts.WithTypeMode(emitType).FormatDiff(v.Value)
 		out = textWrap{"Inverse(" + v.TransformerName + ", ", out, ")"}
 		return opts.FormatType(v.Type, out)
 	} else {
 		switch k := v.Type.Kind(); k {
 		case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
-			return opts.FormatType(v.Type, opts.formatDiffList(v.Records, k))
+			out := opts.formatDiffList(v.Records, k)
+			if hdr := opts.formatDiffHeader(v); hdr != "" {
+				out = textWrap{hdr, out, ""}
+			}
+			return opts.FormatType(v.Type, out)
 		case reflect.Ptr:
-			return textWrap{"&", opts.FormatDiff(v.Value), ""}
+			return opts.formatPointerChain(opts.formatDiffHeader(v), opts.FormatDiff(v.Value))
 		case reflect.Interface:
 			return opts.WithTypeMode(emitType).FormatDiff(v.Value)
 		default:
@@ -180,6 +433,20 @@ func (opts formatOptions) FormatDiff(v *valueNode) textNode {
 }
 
 func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) textNode {
+	withinSet := opts.WithinSet
+	opts.WithinSet = false
+
+	// Map entries are ordered by key, which for pointers is their address.
+	// Reorder them by their formatted key to be independent of addresses.
+	if k == reflect.Map && opts.Deterministic && len(recs) > 0 && hasAddressOrder(recs[0].Key.Type()) {
+		kopts := opts
+		kopts.PointerLabels, kopts.labels = false, nil
+		recs = append([]reportRecord(nil), recs...)
+		sort.SliceStable(recs, func(i, j int) bool {
+			return kopts.formatValueLine(recs[i].Key) < kopts.formatValueLine(recs[j].Key)
+		})
+	}
+
 	// Derive record name based on the data structure kind.
 	var name string
 	var formatKey func(reflect.Value) string
@@ -195,7 +462,7 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 	case reflect.Map:
 		name = "entry"
 		opts = opts.WithTypeMode(elideType)
-		formatKey = func(v reflect.Value) string { return formatMapKey(v, false) }
+		formatKey = func(v reflect.Value) string { return opts.formatMapKey(v, false) }
 	}
 
 	maxLen := -1
@@ -220,7 +487,7 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 			}
 
 			// Elide struct fields that are zero value.
-			if k == reflect.Struct {
+			if k == reflect.Struct && !opts.FullContext {
 				var isZero bool
 				switch opts.DiffMode {
 				case diffIdentical:
@@ -244,6 +511,9 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 			}
 			if out := opts.FormatDiff(r.Value); out != nil {
 				list = append(list, textRecord{Key: formatKey(r.Key), Value: out})
+				if c := opts.formatRecordComment(r.Value); c != nil {
+					list[len(list)-1].Comment = c
+				}
 			}
 		}
 		if deferredEllipsis {
@@ -256,10 +526,29 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 	}
 
 	// Handle differencing.
+	maxLen = opts.maxDiffRecords(maxLen)
 	var numDiffs int
 	var list textList
 	var keys []reflect.Value // invariant: len(list) == len(keys)
+	var moves map[*valueNode]string
+	if ((k == reflect.Slice || k == reflect.Array) && opts.DetectMoves) || (k == reflect.Map && opts.DetectRekeys) {
+		moves = opts.detectMoves(recs, k)
+	}
+	if k == reflect.Map && opts.MapSummary {
+		list = opts.formatMapSummary(recs)
+		keys = make([]reflect.Value, len(list))
+	}
+	if k == reflect.Slice && withinSet {
+		list = opts.formatSetSummary(recs)
+		keys = make([]reflect.Value, len(list))
+	}
 	groups := coalesceAdjacentRecords(name, recs)
+	if opts.AppliedOptions {
+		groups = splitAppliedRecords(groups, recs)
+	}
+	if maxLen >= 0 {
+		groups = splitDiffRecords(groups, recs, maxLen)
+	}
 	maxGroup := diffStats{Name: name}
 	for i, ds := range groups {
 		if maxLen >= 0 && numDiffs >= maxLen {
@@ -272,13 +561,13 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 			// Compute the number of leading and trailing records to print.
 			var numLo, numHi int
 			numEqual := ds.NumIgnored + ds.NumIdentical
-			for numLo < numContextRecords && numLo+numHi < numEqual && i != 0 {
+			for numLo < opts.numContext() && numLo+numHi < numEqual && i != 0 {
 				if r := recs[numLo].Value; r.NumIgnored > 0 && r.NumSame+r.NumDiff == 0 {
 					break
 				}
 				numLo++
 			}
-			for numHi < numContextRecords && numLo+numHi < numEqual && i != len(groups)-1 {
+			for numHi < opts.numContext() && numLo+numHi < numEqual && i != len(groups)-1 {
 				if r := recs[numEqual-numHi-1].Value; r.NumIgnored > 0 && r.NumSame+r.NumDiff == 0 {
 					break
 				}
@@ -287,11 +576,14 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 			if numEqual-(numLo+numHi) == 1 && ds.NumIgnored == 0 {
 				numHi++ // Avoid pointless coalescing of a single equal record
 			}
+			if opts.FullContext {
+				numLo, numHi = numEqual, 0
+			}
 
 			// Format the equal values.
 			for _, r := range recs[:numLo] {
 				out := opts.WithDiffMode(diffIdentical).FormatDiff(r.Value)
-				list = append(list, textRecord{Key: formatKey(r.Key), Value: out})
+				list = append(list, textRecord{Key: formatKey(r.Key), Value: out, Comment: opts.formatRecordComment(r.Value)})
 				keys = append(keys, r.Key)
 			}
 			if numEqual > numLo+numHi {
@@ -303,7 +595,7 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 			}
 			for _, r := range recs[numEqual-numHi : numEqual] {
 				out := opts.WithDiffMode(diffIdentical).FormatDiff(r.Value)
-				list = append(list, textRecord{Key: formatKey(r.Key), Value: out})
+				list = append(list, textRecord{Key: formatKey(r.Key), Value: out, Comment: opts.formatRecordComment(r.Value)})
 				keys = append(keys, r.Key)
 			}
 			recs = recs[numEqual:]
@@ -311,13 +603,20 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 		}
 
 		// Handle unequal records.
+		start := len(list)
 		for _, r := range recs[:ds.NumDiff()] {
 			switch {
+			case r.Value.Redacted:
+				list = append(list, textRecord{Diff: diffRemoved, Key: formatKey(r.Key), Value: textRedacted})
+				list = append(list, textRecord{Diff: diffInserted, Key: formatKey(r.Key), Value: textRedacted})
+				keys = append(keys, r.Key, r.Key)
+				continue
 			case opts.CanFormatDiffSlice(r.Value):
 				out := opts.FormatDiffSlice(r.Value)
 				list = append(list, textRecord{Key: formatKey(r.Key), Value: out})
 				keys = append(keys, r.Key)
 			case r.Value.NumChildren == r.Value.MaxDepth:
+				opts := opts.disambiguateTypes(r.Value.ValueX, r.Value.ValueY)
 				outx := opts.WithDiffMode(diffRemoved).FormatDiff(r.Value)
 				outy := opts.WithDiffMode(diffInserted).FormatDiff(r.Value)
 				for i := 0; i <= maxVerbosityPreset && outx != nil && outy != nil && outx.Equal(outy); i++ {
@@ -325,19 +624,50 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 					outx = opts2.WithDiffMode(diffRemoved).FormatDiff(r.Value)
 					outy = opts2.WithDiffMode(diffInserted).FormatDiff(r.Value)
 				}
+				if outx != nil && outy != nil && isConfusableNode(r.Value) {
+					opts2 := opts
+					opts2.EscapeUnicode = true // print the code points of confusable characters
+					outx = opts2.WithDiffMode(diffRemoved).FormatDiff(r.Value)
+					outy = opts2.WithDiffMode(diffInserted).FormatDiff(r.Value)
+				}
+				if outx != nil && outy != nil && outx.Equal(outy) && len(opts.Transformers) > 0 {
+					opts2 := opts
+					opts2.Transformers = nil // print the original values instead
+					outx = opts2.WithDiffMode(diffRemoved).FormatDiff(r.Value)
+					outy = opts2.WithDiffMode(diffInserted).FormatDiff(r.Value)
+				}
 				if outx != nil {
-					list = append(list, textRecord{Diff: diffRemoved, Key: formatKey(r.Key), Value: outx})
+					list = append(list, textRecord{Diff: diffRemoved, Key: formatKey(r.Key), Value: outx, Comment: opts.withValuePath(r.Value).formatJSONComment(r.Value.ValueX)})
 					keys = append(keys, r.Key)
 				}
 				if outy != nil {
-					list = append(list, textRecord{Diff: diffInserted, Key: formatKey(r.Key), Value: outy})
+					list = append(list, textRecord{Diff: diffInserted, Key: formatKey(r.Key), Value: outy, Comment: opts.withValuePath(r.Value).formatJSONComment(r.Value.ValueY)})
 					keys = append(keys, r.Key)
 				}
+				c := opts.formatPresenceComment(k, r.Value)
+				if s, ok := moves[r.Value]; ok {
+					c = commentString(s)
+				}
+				if c != nil && len(list) > 0 {
+					if c2 := list[len(list)-1].Comment; c2 != nil {
+						c = commentString(c.String() + "; " + c2.String())
+					}
+					list[len(list)-1].Comment = c
+				}
 			default:
 				out := opts.FormatDiff(r.Value)
 				list = append(list, textRecord{Key: formatKey(r.Key), Value: out})
 				keys = append(keys, r.Key)
 			}
+			if c := opts.formatRecordComment(r.Value); c != nil && len(list) > 0 {
+				if c2 := list[len(list)-1].Comment; c2 != nil {
+					c = commentString(c.String() + "; " + c2.String())
+				}
+				list[len(list)-1].Comment = c
+			}
+		}
+		if opts.CoalesceHunks {
+			coalesceHunks(list[start:], keys[start:])
 		}
 		recs = recs[ds.NumDiff():]
 		numDiffs += ds.NumDiff()
@@ -373,7 +703,7 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 		if ambiguous {
 			for i, k := range keys {
 				if k.IsValid() {
-					list[i].Key = formatMapKey(k, true)
+					list[i].Key = opts.formatMapKey(k, true)
 				}
 			}
 		}
@@ -382,6 +712,662 @@ func (opts formatOptions) formatDiffList(recs []reportRecord, k reflect.Kind) te
 	return textWrap{"{", list, "}"}
 }
 
+// formatMapSummary returns a list of comment lines summarizing the keys of
+// the map entries that are only in x, only in y, or changed.
+func (opts formatOptions) formatMapSummary(recs []reportRecord) (list textList) {
+	const maxKeys = 8
+	var onlyX, onlyY, changed []reflect.Value
+	for _, r := range recs {
+		switch rv := r.Value; {
+		case rv.NumDiff == 0:
+		case !rv.ValueY.IsValid():
+			onlyX = append(onlyX, r.Key)
+		case !rv.ValueX.IsValid():
+			onlyY = append(onlyY, r.Key)
+		default:
+			changed = append(changed, r.Key)
+		}
+	}
+	for _, group := range []struct {
+		desc string
+		keys []reflect.Value
+	}{{"only in x", onlyX}, {"only in y", onlyY}, {"changed", changed}} {
+		if len(group.keys) == 0 {
+			continue
+		}
+		var ss []string
+		for i, k := range group.keys {
+			if i == maxKeys {
+				ss = append(ss, "...")
+				break
+			}
+			ss = append(ss, opts.formatMapKey(k, false))
+		}
+		line := fmt.Sprintf("// %d %s %s: %s", len(group.keys), pluralize("key", len(group.keys)), group.desc, strings.Join(ss, ", "))
+		list = append(list, textRecord{Value: textLine(line), ElideComma: true})
+	}
+	return list
+}
+
+// formatSetSummary returns a list of comment lines summarizing the elements
+// of a slice with insignificant order that are only in x, only in y,
+// or present in both but modified.
+func (opts formatOptions) formatSetSummary(recs []reportRecord) (list textList) {
+	const maxElems = 8
+	var onlyX, onlyY, modified []reflect.Value
+	for _, r := range recs {
+		switch rv := r.Value; {
+		case rv.NumDiff == 0:
+		case !rv.ValueY.IsValid():
+			onlyX = append(onlyX, rv.ValueX)
+		case !rv.ValueX.IsValid():
+			onlyY = append(onlyY, rv.ValueY)
+		default:
+			modified = append(modified, rv.ValueX)
+		}
+	}
+	for _, group := range []struct {
+		desc  string
+		elems []reflect.Value
+	}{{"only in x", onlyX}, {"only in y", onlyY}, {"in both but modified", modified}} {
+		if len(group.elems) == 0 {
+			continue
+		}
+		var ss []string
+		for i, v := range group.elems {
+			if i == maxElems {
+				ss = append(ss, "...")
+				break
+			}
+			ss = append(ss, opts.formatValueLine(v))
+		}
+		line := fmt.Sprintf("// %d %s %s: %s", len(group.elems), pluralize("element", len(group.elems)), group.desc, strings.Join(ss, ", "))
+		list = append(list, textRecord{Value: textLine(line), ElideComma: true})
+	}
+	return list
+}
+
+// formatDiffHeader returns the header of a pointer, slice, or map node
+// (see formatHeader) if PrintAddresses is set. Both headers are printed
+// if they differ between the x and y values.
+func (opts formatOptions) formatDiffHeader(v *valueNode) string {
+	if !opts.PrintAddresses {
+		return ""
+	}
+	hx, hy := opts.formatHeader(v.ValueX), opts.formatHeader(v.ValueY)
+	switch {
+	case opts.DiffMode == diffRemoved:
+		return hx
+	case opts.DiffMode == diffInserted:
+		return hy
+	case hx == hy:
+		return hx
+	}
+	unwrap := func(s string) string {
+		if s == "" {
+			return "nil"
+		}
+		return strings.TrimSuffix(strings.TrimPrefix(s, "⟪"), "⟫")
+	}
+	return "⟪-" + unwrap(hx) + " +" + unwrap(hy) + "⟫"
+}
+
+// formatTableOfContents returns a list of the top-level struct fields that
+// contain differences along with the number of differences in each.
+// It returns an empty string if the root is not a struct.
+func formatTableOfContents(v *valueNode) string {
+	for v.Value != nil {
+		v = v.Value // Look through pointers, interfaces, and transformations
+	}
+	if v.Type.Kind() != reflect.Struct {
+		return ""
+	}
+	var recs []reportRecord
+	var maxLen int
+	for _, r := range v.Records {
+		if r.Value.NumDiff > 0 {
+			recs = append(recs, r)
+			if n := len(r.Key.String()); n > maxLen {
+				maxLen = n
+			}
+		}
+	}
+	var b []byte
+	b = append(b, fmt.Sprintf("// %d %s with differences:\n", len(recs), pluralize("field", len(recs)))...)
+	for _, r := range recs {
+		name := r.Key.String()
+		b = append(b, "//\t"+name+": "...)
+		b = repeatCount(maxLen-len(name)).appendChar(b, ' ')
+		b = append(b, fmt.Sprintf("%d %s\n", r.Value.NumDiff, pluralize("difference", r.Value.NumDiff))...)
+	}
+	return string(b)
+}
+
+// formatGroupedByType returns a list of the unequal leaf nodes of v grouped
+// by their concrete type, in the order that each type is first encountered.
+func (opts formatOptions) formatGroupedByType(v *valueNode) string {
+	var types []reflect.Type
+	groups := make(map[reflect.Type][]*valueNode)
+	var walk func(*valueNode)
+	walk = func(v *valueNode) {
+		switch {
+		case v.NumDiff == 0:
+		case v.Value != nil:
+			walk(v.Value)
+		case len(v.Records) > 0:
+			for _, r := range v.Records {
+				walk(r.Value)
+			}
+		default:
+			t := v.Type
+			for _, vv := range []reflect.Value{v.ValueX, v.ValueY} {
+				if t.Kind() == reflect.Interface && vv.IsValid() && !vv.IsNil() {
+					t = vv.Elem().Type()
+				}
+			}
+			if _, ok := groups[t]; !ok {
+				types = append(types, t)
+			}
+			groups[t] = append(groups[t], v)
+		}
+	}
+	walk(v)
+
+	var b []byte
+	for _, t := range types {
+		vs := groups[t]
+		b = append(b, fmt.Sprintf("%s: %d %s\n", opts.formatTypeName(t), len(vs), pluralize("difference", len(vs)))...)
+		for _, v := range vs {
+			p := v.Path()
+			for _, d := range []struct {
+				diff diffMode
+				v    reflect.Value
+			}{{diffRemoved, v.ValueX}, {diffInserted, v.ValueY}} {
+				if !d.v.IsValid() {
+					continue
+				}
+				s := opts.withPath(p).formatValueLine(d.v)
+				if v.Redacted || opts.isRedactedPath(p) {
+					s = string(textRedacted)
+				}
+				b = append(b, fmt.Sprintf("%c\t%#v: %s\n", d.diff, p, s)...)
+			}
+		}
+	}
+	return string(b)
+}
+
+// formatJSONComment returns a comment with the JSON representation of v,
+// or nil if not requested or if v cannot be represented in JSON.
+func (opts formatOptions) formatJSONComment(v reflect.Value) fmt.Stringer {
+	if !opts.JSONValues || !v.IsValid() || !v.CanInterface() || opts.hasRedacted(v, opts.valuePath, nil) {
+		return nil
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil
+	}
+	return commentString("JSON: " + string(b))
+}
+
+// detectMoves pairs each slice element or map entry only in x with one
+// only in y that is formatted identically, and returns comments describing
+// the move (or change of key) for each record of every pair.
+// Pairs are matched greedily in the order of the records.
+func (opts formatOptions) detectMoves(recs []reportRecord, k reflect.Kind) map[*valueNode]string {
+	type candidate struct {
+		node *valueNode
+		out  textNode
+	}
+	var inserted []candidate
+	for _, r := range recs {
+		if !r.Value.ValueX.IsValid() && r.Value.ValueY.IsValid() {
+			if out := opts.WithDiffMode(diffInserted).FormatDiff(r.Value); out != nil {
+				inserted = append(inserted, candidate{r.Value, out})
+			}
+		}
+	}
+	moves := make(map[*valueNode]string)
+	for _, r := range recs {
+		if !r.Value.ValueX.IsValid() || r.Value.ValueY.IsValid() || len(inserted) == 0 {
+			continue
+		}
+		outx := opts.WithDiffMode(diffRemoved).FormatDiff(r.Value)
+		if outx == nil {
+			continue
+		}
+		for i, c := range inserted {
+			if outx.Equal(c.out) {
+				if k == reflect.Map {
+					kx := opts.formatMapKey(r.Value.step.(MapIndex).Key(), false)
+					ky := opts.formatMapKey(c.node.step.(MapIndex).Key(), false)
+					moves[r.Value] = "renamed to key " + ky
+					moves[c.node] = "renamed from key " + kx
+				} else {
+					ix, _ := r.Value.step.(SliceIndex).SplitKeys()
+					_, iy := c.node.step.(SliceIndex).SplitKeys()
+					moves[r.Value] = fmt.Sprintf("moved to index %d", iy)
+					moves[c.node] = fmt.Sprintf("moved from index %d", ix)
+				}
+				inserted = append(inserted[:i], inserted[i+1:]...)
+				break
+			}
+		}
+	}
+	return moves
+}
+
+// formatPresenceComment returns a comment to annotate a map entry
+// that is only in x or only in y, if requested.
+func (opts formatOptions) formatPresenceComment(k reflect.Kind, v *valueNode) fmt.Stringer {
+	switch {
+	case k != reflect.Map || !opts.MapPresence:
+		return nil
+	case !v.ValueY.IsValid():
+		return commentString("only in x")
+	case !v.ValueX.IsValid():
+		return commentString("only in y")
+	}
+	return nil
+}
+
+// formatRecordComment returns an optional comment to annotate a node.
+func (opts formatOptions) formatRecordComment(v *valueNode) fmt.Stringer {
+	if opts.AppliedOptions && v.NumDiff == 0 {
+		for v.AppliedBy == "" && v.Value != nil {
+			v = v.Value
+		}
+		if v.AppliedBy != "" {
+			return commentString("equal by " + v.AppliedBy)
+		}
+		return nil
+	}
+	for v2 := v; v2 != nil && v2.NumDiff > 0; v2 = v2.Value {
+		if v2.Annotator != nil {
+			if s := v2.Annotator.annotation(v2.Type, v2.ValueX, v2.ValueY); s != "" {
+				return commentString(s)
+			}
+		}
+	}
+	if opts.BitDiffs {
+		if s := formatBitDiff(v); s != "" {
+			return commentString(s)
+		}
+	}
+	if s := opts.formatBitmaskDiff(v); s != "" {
+		return commentString(s)
+	}
+	return nil
+}
+
+// bytesMode reports how differences in values of type t are printed.
+func (opts formatOptions) bytesMode(t reflect.Type) bytesMode {
+	if m, ok := opts.BytesModes[t]; ok {
+		return m
+	}
+	if t.Kind() == reflect.String || t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return opts.BytesModes[nil]
+	}
+	return autoBytes
+}
+
+// coalesceHunks reorders each run of adjacent removed and inserted records
+// such that all removed records precede all inserted records,
+// preserving their relative order. The keys are reordered accordingly.
+func coalesceHunks(list textList, keys []reflect.Value) {
+	for i := 0; i < len(list); {
+		j := i
+		for j < len(list) && (list[j].Diff == diffRemoved || list[j].Diff == diffInserted) {
+			j++
+		}
+		if j == i {
+			i++
+			continue
+		}
+		var recs textList
+		var ks []reflect.Value
+		for _, d := range []diffMode{diffRemoved, diffInserted} {
+			for k := i; k < j; k++ {
+				if list[k].Diff == d {
+					recs = append(recs, list[k])
+					ks = append(ks, keys[k])
+				}
+			}
+		}
+		copy(list[i:j], recs)
+		copy(keys[i:j], ks)
+		i = j
+	}
+}
+
+// numContext reports the number of surrounding equal records to print.
+func (opts formatOptions) numContext() int {
+	if opts.HasContextRecords {
+		return opts.ContextRecords
+	}
+	return numContextRecords
+}
+
+// isRedacted reports whether the named field of struct type t is redacted.
+func (opts formatOptions) isRedacted(t reflect.Type, name string) bool {
+	return opts.RedactedFields[t][name]
+}
+
+// hasAddressOrder reports whether values of type t may be ordered by
+// their address when sorted as map keys.
+func hasAddressOrder(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer, reflect.Interface:
+		return true
+	case reflect.Array:
+		return hasAddressOrder(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasAddressOrder(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasRedacted reports whether v contains any struct with a redacted field
+// or any value at a redacted path, where p is the path of v (or nil if
+// it is not tracked) and seen is the set of pointers already visited.
+func (opts formatOptions) hasRedacted(v reflect.Value, p Path, seen map[uintptr]bool) bool {
+	if len(opts.RedactedFields) == 0 && (p == nil || len(opts.RedactedPaths) == 0) {
+		return false
+	}
+	if p != nil && opts.matchesRedactedPath(p) {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return false
+		}
+		if seen == nil {
+			seen = make(map[uintptr]bool)
+		}
+		seen[v.Pointer()] = true
+		return opts.hasRedacted(v.Elem(), appendStep(p, func() PathStep { return indirectStep(v) }), seen)
+	case reflect.Interface:
+		return !v.IsNil() && opts.hasRedacted(v.Elem(), appendStep(p, func() PathStep { return assertStep(v) }), seen)
+	case reflect.Struct:
+		if len(opts.RedactedFields[v.Type()]) > 0 {
+			return true
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if opts.hasRedacted(v.Field(i), appendStep(p, func() PathStep { return fieldStep(v, i) }), seen) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if opts.hasRedacted(v.Index(i), appendStep(p, func() PathStep { return indexStep(v, i) }), seen) {
+				return true
+			}
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			if opts.hasRedacted(k, nil, seen) || opts.hasRedacted(v.MapIndex(k), appendStep(p, func() PathStep { return entryStep(v, k) }), seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isRedactedPath reports whether any struct field along p is redacted,
+// or whether p or any of its ancestors matches a redacted path pattern.
+func (opts formatOptions) isRedactedPath(p Path) bool {
+	for i := 1; i < len(p); i++ {
+		if sf, ok := p[i].(StructField); ok && opts.isRedacted(p[i-1].Type(), sf.Name()) {
+			return true
+		}
+	}
+	for i := range p {
+		if opts.matchesRedactedPath(p[:i+1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesRedactedPath reports whether p matches a redacted path pattern.
+func (opts formatOptions) matchesRedactedPath(p Path) bool {
+	for _, pp := range opts.RedactedPaths {
+		if pp.Match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// withValuePath returns the options used to format the values of v,
+// which track the path of v if RedactedPaths is non-empty.
+func (opts formatOptions) withValuePath(v *valueNode) formatOptions {
+	if len(opts.RedactedPaths) > 0 {
+		opts.valuePath = v.Path()
+	}
+	return opts
+}
+
+// withStep returns the options used to format a child of the value being
+// formatted, where f returns the step to the child. The step is only
+// constructed if the path of the value is tracked.
+func (opts formatOptions) withStep(f func() PathStep) formatOptions {
+	opts.valuePath = appendStep(opts.valuePath, f)
+	return opts
+}
+
+// appendStep returns p extended with the step returned by f,
+// or nil if p is nil.
+func appendStep(p Path, f func() PathStep) Path {
+	if p == nil {
+		return nil
+	}
+	return append(p[:len(p):len(p)], f())
+}
+
+// The following functions return the step from the value v to one of its
+// children, where both values of the step are set to the child.
+
+func fieldStep(v reflect.Value, i int) PathStep {
+	sf := v.Type().Field(i)
+	vf := v.Field(i)
+	return StructField{&structField{pathStep: pathStep{sf.Type, vf, vf}, name: sf.Name, idx: i, field: sf, unexported: !isExported(sf.Name)}}
+}
+
+func indexStep(v reflect.Value, i int) PathStep {
+	vi := v.Index(i)
+	return SliceIndex{&sliceIndex{pathStep{v.Type().Elem(), vi, vi}, i, i, v.Kind() == reflect.Slice}}
+}
+
+func entryStep(v, k reflect.Value) PathStep {
+	vk := v.MapIndex(k)
+	return MapIndex{&mapIndex{pathStep{v.Type().Elem(), vk, vk}, k}}
+}
+
+func indirectStep(v reflect.Value) PathStep {
+	return Indirect{&indirect{pathStep{v.Type().Elem(), v.Elem(), v.Elem()}}}
+}
+
+func assertStep(v reflect.Value) PathStep {
+	return TypeAssertion{&typeAssertion{pathStep{v.Elem().Type(), v.Elem(), v.Elem()}}}
+}
+
+// isConfusableNode reports whether v is a pair of strings that only differ
+// by invisible or confusable characters, looking through pointers and
+// interfaces.
+func isConfusableNode(v *valueNode) bool {
+	for v.Value != nil && v.TransformerName == "" {
+		v = v.Value
+	}
+	vx, vy := v.ValueX, v.ValueY
+	if !vx.IsValid() || !vy.IsValid() || v.Type.Kind() != reflect.String {
+		return false
+	}
+	return isConfusable(vx.String(), vy.String())
+}
+
+// formatBitmaskDiff describes the bits that were set and cleared between
+// two integers that are treated as bitmasks (e.g., "0x13 → 0x17 (+0x04)"),
+// looking through pointers and interfaces.
+// It returns an empty string if not applicable.
+func (opts formatOptions) formatBitmaskDiff(v *valueNode) string {
+	for v.Value != nil && v.TransformerName == "" {
+		v = v.Value
+	}
+	vx, vy := v.ValueX, v.ValueY
+	if len(opts.Bitmasks) == 0 || !vx.IsValid() || !vy.IsValid() {
+		return ""
+	}
+	var x, y uint64
+	switch v.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, y = uint64(vx.Int()), uint64(vy.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, y = vx.Uint(), vy.Uint()
+	default:
+		return ""
+	}
+	if !opts.Bitmasks[v.Type] && !opts.Bitmasks[nil] {
+		return ""
+	}
+	bits := uint(v.Type.Bits())
+	if bits < 64 {
+		x &= 1<<bits - 1
+		y &= 1<<bits - 1
+	}
+	if x == y {
+		return ""
+	}
+	f := fmt.Sprintf("0x%%0%dx", bits/4)
+	var changes []string
+	if set := y &^ x; set != 0 {
+		changes = append(changes, "+"+fmt.Sprintf(f, set))
+	}
+	if cleared := x &^ y; cleared != 0 {
+		changes = append(changes, "-"+fmt.Sprintf(f, cleared))
+	}
+	return fmt.Sprintf(f+" → "+f+" (%s)", x, y, strings.Join(changes, " "))
+}
+
+// formatBitDiff describes the differing bits between two byte arrays or
+// byte slices of equal length, looking through pointers and interfaces.
+// It returns an empty string if not applicable.
+func formatBitDiff(v *valueNode) string {
+	const maxBytes = 64
+	const maxPositions = 16
+	for v.Value != nil && v.TransformerName == "" {
+		v = v.Value
+	}
+	vx, vy := v.ValueX, v.ValueY
+	switch {
+	case !vx.IsValid() || !vy.IsValid():
+		return ""
+	case v.Type.Kind() != reflect.Array && v.Type.Kind() != reflect.Slice:
+		return ""
+	case v.Type.Elem().Kind() != reflect.Uint8:
+		return ""
+	case vx.Len() != vy.Len() || vx.Len() == 0 || vx.Len() > maxBytes:
+		return ""
+	}
+
+	var positions []string
+	var numBits int
+	mask := make([]byte, vx.Len())
+	for i := range mask {
+		mask[i] = byte(vx.Index(i).Uint() ^ vy.Index(i).Uint())
+		for j := 0; j < 8; j++ {
+			if mask[i]&(0x80>>uint(j)) != 0 {
+				if numBits < maxPositions {
+					positions = append(positions, fmt.Sprint(8*i+j))
+				}
+				numBits++
+			}
+		}
+	}
+	if numBits == 0 {
+		return ""
+	}
+	if numBits > maxPositions {
+		positions = append(positions, "...")
+	}
+	return fmt.Sprintf("%d differing %s at %s (mask: 0x%x)",
+		numBits, pluralize("bit", numBits), strings.Join(positions, ", "), mask)
+}
+
+// splitDiffRecords splits the group of unequal records containing the
+// n-th unequal record such that the group ends with that record,
+// ensuring that no more than n unequal records are printed.
+func splitDiffRecords(groups []diffStats, recs []reportRecord, n int) (out []diffStats) {
+	var numDiffs int
+	for _, ds := range groups {
+		size := ds.NumIgnored + ds.NumIdentical + ds.NumDiff()
+		if numDiffs >= n || numDiffs+ds.NumDiff() <= n {
+			out = append(out, ds)
+			numDiffs += ds.NumDiff()
+			recs = recs[size:]
+			continue
+		}
+		first, rest := diffStats{Name: ds.Name}, diffStats{Name: ds.Name}
+		for i, r := range recs[:size] {
+			curr := &rest
+			if numDiffs+i < n {
+				curr = &first
+			}
+			switch rv := r.Value; {
+			case !rv.ValueY.IsValid():
+				curr.NumRemoved++
+			case !rv.ValueX.IsValid():
+				curr.NumInserted++
+			default:
+				curr.NumModified++
+			}
+		}
+		out = append(out, first, rest)
+		numDiffs += ds.NumDiff()
+		recs = recs[size:]
+	}
+	return out
+}
+
+// splitAppliedRecords splits each group of equal records such that every
+// record containing a node that was compared or transformed using an option
+// is in a group of its own, ensuring that the record is always printed.
+func splitAppliedRecords(groups []diffStats, recs []reportRecord) (out []diffStats) {
+	for _, ds := range groups {
+		n := ds.NumIgnored + ds.NumIdentical + ds.NumDiff()
+		if ds.NumDiff() > 0 {
+			out = append(out, ds)
+			recs = recs[n:]
+			continue
+		}
+		curr := diffStats{Name: ds.Name}
+		for _, r := range recs[:n] {
+			switch rv := r.Value; {
+			case rv.NumIgnored > 0 && rv.NumSame+rv.NumDiff == 0:
+				curr.NumIgnored++
+			case rv.NumCompared+rv.NumTransformed == 0:
+				curr.NumIdentical++
+			default:
+				if !curr.IsZero() {
+					out = append(out, curr)
+				}
+				out = append(out, diffStats{Name: ds.Name, NumIdentical: 1})
+				curr = diffStats{Name: ds.Name}
+			}
+		}
+		if !curr.IsZero() {
+			out = append(out, curr)
+		}
+		recs = recs[n:]
+	}
+	return out
+}
+
 // coalesceAdjacentRecords coalesces the list of records into groups of
 // adjacent equal, or unequal counts.
 func coalesceAdjacentRecords(name string, recs []reportRecord) (groups []diffStats) {
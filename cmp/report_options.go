@@ -0,0 +1,882 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp/internal/function"
+)
+
+// reportOption is an Option that configures how Diff formats the report.
+// Report options have no effect on the result of Equal.
+type reportOption struct {
+	name string
+	fnc  func(*formatOptions)
+}
+
+func (*reportOption) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+func (o *reportOption) String() string { return o.name }
+
+// valueReportOption is a reportOption that only affects how individual
+// values are formatted, such that it may be limited to certain paths
+// using FilterPath.
+type valueReportOption struct{ *reportOption }
+
+// filterPath returns a report option that only applies o to the values
+// at paths for which f returns true and to their descendants.
+func (o valueReportOption) filterPath(f func(Path) bool) Option {
+	name := fmt.Sprintf("FilterPath(%s, %v)", function.NameOf(reflect.ValueOf(f)), o)
+	return &reportOption{name, func(opts *formatOptions) {
+		opts.PathOptions = append(opts.PathOptions[:len(opts.PathOptions):len(opts.PathOptions)], pathReportOption{f, o.fnc})
+	}}
+}
+
+type pathReportOption struct {
+	filter func(Path) bool
+	fnc    func(*formatOptions)
+}
+
+// withPath returns the options used to format the value at path p,
+// which additionally applies every path-limited report option that
+// matches p or any of its ancestors.
+func (opts formatOptions) withPath(p Path) formatOptions {
+	if len(opts.RedactedPaths) > 0 {
+		opts.valuePath = p
+	}
+	for _, o := range opts.PathOptions {
+		for i := range p {
+			if o.filter(p[:i+1]) {
+				o.fnc(&opts)
+				break
+			}
+		}
+	}
+	return opts
+}
+
+// formatOptions returns the initial format options as configured by
+// all report options in the order that they were provided.
+func (s *state) formatOptions() (opts formatOptions) {
+	for _, o := range s.reportOpts {
+		o.fnc(&opts)
+	}
+	return opts
+}
+
+// ReportBitDiffs returns an Option that annotates differing byte arrays and
+// byte slices of equal length with the positions of the differing bits and
+// a mask of the differing bits. Bits are numbered starting from the most
+// significant bit of the first byte (i.e., network bit order).
+// Values longer than 64 bytes are not annotated.
+func ReportBitDiffs() Option {
+	return &reportOption{"ReportBitDiffs()", func(opts *formatOptions) {
+		opts.BitDiffs = true
+	}}
+}
+
+// ReportRedactFields returns an Option that prints the values of the named
+// fields of a struct as "<redacted>", which is useful for fields holding
+// secrets such as passwords or tokens. Redacted fields still affect the
+// result of the comparison, and differences in them are still reported,
+// but their values are never printed.
+//
+// The struct type is specified by passing in a value of that type and
+// the names must be the names of its fields. Fields promoted from an
+// embedded struct must be redacted on the embedded struct type instead.
+// Values that are not struct fields may be redacted using ReportRedactPaths.
+func ReportRedactFields(typ interface{}, names ...string) Option {
+	t := reflect.TypeOf(typ)
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("%T must be a struct", typ))
+	}
+	for _, name := range names {
+		sf, ok := t.FieldByName(name)
+		if !ok || strings.Contains(name, ".") {
+			panic(fmt.Sprintf("%v has no field %q", t, name))
+		}
+		if len(sf.Index) > 1 {
+			et := t.FieldByIndex(sf.Index[:len(sf.Index)-1]).Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			panic(fmt.Sprintf("%v field %q is promoted from embedded %v; redact it on %v instead", t, name, et, et))
+		}
+	}
+	name := fmt.Sprintf("ReportRedactFields(%v, %q)", t, names)
+	return &reportOption{name, func(opts *formatOptions) {
+		if opts.RedactedFields == nil {
+			opts.RedactedFields = make(map[reflect.Type]map[string]bool)
+		}
+		if opts.RedactedFields[t] == nil {
+			opts.RedactedFields[t] = make(map[string]bool)
+		}
+		for _, name := range names {
+			opts.RedactedFields[t][name] = true
+		}
+	}}
+}
+
+// ReportRedactPaths returns an Option that prints the values at paths that
+// match any of the provided patterns as "<redacted>", which is useful for
+// secrets that are not held in a struct field of their own, such as map
+// entries (e.g., ReportRedactPaths(`Headers["Authorization"]`)).
+// The patterns use the syntax of CompilePathPattern, and the descendants
+// of a matched value are redacted as well. As with ReportRedactFields,
+// redacted values still affect the result of the comparison,
+// but their values are never printed.
+// It panics if any pattern is invalid.
+func ReportRedactPaths(patterns ...string) Option {
+	var ps []*PathPattern
+	for _, pattern := range patterns {
+		ps = append(ps, MustCompilePathPattern(pattern))
+	}
+	return &reportOption{fmt.Sprintf("ReportRedactPaths(%q)", patterns), func(opts *formatOptions) {
+		opts.RedactedPaths = append(opts.RedactedPaths[:len(opts.RedactedPaths):len(opts.RedactedPaths)], ps...)
+	}}
+}
+
+// ReportBitmasks returns an Option that annotates differing integers with
+// the bits that were set and cleared (e.g., "0x0013 → 0x0017 (+0x0004)"),
+// which is useful for flag words and permission bits.
+// The option only applies to integers of the same type as one of the
+// provided values, or to all integers if no values are provided.
+func ReportBitmasks(types ...interface{}) Option {
+	ts, names := integerTypes(types)
+	return &reportOption{"ReportBitmasks(" + strings.Join(names, ", ") + ")", func(opts *formatOptions) {
+		if opts.Bitmasks == nil {
+			opts.Bitmasks = make(map[reflect.Type]bool)
+		}
+		for _, t := range ts {
+			opts.Bitmasks[t] = true
+		}
+	}}
+}
+
+// Verbosity returns an Option that adjusts how much of the compared values
+// are printed in the report, where 0 is the default level.
+// Each higher level roughly doubles the number of elements, fields, and
+// characters that are printed before the remainder is elided, such that
+// a sufficiently high level (e.g., 16) prints the values exhaustively.
+// Each lower level roughly halves them, such that a level of -1 omits
+// the content of equal composite values entirely.
+//
+// Regardless of the level, the report is made more verbose where necessary
+// to distinguish values that differ, but would otherwise print the same.
+func Verbosity(level int) Option {
+	return &reportOption{fmt.Sprintf("Verbosity(%d)", level), func(opts *formatOptions) {
+		opts.VerbosityOffset = level
+	}}
+}
+
+// MaxReportStringLength returns an Option that truncates strings in the
+// report to n bytes, including the results of String and Error methods.
+// By default, strings within values that are printed in their entirety
+// (e.g., equal values) are truncated to a length depending on the verbosity,
+// while differing strings are never truncated.
+// This option only changes the former, where n == 0 disables truncation.
+func MaxReportStringLength(n int) Option {
+	if n < 0 {
+		panic(fmt.Sprintf("invalid maximum string length: %d", n))
+	}
+	return &reportOption{fmt.Sprintf("MaxReportStringLength(%d)", n), func(opts *formatOptions) {
+		opts.MaxStringLen = n
+		if n == 0 {
+			opts.MaxStringLen = -1
+		}
+	}}
+}
+
+// MaxReportBytes returns an Option that limits the size of the report
+// to n bytes. If the report is larger, then it is truncated to the complete
+// lines that fit within n bytes, and a summary of how many bytes and lines
+// were omitted is appended. The size of the summary is not counted against n.
+func MaxReportBytes(n int) Option {
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid maximum report size: %d", n))
+	}
+	return &reportOption{fmt.Sprintf("MaxReportBytes(%d)", n), func(opts *formatOptions) {
+		opts.MaxBytes = n
+	}}
+}
+
+// ReportTimeLayout returns an Option that formats time.Time values in the
+// report using the provided layout (see time.Time.Format).
+// By default, time.Time values are formatted using time.RFC3339Nano.
+// If the formatted values of a difference are identical (e.g., they only
+// differ in their location), then the internal fields are printed instead.
+func ReportTimeLayout(layout string) Option {
+	if layout == "" {
+		panic("invalid time layout: empty string")
+	}
+	return &reportOption{fmt.Sprintf("ReportTimeLayout(%q)", layout), func(opts *formatOptions) {
+		opts.TimeLayout = layout
+	}}
+}
+
+// ReportIntegerBase returns an Option that formats integers in the report
+// using the provided base, which must be 2, 8, 10, or 16.
+// Values are printed in Go notation (e.g., "0b00010011", "0o644", or "0x13"),
+// where binary values are padded to a whole number of bytes.
+//
+// The option only applies to integers of the same type as one of the
+// provided values (e.g., ReportIntegerBase(16, uint32(0), Flags(0))),
+// or to all integers if no values are provided.
+// Integers with an Error or String method are still formatted using it.
+//
+// The option may be limited to certain paths using FilterPath
+// (e.g., FilterPath(MustCompilePathPattern("Flags").Match, ReportIntegerBase(16))),
+// in which case it also applies to the descendants of the matched values.
+func ReportIntegerBase(base int, types ...interface{}) Option {
+	switch base {
+	case 2, 8, 10, 16:
+	default:
+		panic(fmt.Sprintf("invalid integer base: %d", base))
+	}
+	ts, names := integerTypes(types)
+	name := fmt.Sprintf("ReportIntegerBase(%d)", base)
+	if len(names) > 0 {
+		name = fmt.Sprintf("ReportIntegerBase(%d, %s)", base, strings.Join(names, ", "))
+	}
+	return valueReportOption{&reportOption{name, func(opts *formatOptions) {
+		m := make(map[reflect.Type]int, len(opts.IntegerBases)+len(ts))
+		for t, b := range opts.IntegerBases {
+			m[t] = b
+		}
+		for _, t := range ts {
+			m[t] = base
+		}
+		opts.IntegerBases = m
+	}}}
+}
+
+// integerTypes returns the integer types of the provided values and their
+// names, where no values results in a single nil type that matches any type.
+func integerTypes(types []interface{}) (ts []reflect.Type, names []string) {
+	for _, typ := range types {
+		t := reflect.TypeOf(typ)
+		switch reflect.ValueOf(typ).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			panic(fmt.Sprintf("invalid integer type: %T", typ))
+		}
+		ts = append(ts, t)
+		names = append(names, t.String())
+	}
+	if len(ts) == 0 {
+		ts = []reflect.Type{nil}
+	}
+	return ts, names
+}
+
+// ReportEnumNames returns an Option that prints values of an integer type
+// using the provided names (e.g., "StateRunning (2)" instead of "2").
+// The names must be a map from an integer type to string, such as
+// map[State]string{StateIdle: "StateIdle", StateRunning: "StateRunning"}.
+// Values without a name are printed as usual.
+// This is intended for enum types that lack a String method,
+// which otherwise takes precedence.
+func ReportEnumNames(names interface{}) Option {
+	v := reflect.ValueOf(names)
+	if v.Kind() != reflect.Map || v.Type().Elem().Kind() != reflect.String {
+		panic(fmt.Sprintf("invalid enum names: %T", names))
+	}
+	switch v.Type().Key().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		panic(fmt.Sprintf("invalid enum names: %T", names))
+	}
+	return &reportOption{fmt.Sprintf("ReportEnumNames(%v)", v.Type()), func(opts *formatOptions) {
+		if opts.EnumNames == nil {
+			opts.EnumNames = make(map[reflect.Type]reflect.Value)
+		}
+		opts.EnumNames[v.Type().Key()] = v
+	}}
+}
+
+// ReportBytesAsText returns an Option that always prints differences in
+// strings and byte slices as text, even if they contain invalid UTF-8 or
+// many non-printable characters, which are escaped.
+// By default, they are printed as text unless they appear to be binary data,
+// in which case they are printed as a hexdump.
+// The option only applies to values of the same type as one of the
+// provided values, or to all strings and byte slices if none are provided.
+// It may also be limited to certain paths using FilterPath
+// (e.g., FilterPath(MustCompilePathPattern("Body").Match, ReportBytesAsText())).
+func ReportBytesAsText(types ...interface{}) Option {
+	return reportBytesAs("ReportBytesAsText", textBytes, types)
+}
+
+// ReportBytesAsBinary returns an Option that always prints differences in
+// strings and byte slices as a hexdump, even if they appear to be text.
+// The option only applies to values of the same type as one of the
+// provided values, or to all strings and byte slices if none are provided.
+// It may also be limited to certain paths using FilterPath.
+func ReportBytesAsBinary(types ...interface{}) Option {
+	return reportBytesAs("ReportBytesAsBinary", binaryBytes, types)
+}
+
+func reportBytesAs(name string, mode bytesMode, types []interface{}) Option {
+	var ts []reflect.Type
+	var names []string
+	for _, typ := range types {
+		t := reflect.TypeOf(typ)
+		if t == nil || !(t.Kind() == reflect.String || t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8) {
+			panic(fmt.Sprintf("invalid string or byte slice type: %T", typ))
+		}
+		ts = append(ts, t)
+		names = append(names, t.String())
+	}
+	if len(ts) == 0 {
+		ts = []reflect.Type{nil}
+	}
+	return valueReportOption{&reportOption{name + "(" + strings.Join(names, ", ") + ")", func(opts *formatOptions) {
+		m := make(map[reflect.Type]bytesMode, len(opts.BytesModes)+len(ts))
+		for t, bm := range opts.BytesModes {
+			m[t] = bm
+		}
+		for _, t := range ts {
+			m[t] = mode
+		}
+		opts.BytesModes = m
+	}}}
+}
+
+// ReportFloatFormat returns an Option that formats floating-point values in
+// the report using strconv.FormatFloat with the provided format and precision.
+// For example, ReportFloatFormat('g', 17) prints enough digits to distinguish
+// any two float64 values, ReportFloatFormat('f', 3) prints a fixed number of
+// decimal places, and ReportFloatFormat('g', -1) prints the shortest
+// representation that round-trips to the same value.
+// The format must be one of 'b', 'e', 'E', 'f', 'g', or 'G'.
+func ReportFloatFormat(format byte, prec int) Option {
+	if strings.IndexByte("beEfgG", format) < 0 {
+		panic(fmt.Sprintf("invalid float format: %q", format))
+	}
+	return &reportOption{fmt.Sprintf("ReportFloatFormat(%q, %d)", format, prec), func(opts *formatOptions) {
+		opts.FloatFormat = format
+		opts.FloatPrecision = prec
+	}}
+}
+
+// ReportTypes returns an Option that prefixes every value in the report with
+// its type, including equal values and values of primitive kinds, which are
+// otherwise printed without their type whenever it can be inferred from the
+// surrounding context. This is useful when comparing trees of interface{}
+// values (e.g., as decoded by encoding/json), where the types of values
+// within an interface are otherwise only printed where they are ambiguous.
+func ReportTypes() Option {
+	return &reportOption{"ReportTypes()", func(opts *formatOptions) {
+		opts.EmitTypes = true
+	}}
+}
+
+// ReportQualifiedNames returns an Option that prints type names qualified
+// by the full package path (e.g., "github.com/google/go-cmp/cmp.Path")
+// instead of only the package name (e.g., "cmp.Path").
+// Otherwise, qualified names are only printed where necessary to distinguish
+// between differing values that would otherwise be printed the same.
+func ReportQualifiedNames() Option {
+	return &reportOption{"ReportQualifiedNames()", func(opts *formatOptions) {
+		opts.QualifiedNames = true
+	}}
+}
+
+// ReportTypeNames returns an Option that prints named types using the name
+// returned by f, which is useful for aliasing long type names
+// (e.g., instantiations of generic types) with shorter ones.
+// The function f is called with every named type that is printed,
+// including those within composite types (e.g., the T in []*T),
+// and may return the empty string to print the type as usual.
+// If multiple ReportTypeNames options are provided, then they are
+// consulted in order until one of them returns a non-empty name.
+func ReportTypeNames(f func(reflect.Type) string) Option {
+	if f == nil {
+		panic("invalid type names function: nil")
+	}
+	return &reportOption{fmt.Sprintf("ReportTypeNames(%s)", function.NameOf(reflect.ValueOf(f))), func(opts *formatOptions) {
+		opts.TypeNames = append(opts.TypeNames[:len(opts.TypeNames):len(opts.TypeNames)], f)
+	}}
+}
+
+// ReportElideTypeArguments returns an Option that prints instantiations
+// of generic types without their type arguments
+// (e.g., "pkg.Map[...]" rather than "pkg.Map[string,pkg.Value[int]]").
+// Names provided by ReportTypeNames take precedence.
+func ReportElideTypeArguments() Option {
+	return &reportOption{"ReportElideTypeArguments()", func(opts *formatOptions) {
+		opts.ElideTypeArgs = true
+	}}
+}
+
+// ReportAddresses returns an Option that prints the address of every
+// pointer and map, and the address, length, and capacity of every slice
+// in the report (e.g., "⟪ptr:0xc000010000, len:2, cap:4⟫"). If the x and y
+// values of a node differ in these respects, then both are printed.
+// This is useful for debugging aliasing bugs, where values are equal,
+// but unexpectedly share (or do not share) the same memory.
+// See also ReportPointerLabels.
+func ReportAddresses() Option {
+	return &reportOption{"ReportAddresses()", func(opts *formatOptions) {
+		opts.PrintAddresses = true
+	}}
+}
+
+// ReportPointerLabels returns an Option that prints pointers, slices, maps,
+// channels, and functions using labels (e.g., "⟪p#1⟫") instead of their
+// addresses wherever an address would otherwise be printed (e.g., for
+// references to values that were already printed within a cycle).
+// Labels are numbered in the order that the addresses are first printed,
+// and the same address is printed with the same label at every occurrence,
+// which makes the report deterministic across runs.
+func ReportPointerLabels() Option {
+	return &reportOption{"ReportPointerLabels()", func(opts *formatOptions) {
+		opts.PointerLabels = true
+	}}
+}
+
+// ReportQuotedStrings returns an Option that always prints strings as
+// double-quoted string literals with escape sequences,
+// rather than as backtick-quoted raw string literals where they are shorter.
+func ReportQuotedStrings() Option {
+	return &reportOption{"ReportQuotedStrings()", func(opts *formatOptions) {
+		opts.QuoteMode = doubleQuote
+	}}
+}
+
+// ReportRawStrings returns an Option that prints strings as backtick-quoted
+// raw string literals whenever they can be represented as such on a single
+// line, even if the double-quoted form is no longer.
+func ReportRawStrings() Option {
+	return &reportOption{"ReportRawStrings()", func(opts *formatOptions) {
+		opts.QuoteMode = rawQuote
+	}}
+}
+
+// ReportWhitespace returns an Option that prints strings such that
+// differences only in whitespace are visible. Tabs and carriage returns are
+// always escaped (e.g., "\t" and "\r") and strings or lines with trailing
+// whitespace are always quoted, rather than being printed verbatim
+// within raw string literals or triple-quoted blocks.
+func ReportWhitespace() Option {
+	return &reportOption{"ReportWhitespace()", func(opts *formatOptions) {
+		opts.ShowWhitespace = true
+	}}
+}
+
+// ReportLogSafe returns an Option that produces a report that survives
+// line-oriented log transports (e.g., "go test -json" or journald) intact.
+// The report is indented with spaces instead of tabs, strings are printed
+// with tabs escaped, and any other control characters (e.g., carriage returns
+// or terminal escape sequences) are replaced with Go escape sequences,
+// such that every line of the report is a single physical line.
+// It takes precedence over ReportColor.
+func ReportLogSafe() Option {
+	return &reportOption{"ReportLogSafe()", func(opts *formatOptions) {
+		opts.LogSafe = true
+	}}
+}
+
+// ReportLineWidth returns an Option that prints any struct, slice, or map
+// across multiple lines if its single-line form is longer than n bytes,
+// and wraps rows of batched elements (e.g., in a []int) at n bytes.
+// By default, only removed or inserted values longer than 80 bytes
+// are printed across multiple lines.
+//
+// Since the width does not account for indentation and field names,
+// lines in the report may still be longer than n bytes.
+func ReportLineWidth(n int) Option {
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid line width: %d", n))
+	}
+	return &reportOption{fmt.Sprintf("ReportLineWidth(%d)", n), func(opts *formatOptions) {
+		opts.LineWidth = n
+	}}
+}
+
+// MaxReportDiffs returns an Option that limits the report to the first n
+// differences (in the order that they are encountered), followed by
+// a summary of how many differences were omitted (see DiffN).
+// It has no effect on whether the values are equal.
+func MaxReportDiffs(n int) Option {
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid maximum number of differences: %d", n))
+	}
+	return &reportOption{fmt.Sprintf("MaxReportDiffs(%d)", n), func(opts *formatOptions) {
+		opts.MaxDiffs = n
+	}}
+}
+
+// MaxReportDiffsPerContainer returns an Option that limits the number of
+// differing records (i.e., struct fields, slice elements, or map entries)
+// printed within each struct, slice, or map in the report. The remaining
+// records are summarized by an ellipsis with a count of the differences.
+// For slices of primitive values that are printed as rows of elements
+// (e.g., []byte or []int), the limit is approximate since adjacent rows
+// that differ are printed together.
+// It has no effect on whether the values are equal.
+func MaxReportDiffsPerContainer(n int) Option {
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid maximum number of differences: %d", n))
+	}
+	return &reportOption{fmt.Sprintf("MaxReportDiffsPerContainer(%d)", n), func(opts *formatOptions) {
+		opts.MaxContainerDiffs = n
+	}}
+}
+
+// ReportJSON returns an Option that annotates each differing leaf value
+// (e.g., a struct field, slice element, or map entry that is printed as
+// removed and inserted) with its JSON representation as produced by
+// json.Marshal. Values that cannot be represented in JSON (e.g., functions,
+// channels, or values of unexported fields) are not annotated.
+// This is useful for readers who are unfamiliar with Go syntax.
+func ReportJSON() Option {
+	return &reportOption{"ReportJSON()", func(opts *formatOptions) {
+		opts.JSONValues = true
+	}}
+}
+
+// ReportAppliedOptions returns an Option that annotates equal nodes in the
+// report with the Equal method, Comparer, or Transformer that determined
+// their equality. Nodes determined equal by such means are always printed,
+// rather than being summarized as identical. This is useful for verifying
+// that an option (e.g., one that equates values within some tolerance)
+// is actually in effect, rather than the values being coincidentally equal.
+//
+// Since Diff reports nothing for values that are equal,
+// the annotations only appear when some other difference exists.
+func ReportAppliedOptions() Option {
+	return &reportOption{"ReportAppliedOptions()", func(opts *formatOptions) {
+		opts.AppliedOptions = true
+	}}
+}
+
+// ReportMapSummary returns an Option that prints a summary before the entries
+// of each differing map, listing the number of keys only in x, only in y,
+// and with changed values, along with the first few keys in each category.
+// This makes large map differences easier to scan.
+func ReportMapSummary() Option {
+	return &reportOption{"ReportMapSummary()", func(opts *formatOptions) {
+		opts.MapSummary = true
+	}}
+}
+
+// ReportMapPresence returns an Option that annotates each map entry
+// that is only in x or only in y with an "only in x" or "only in y" comment,
+// which distinguishes missing and extra entries from modified values
+// at a glance. The same classification is available programmatically
+// from Difference.Kind.
+func ReportMapPresence() Option {
+	return &reportOption{"ReportMapPresence()", func(opts *formatOptions) {
+		opts.MapPresence = true
+	}}
+}
+
+// ReportMoves returns an Option that detects slice elements that were
+// relocated (i.e., removed from one index and inserted identically at another)
+// and annotates both the removal and the insertion with the index of the other,
+// such that a reordering is distinguishable from unrelated changes in content.
+// Elements are considered identical if they are formatted identically.
+func ReportMoves() Option {
+	return &reportOption{"ReportMoves()", func(opts *formatOptions) {
+		opts.DetectMoves = true
+	}}
+}
+
+// ReportRekeyedEntries returns an Option that detects map entries whose
+// value is only in x under one key and only in y under another key
+// (e.g., in maps keyed by generated identifiers) and annotates both
+// the removal and the insertion with the other key, such that a renamed key
+// is distinguishable from an unrelated removal and insertion.
+// Values are considered identical if they are formatted identically.
+func ReportRekeyedEntries() Option {
+	return &reportOption{"ReportRekeyedEntries()", func(opts *formatOptions) {
+		opts.DetectRekeys = true
+	}}
+}
+
+// ReportSetDifference returns an Option that prints a summary before the
+// elements of each differing slice whose order is insignificant, listing
+// the elements only in x, only in y, and in both but modified, since the
+// positional difference of sorted slices is often difficult to interpret.
+// A slice is considered unordered if it is the result of a Transformer
+// with one of the provided names, or with the name "cmpopts.SortSlices"
+// (i.e., the Transformer used by cmpopts.SortSlices) if none are provided.
+func ReportSetDifference(transformers ...string) Option {
+	if len(transformers) == 0 {
+		transformers = []string{"cmpopts.SortSlices"}
+	}
+	return &reportOption{"ReportSetDifference(" + strings.Join(transformers, ", ") + ")", func(opts *formatOptions) {
+		if opts.SetTransformers == nil {
+			opts.SetTransformers = make(map[string]bool)
+		}
+		for _, name := range transformers {
+			opts.SetTransformers[name] = true
+		}
+	}}
+}
+
+// ReportIndent returns an Option that indents each level of the report
+// with the provided string instead of a single tab (e.g., "  " for two spaces).
+// The indent must be non-empty and only contain spaces and tabs.
+// If the indent contains no tabs, then tabs within strings are escaped
+// such that the report contains no tabs at all, which preserves alignment
+// when the report is viewed by tools that expand tabs.
+func ReportIndent(indent string) Option {
+	if indent == "" || strings.Trim(indent, " \t") != "" {
+		panic(fmt.Sprintf("invalid indent: %q", indent))
+	}
+	return &reportOption{fmt.Sprintf("ReportIndent(%q)", indent), func(opts *formatOptions) {
+		opts.Indent = indent
+	}}
+}
+
+// ReportCompact returns an Option that prints the report on a single line
+// if it is no longer than maxLen bytes, which is suitable for inlining
+// into a test failure message. Otherwise, the report is printed across
+// multiple lines as usual. On a single line, removed and inserted records
+// are prefixed with "-" and "+", respectively, and most comments are omitted.
+func ReportCompact(maxLen int) Option {
+	if maxLen <= 0 {
+		panic(fmt.Sprintf("invalid maximum length: %d", maxLen))
+	}
+	return &reportOption{fmt.Sprintf("ReportCompact(%d)", maxLen), func(opts *formatOptions) {
+		opts.CompactLength = maxLen
+	}}
+}
+
+// ReportColor returns an Option that controls whether removed and inserted
+// lines of the report are printed in red and green using ANSI escape sequences,
+// which eases reading large reports in a terminal. Since the report may be
+// printed elsewhere (e.g., in a log file), color is not enabled automatically.
+// Instead, the caller decides based on the environment. For example:
+//
+//	cmp.ReportColor(os.Getenv("NO_COLOR") == "")
+//
+// Reports printed on a single line (see ReportCompact) are never colored.
+func ReportColor(enabled bool) Option {
+	return &reportOption{fmt.Sprintf("ReportColor(%v)", enabled), func(opts *formatOptions) {
+		opts.Color = enabled
+	}}
+}
+
+// ReportCollapsePointers returns an Option that prints a chain of pointers
+// (e.g., ***T) using a single prefix annotated with the number of pointers,
+// such as "&³T{...}" rather than "&&&T{...}". Pointers whose addresses are
+// printed (see ReportAddresses) are not collapsed.
+func ReportCollapsePointers() Option {
+	return &reportOption{"ReportCollapsePointers()", func(opts *formatOptions) {
+		opts.CollapsePointers = true
+	}}
+}
+
+// ReportIndentJSON returns an Option that re-indents differing strings and
+// byte slices with one JSON value per line before diffing them line by line,
+// if both are JSON objects or arrays. This makes the changed keys visible
+// within compact JSON documents, which otherwise differ as a single line.
+// Only the report is affected; the values are still compared as is.
+func ReportIndentJSON() Option {
+	return &reportOption{"ReportIndentJSON()", func(opts *formatOptions) {
+		opts.IndentJSON = true
+	}}
+}
+
+// ReportSummary returns an Option that appends a line summarizing the
+// comparison to the report, which includes the number of unequal,
+// ignored, and transformed values, and the path to the deepest difference
+// (see Summary). The summary is also available from DiffResult.Summary.
+func ReportSummary() Option {
+	return &reportOption{"ReportSummary()", func(opts *formatOptions) {
+		opts.Summary = true
+	}}
+}
+
+// ReportDeterministic returns an Option that produces a report that is
+// byte-for-byte identical for the same inputs across different runs of the
+// same program (e.g., to deduplicate reports of the same failing test).
+// By default, the exact output varies between runs to discourage reliance
+// on the report format, which is not stable across versions of this package.
+// This option also implies ReportPointerLabels, since addresses vary between
+// runs, including within map keys. Differences are always reported in the
+// order that values are traversed, where map entries are visited in the order
+// of their sorted keys. Keys that would be sorted by address (e.g., pointers)
+// are instead ordered by their formatted value.
+func ReportDeterministic() Option {
+	return &reportOption{"ReportDeterministic()", func(opts *formatOptions) {
+		opts.Deterministic = true
+		opts.PointerLabels = true
+	}}
+}
+
+// ReportCoalesceHunks returns an Option that prints each group of adjacent
+// differing fields, elements, or entries as a single hunk, where all of the
+// removed lines precede all of the inserted lines, rather than interleaving
+// the removed and inserted lines of each field, element, or entry.
+// Differences within nested values still break up the hunk.
+func ReportCoalesceHunks() Option {
+	return &reportOption{"ReportCoalesceHunks()", func(opts *formatOptions) {
+		opts.CoalesceHunks = true
+	}}
+}
+
+// ReportFullContext returns an Option that prints the compared values in
+// their entirety, including all equal fields, elements, and entries,
+// struct fields with zero values, and strings without truncation.
+// Only the differences are still marked, so that the report shows
+// where each difference lies without needing to print the values separately.
+func ReportFullContext() Option {
+	return &reportOption{"ReportFullContext()", func(opts *formatOptions) {
+		opts.FullContext = true
+	}}
+}
+
+// ReportContext returns an Option that prints n equal elements, fields,
+// or entries before and after each group of differences within a slice,
+// struct, or map, where the default is 2. For strings and slices of
+// primitive kinds that are printed in chunks (e.g., lines of text),
+// n is the number of such chunks. The remaining equal records are elided.
+func ReportContext(n int) Option {
+	if n < 0 {
+		panic(fmt.Sprintf("invalid number of context records: %d", n))
+	}
+	return &reportOption{fmt.Sprintf("ReportContext(%d)", n), func(opts *formatOptions) {
+		opts.HasContextRecords = true
+		opts.ContextRecords = n
+	}}
+}
+
+// ReportUnified returns an Option that prints the report in the unified diff
+// format understood by patch viewing tools, where each hunk of removed and
+// inserted lines is preceded by a "@@ -l,s +l,s @@" header and surrounded by
+// at most context unchanged lines of the report. The line numbers refer to
+// the lines of the report as if only the x or only the y values were printed.
+// The report is never printed on a single line (see ReportCompact).
+func ReportUnified(context int) Option {
+	if context < 0 {
+		panic(fmt.Sprintf("invalid number of context lines: %d", context))
+	}
+	return &reportOption{fmt.Sprintf("ReportUnified(%d)", context), func(opts *formatOptions) {
+		opts.Unified = true
+		opts.UnifiedContext = context
+	}}
+}
+
+// ReportGroupByType returns an Option that prints the report as a list of
+// the differing leaf values grouped by their concrete type (e.g., all
+// time.Time differences together), with the number of differences in each
+// group, instead of as a literal in pseudo-Go syntax.
+// Each difference is printed on a "-" line with the value in x and a "+" line
+// with the value in y, prefixed by the path formatted as by Path.GoString.
+// For structs with many fields, this reveals systematic problems
+// (e.g., every timestamp being off by an hour) at a glance.
+func ReportGroupByType() Option {
+	return &reportOption{"ReportGroupByType()", func(opts *formatOptions) {
+		opts.GroupByType = true
+	}}
+}
+
+// ReportTableOfContents returns an Option that prints a table of contents
+// before the report if the compared values are structs (or pointers to
+// structs), listing each top-level field with differences along with the
+// number of differences within it. This helps readers of a large report
+// find the section that they care about.
+func ReportTableOfContents() Option {
+	return &reportOption{"ReportTableOfContents()", func(opts *formatOptions) {
+		opts.TableOfContents = true
+	}}
+}
+
+// ReportTransformer returns an Option that rewrites values of a certain type
+// for display purposes only. Unlike Transformer, it has no effect on
+// whether values are equal, but only on how they are printed in the report.
+// This is useful for summarizing or shortening values that are verbose
+// (e.g., truncating long identifiers), or for hiding sensitive values.
+//
+// The transformer f must be a function "func(T) R" that converts values of
+// type T to those of type R, and is only applied to values assignable to T.
+// The output of f is formatted as if it were the original value, except that
+// f is not applied again to its own output. If multiple report transformers
+// apply to a value, then only the first one provided is used.
+//
+// If the transformed values of a difference are formatted identically,
+// then the original values are printed instead so that the report
+// never hides the existence of a difference.
+func ReportTransformer(f interface{}) Option {
+	v := reflect.ValueOf(f)
+	if !function.IsType(v.Type(), function.Transformer) || v.IsNil() {
+		panic(fmt.Sprintf("invalid transformer function: %T", f))
+	}
+	tr := &reportTransformer{typ: v.Type().In(0), fnc: v}
+	return &reportOption{fmt.Sprintf("ReportTransformer(%s)", function.NameOf(v)), func(opts *formatOptions) {
+		opts.Transformers = append(opts.Transformers[:len(opts.Transformers):len(opts.Transformers)], tr)
+	}}
+}
+
+// ReportFormatter returns an Option that prints values of a certain type
+// using a custom formatting function. Like ReportTransformer, it has no effect
+// on whether values are equal, but only on how they are printed in the report.
+// This is useful for presenting opaque types in a familiar form
+// (e.g., a UUID as "123e4567-e89b-12d3-a456-426614174000").
+//
+// The formatter f must be a function "func(T) string" and is only applied
+// to values assignable to T. Its output is printed verbatim in place of
+// the value, and should be a single line. Report transformers and formatters
+// are considered in the order they are provided, where only the first
+// applicable one is used.
+//
+// If the formatted values of a difference are identical, then the original
+// values are printed instead so that the report never hides the existence
+// of a difference.
+func ReportFormatter(f interface{}) Option {
+	v := reflect.ValueOf(f)
+	if !function.IsType(v.Type(), function.Transformer) || v.IsNil() || v.Type().Out(0) != reflect.TypeOf("") {
+		panic(fmt.Sprintf("invalid formatter function: %T", f))
+	}
+	tr := &reportTransformer{typ: v.Type().In(0), fnc: v, format: true}
+	return &reportOption{fmt.Sprintf("ReportFormatter(%s)", function.NameOf(v)), func(opts *formatOptions) {
+		opts.Transformers = append(opts.Transformers[:len(opts.Transformers):len(opts.Transformers)], tr)
+	}}
+}
+
+type reportTransformer struct {
+	typ    reflect.Type  // T
+	fnc    reflect.Value // func(T) R
+	format bool          // Whether the output of fnc is printed verbatim
+}
+
+// transformer returns the first report transformer applicable to type t.
+func (opts formatValueOptions) transformer(t reflect.Type) *reportTransformer {
+	for _, tr := range opts.Transformers {
+		if t.AssignableTo(tr.typ) {
+			return tr
+		}
+	}
+	return nil
+}
+
+// transformValue applies the first applicable report transformer to v.
+// It returns the transformed value and the options to format it with,
+// which exclude the applied transformer.
+func (opts formatOptions) transformValue(v reflect.Value) (formatOptions, reflect.Value, bool) {
+	tr := opts.transformer(v.Type())
+	if tr == nil || !v.CanInterface() {
+		return opts, v, false
+	}
+	var trs []*reportTransformer
+	for _, tr2 := range opts.Transformers {
+		if tr2 != tr {
+			trs = append(trs, tr2)
+		}
+	}
+	opts.Transformers = trs
+	return opts, tr.fnc.Call([]reflect.Value{sanitizeValue(v, tr.typ)})[0], true
+}
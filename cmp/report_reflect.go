@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -16,6 +17,8 @@ import (
 	"github.com/google/go-cmp/cmp/internal/value"
 )
 
+var timeType = reflect.TypeOf(time.Time{})
+
 type formatValueOptions struct {
 	// AvoidStringer controls whether to avoid calling custom stringer
 	// methods like error.Error or fmt.Stringer.String.
@@ -30,10 +33,48 @@ type formatValueOptions struct {
 	// slice elements, and maps.
 	PrintAddresses bool
 
+	// PointerLabels controls whether to print pointers using labels that are
+	// unique within the report (e.g., "⟪p#1⟫") instead of their addresses.
+	PointerLabels bool
+
+	// labels maps each pointer printed so far to its label number.
+	// It is shared by all copies of the options used to format a report.
+	labels map[value.Pointer]int
+
+	// TimeLayout is the layout used to format time.Time values,
+	// where an empty string means time.RFC3339Nano.
+	TimeLayout string
+
+	// IntegerBases maps integer types to the base used to format them,
+	// where the entry for a nil type applies to all other integer types.
+	IntegerBases map[reflect.Type]int
+
+	// EnumNames maps integer types to a map of names for their values,
+	// where the values of each map have the type of the key.
+	EnumNames map[reflect.Type]reflect.Value
+
+	// FloatFormat and FloatPrecision are the format and precision passed to
+	// strconv.FormatFloat when formatting floating-point values,
+	// where a zero FloatFormat means the formatting of fmt.Sprint.
+	FloatFormat    byte
+	FloatPrecision int
+
+	// EmitTypes controls whether FormatType always emits the type,
+	// regardless of the TypeMode.
+	EmitTypes bool
+
 	// QualifiedNames controls whether FormatType uses the fully qualified name
 	// (including the full package path as opposed to just the package name).
 	QualifiedNames bool
 
+	// TypeNames is a list of functions that rename named types
+	// (see ReportTypeNames), which are consulted in order.
+	TypeNames []func(reflect.Type) string
+
+	// ElideTypeArgs controls whether FormatType elides the type arguments
+	// of instantiated generic types (see ReportElideTypeArguments).
+	ElideTypeArgs bool
+
 	// VerbosityLevel controls the amount of output to produce.
 	// A higher value produces more output. A value of zero or lower produces
 	// no output (represented using an ellipsis).
@@ -42,14 +83,19 @@ type formatValueOptions struct {
 
 	// LimitVerbosity specifies that formatting should respect VerbosityLevel.
 	LimitVerbosity bool
+
+	// Transformers is a list of display-only transformations applied to
+	// values prior to formatting them. See ReportTransformer.
+	Transformers []*reportTransformer
 }
 
 // FormatType prints the type as if it were wrapping s.
 // This may return s as-is depending on the current type and TypeMode mode.
 func (opts formatOptions) FormatType(t reflect.Type, s textNode) textNode {
 	// Check whether to emit the type or not.
-	switch opts.TypeMode {
-	case autoType:
+	switch {
+	case opts.EmitTypes:
+	case opts.TypeMode == autoType:
 		switch t.Kind() {
 		case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
 			if s.Equal(textNil) {
@@ -61,12 +107,12 @@ func (opts formatOptions) FormatType(t reflect.Type, s textNode) textNode {
 		if opts.DiffMode == diffIdentical {
 			return s // elide type for identical nodes
 		}
-	case elideType:
+	case opts.TypeMode == elideType:
 		return s
 	}
 
 	// Determine the type label, applying special handling for unnamed types.
-	typeName := value.TypeString(t, opts.QualifiedNames)
+	typeName := opts.formatTypeName(t)
 	if t.Name() == "" {
 		// According to Go grammar, certain type literals contain symbols that
 		// do not strongly bind to the next lexicographical token (e.g., *T).
@@ -87,14 +133,62 @@ func (opts formatOptions) FormatType(t reflect.Type, s textNode) textNode {
 	return textWrap{typeName + "(", s, ")"}
 }
 
+// formatTypeName formats the name of t, applying any user-provided names
+// for named types and eliding type arguments if requested.
+func (opts formatOptions) formatTypeName(t reflect.Type) string {
+	if len(opts.TypeNames) == 0 && !opts.ElideTypeArgs {
+		return value.TypeString(t, opts.QualifiedNames)
+	}
+	return value.TypeStringFunc(t, opts.QualifiedNames, func(t reflect.Type) string {
+		for _, f := range opts.TypeNames {
+			if name := f(t); name != "" {
+				return name
+			}
+		}
+		if opts.ElideTypeArgs && strings.Contains(t.Name(), "[") {
+			return elideTypeArgs(value.TypeString(t, opts.QualifiedNames))
+		}
+		return ""
+	})
+}
+
+// elideTypeArgs replaces the type arguments of a generic type name
+// with an ellipsis (e.g., "pkg.Map[string,pkg.Value[int]]" as "pkg.Map[...]").
+func elideTypeArgs(name string) string {
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		return name[:i] + "[...]"
+	}
+	return name
+}
+
 // FormatValue prints the reflect.Value, taking extra care to avoid descending
 // into pointers already in m. As pointers are visited, m is also updated.
 func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visitedPointers) (out textNode) {
 	if !v.IsValid() {
 		return nil
 	}
+	if opts.valuePath != nil && opts.matchesRedactedPath(opts.valuePath) {
+		return textRedacted
+	}
+	if tr := opts.transformer(v.Type()); tr != nil && tr.format && v.CanInterface() {
+		s := tr.fnc.Call([]reflect.Value{sanitizeValue(v, tr.typ)})[0].String()
+		return opts.FormatType(v.Type(), textLine(s))
+	}
+	if opts2, vt, ok := opts.transformValue(v); ok {
+		return opts2.FormatValue(vt, withinSlice, m)
+	}
 	t := v.Type()
 
+	// Check whether to use the built-in formatting for time.Time.
+	if t == timeType && !opts.AvoidStringer && v.CanInterface() {
+		layout := opts.TimeLayout
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		out := textNode(textLine(v.Interface().(time.Time).Format(layout)))
+		return opts.FormatType(t, out)
+	}
+
 	// Check whether there is an Error or String method to call.
 	if !opts.AvoidStringer && v.CanInterface() {
 		// Avoid calling Error or String methods on nil receivers since many
@@ -108,14 +202,11 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 				prefix, strVal = "s", v.String()
 			}
 			if prefix != "" {
-				maxLen := len(strVal)
-				if opts.LimitVerbosity {
-					maxLen = (1 << opts.verbosity()) << 5 // 32, 64, 128, 256, etc...
-				}
+				maxLen := opts.maxStringLen(len(strVal))
 				if len(strVal) > maxLen+len(textEllipsis) {
-					return textLine(prefix + formatString(strVal[:maxLen]) + string(textEllipsis))
+					return textLine(prefix + opts.formatString(strVal[:maxLen]) + string(textEllipsis))
 				}
-				return textLine(prefix + formatString(strVal))
+				return textLine(prefix + opts.formatString(strVal))
 			}
 		}
 	}
@@ -128,6 +219,19 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 		}
 	}()
 
+	if names, ok := opts.EnumNames[t]; ok {
+		if name := names.MapIndex(v); name.IsValid() {
+			base := opts.integerBase(t)
+			if base == 0 {
+				base = 10
+			}
+			return textLine(fmt.Sprintf("%s (%s)", name.String(), formatBase(v, base)))
+		}
+	}
+	if base := opts.integerBase(t); base != 0 {
+		return textLine(formatBase(v, base))
+	}
+
 	var ptr string
 	switch t.Kind() {
 	case reflect.Bool:
@@ -135,7 +239,7 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return textLine(fmt.Sprint(v.Int()))
 	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return textLine(fmt.Sprint(v.Uint()))
+		return textLine(formatUint(v.Uint(), t.Bits()))
 	case reflect.Uint8:
 		if withinSlice {
 			return textLine(formatHex(v.Uint()))
@@ -144,20 +248,20 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 	case reflect.Uintptr:
 		return textLine(formatHex(v.Uint()))
 	case reflect.Float32, reflect.Float64:
+		if opts.FloatFormat != 0 {
+			return textLine(strconv.FormatFloat(v.Float(), opts.FloatFormat, opts.FloatPrecision, t.Bits()))
+		}
 		return textLine(fmt.Sprint(v.Float()))
 	case reflect.Complex64, reflect.Complex128:
 		return textLine(fmt.Sprint(v.Complex()))
 	case reflect.String:
-		maxLen := v.Len()
-		if opts.LimitVerbosity {
-			maxLen = (1 << opts.verbosity()) << 5 // 32, 64, 128, 256, etc...
-		}
+		maxLen := opts.maxStringLen(v.Len())
 		if v.Len() > maxLen+len(textEllipsis) {
-			return textLine(formatString(v.String()[:maxLen]) + string(textEllipsis))
+			return textLine(opts.formatString(v.String()[:maxLen]) + string(textEllipsis))
 		}
-		return textLine(formatString(v.String()))
+		return textLine(opts.formatString(v.String()))
 	case reflect.UnsafePointer, reflect.Chan, reflect.Func:
-		return textLine(formatPointer(v))
+		return textLine(opts.formatPointer(v))
 	case reflect.Struct:
 		var list textList
 		v := makeAddressable(v) // needed for retrieveUnexportedField
@@ -168,7 +272,7 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 		}
 		for i := 0; i < v.NumField(); i++ {
 			vv := v.Field(i)
-			if value.IsZero(vv) {
+			if value.IsZero(vv) && !opts.FullContext {
 				continue // Elide fields with zero values
 			}
 			if len(list) == maxLen {
@@ -176,10 +280,14 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 				break
 			}
 			sf := t.Field(i)
+			if opts.isRedacted(t, sf.Name) {
+				list = append(list, textRecord{Key: sf.Name, Value: textRedacted})
+				continue
+			}
 			if supportExporters && !isExported(sf.Name) {
 				vv = retrieveUnexportedField(v, sf, true)
 			}
-			s := opts.WithTypeMode(autoType).FormatValue(vv, false, m)
+			s := opts.withStep(func() PathStep { return fieldStep(v, i) }).WithTypeMode(autoType).FormatValue(vv, false, m)
 			list = append(list, textRecord{Key: sf.Name, Value: s})
 		}
 		return textWrap{"{", list, "}"}
@@ -188,7 +296,7 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 			return textNil
 		}
 		if opts.PrintAddresses {
-			ptr = fmt.Sprintf("⟪ptr:0x%x, len:%d, cap:%d⟫", pointerValue(v), v.Len(), v.Cap())
+			ptr = opts.formatHeader(v)
 		}
 		fallthrough
 	case reflect.Array:
@@ -208,14 +316,14 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 				p := vi.Addr()
 				if m.Visit(p) {
 					var out textNode
-					out = textLine(formatPointer(p))
+					out = textLine(opts.formatPointer(p))
 					out = opts.WithTypeMode(emitType).FormatType(p.Type(), out)
 					out = textWrap{"*", out, ""}
 					list = append(list, textRecord{Value: out})
 					continue
 				}
 			}
-			s := opts.WithTypeMode(elideType).FormatValue(vi, true, m)
+			s := opts.withStep(func() PathStep { return indexStep(v, i) }).WithTypeMode(elideType).FormatValue(vi, true, m)
 			list = append(list, textRecord{Value: s})
 		}
 		return textWrap{ptr + "{", list, "}"}
@@ -224,7 +332,7 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 			return textNil
 		}
 		if m.Visit(v) {
-			return textLine(formatPointer(v))
+			return textLine(opts.formatPointer(v))
 		}
 
 		maxLen := v.Len()
@@ -238,12 +346,12 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 				list.AppendEllipsis(diffStats{})
 				break
 			}
-			sk := formatMapKey(k, false)
-			sv := opts.WithTypeMode(elideType).FormatValue(v.MapIndex(k), false, m)
+			sk := opts.formatMapKey(k, false)
+			sv := opts.withStep(func() PathStep { return entryStep(v, k) }).WithTypeMode(elideType).FormatValue(v.MapIndex(k), false, m)
 			list = append(list, textRecord{Key: sk, Value: sv})
 		}
 		if opts.PrintAddresses {
-			ptr = formatPointer(v)
+			ptr = opts.formatPointer(v)
 		}
 		return textWrap{ptr + "{", list, "}"}
 	case reflect.Ptr:
@@ -251,14 +359,14 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 			return textNil
 		}
 		if m.Visit(v) {
-			return textLine(formatPointer(v))
+			return textLine(opts.formatPointer(v))
 		}
 		if opts.PrintAddresses || opts.PrintShallowPointer {
-			ptr = formatPointer(v)
+			ptr = opts.formatPointer(v)
 			opts.PrintShallowPointer = false
 		}
 		skipType = true // Let the underlying value print the type instead
-		return textWrap{"&" + ptr, opts.FormatValue(v.Elem(), false, m), ""}
+		return opts.formatPointerChain(ptr, opts.withStep(func() PathStep { return indirectStep(v) }).FormatValue(v.Elem(), false, m))
 	case reflect.Interface:
 		if v.IsNil() {
 			return textNil
@@ -266,16 +374,75 @@ func (opts formatOptions) FormatValue(v reflect.Value, withinSlice bool, m visit
 		// Interfaces accept different concrete types,
 		// so configure the underlying value to explicitly print the type.
 		skipType = true // Print the concrete type instead
-		return opts.WithTypeMode(emitType).FormatValue(v.Elem(), false, m)
+		return opts.withStep(func() PathStep { return assertStep(v) }).WithTypeMode(emitType).FormatValue(v.Elem(), false, m)
 	default:
 		panic(fmt.Sprintf("%v kind not handled", v.Kind()))
 	}
 }
 
+// formatPointerChain prefixes the formatted pointee s with "&" and the
+// pointer header hdr. If CollapsePointers is set, then a chain of pointers
+// without headers is collapsed into a single prefix with the number of
+// pointers as a superscript (e.g., "&³T{...}" rather than "&&&T{...}").
+func (opts formatOptions) formatPointerChain(hdr string, s textNode) textNode {
+	if opts.CollapsePointers && hdr == "" {
+		if w, ok := s.(textWrap); ok && w.Suffix == "" {
+			if n := pointerChainLen(w.Prefix); n > 0 {
+				return textWrap{"&" + superscript(n+1), w.Value, ""}
+			}
+		}
+	}
+	return textWrap{"&" + hdr, s, ""}
+}
+
+const superscriptDigits = "⁰¹²³⁴⁵⁶⁷⁸⁹"
+
+// pointerChainLen reports the number of pointers represented by a prefix
+// produced by formatPointerChain, or zero if it is not such a prefix.
+func pointerChainLen(prefix string) int {
+	if prefix == "&" {
+		return 1
+	}
+	if !strings.HasPrefix(prefix, "&") || len(prefix) == 1 {
+		return 0
+	}
+	var n int
+	for _, r := range prefix[1:] {
+		i := strings.IndexRune(superscriptDigits, r)
+		if i < 0 {
+			return 0
+		}
+		n = 10*n + utf8.RuneCountInString(superscriptDigits[:i])
+	}
+	return n
+}
+
+// superscript formats n using superscript digits.
+func superscript(n int) string {
+	digits := []rune(superscriptDigits)
+	var b []rune
+	for _, c := range strconv.Itoa(n) {
+		b = append(b, digits[c-'0'])
+	}
+	return string(b)
+}
+
+// formatValueLine formats v as a single line in its entirety.
+func (opts formatOptions) formatValueLine(v reflect.Value) string {
+	opts.DiffMode = diffIdentical
+	opts.TypeMode = elideType
+	opts.LimitVerbosity = false
+	b, _ := opts.FormatValue(v, false, visitedPointers{}).formatCompactTo(nil, diffIdentical, 0)
+	return string(b)
+}
+
 // formatMapKey formats v as if it were a map key.
 // The result is guaranteed to be a single line.
-func formatMapKey(v reflect.Value, disambiguate bool) string {
-	var opts formatOptions
+// Only the QuoteMode and pointer labels of the receiver are respected.
+func (opts formatOptions) formatMapKey(v reflect.Value, disambiguate bool) string {
+	labels, ptrLabels := opts.labels, opts.PointerLabels
+	opts = formatOptions{QuoteMode: opts.QuoteMode}
+	opts.PointerLabels, opts.labels = ptrLabels, labels
 	opts.DiffMode = diffIdentical
 	opts.TypeMode = elideType
 	opts.PrintShallowPointer = true
@@ -285,19 +452,39 @@ func formatMapKey(v reflect.Value, disambiguate bool) string {
 	return strings.TrimSpace(s)
 }
 
+// maxStringLen reports the length that a string of length n
+// is truncated to when printed.
+func (opts formatOptions) maxStringLen(n int) int {
+	switch {
+	case !opts.LimitVerbosity || opts.MaxStringLen < 0:
+		return n
+	case opts.MaxStringLen > 0:
+		return opts.MaxStringLen
+	default:
+		return (1 << opts.verbosity()) << 5 // 32, 64, 128, 256, etc...
+	}
+}
+
 // formatString prints s as a double-quoted or backtick-quoted string.
-func formatString(s string) string {
+func (opts formatOptions) formatString(s string) string {
+	if opts.EscapeUnicode {
+		return strconv.QuoteToASCII(s)
+	}
+
 	// Use quoted string if it the same length as a raw string literal.
 	// Otherwise, attempt to use the raw string form.
 	qs := strconv.Quote(s)
-	if len(qs) == 1+len(s)+1 {
+	if opts.QuoteMode == doubleQuote || len(qs) == 1+len(s)+1 && opts.QuoteMode != rawQuote {
 		return qs
 	}
 
 	// Disallow newlines to ensure output is a single line.
 	// Only allow printable runes for readability purposes.
 	rawInvalid := func(r rune) bool {
-		return r == '`' || r == '\n' || !(unicode.IsPrint(r) || r == '\t')
+		return r == '`' || r == '\n' || !(unicode.IsPrint(r) || r == '\t' && opts.rawTabs())
+	}
+	if opts.ShowWhitespace && hasTrailingSpace(s) {
+		return qs // quotes delimit the trailing whitespace
 	}
 	if utf8.ValidString(s) && strings.IndexFunc(s, rawInvalid) < 0 {
 		return "`" + s + "`"
@@ -305,6 +492,118 @@ func formatString(s string) string {
 	return qs
 }
 
+// confusables maps runes that are visually indistinguishable from (or easily
+// mistaken for) an ASCII character to that character.
+var confusables = map[rune]rune{
+	// Spaces.
+	'\u00a0': ' ', '\u2000': ' ', '\u2001': ' ', '\u2002': ' ', '\u2003': ' ',
+	'\u2004': ' ', '\u2005': ' ', '\u2006': ' ', '\u2007': ' ', '\u2008': ' ',
+	'\u2009': ' ', '\u200a': ' ', '\u202f': ' ', '\u205f': ' ', '\u3000': ' ',
+	// Dashes and quotes.
+	'\u2010': '-', '\u2011': '-', '\u2012': '-', '\u2013': '-', '\u2212': '-',
+	'\u2018': '\'', '\u2019': '\'', '\u201c': '"', '\u201d': '"',
+	// Cyrillic letters.
+	'а': 'a', 'с': 'c', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j', 'о': 'o', 'р': 'p',
+	'ѕ': 's', 'у': 'y', 'х': 'x', 'А': 'A', 'В': 'B', 'С': 'C', 'Е': 'E', 'Н': 'H',
+	'І': 'I', 'Ј': 'J', 'К': 'K', 'М': 'M', 'О': 'O', 'Р': 'P', 'Ѕ': 'S', 'Т': 'T',
+	'Х': 'X', 'Ү': 'Y',
+	// Greek letters.
+	'ο': 'o', 'ν': 'v', 'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I',
+	'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+}
+
+// isConfusable reports whether the strings x and y differ, but are likely
+// to look identical when printed since they only differ by invisible runes
+// (e.g., zero-width joiners) or by runes that look alike
+// (e.g., a Cyrillic "а" versus a Latin "a").
+func isConfusable(x, y string) bool {
+	skeleton := func(r rune) rune {
+		if c, ok := confusables[r]; ok {
+			return c
+		}
+		if unicode.Is(unicode.Cf, r) {
+			return -1 // drop invisible formatting characters
+		}
+		return r
+	}
+	return x != y && strings.Map(skeleton, x) == strings.Map(skeleton, y)
+}
+
+// rawTabs reports whether tabs within strings may be printed verbatim,
+// which is avoided if the report is not indented with tabs (or is escaped
+// for logs) so that the report contains no tabs at all,
+// or if whitespace must be distinguishable.
+func (opts formatOptions) rawTabs() bool {
+	return !opts.LogSafe && !opts.ShowWhitespace && (opts.Indent == "" || strings.Contains(opts.Indent, "\t"))
+}
+
+// hasTrailingSpace reports whether s ends with a whitespace character.
+func hasTrailingSpace(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return s != "" && unicode.IsSpace(r)
+}
+
+// formatUint prints u as a decimal integer. Values that are close to the
+// maximum value of an unsigned integer of the given bit-size are likely
+// negative numbers that were incorrectly converted, in which case the
+// equivalent signed value is provided as a hint.
+func formatUint(u uint64, bits int) string {
+	const maxHint = 1 << 16
+	s := fmt.Sprint(u)
+	if bits >= 32 {
+		n := int64(u<<uint(64-bits)) >> uint(64-bits) // sign-extend
+		if -maxHint <= n && n < 0 {
+			s += fmt.Sprintf(" /* int%d(%d)? */", bits, n)
+		}
+	}
+	return s
+}
+
+// integerBase reports the base used to format values of type t,
+// where zero means the default formatting or that t is not an integer.
+func (opts formatOptions) integerBase(t reflect.Type) int {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		return 0
+	}
+	if base, ok := opts.IntegerBases[t]; ok {
+		return base
+	}
+	return opts.IntegerBases[nil]
+}
+
+// formatBase prints the integer v in the given base using Go notation,
+// where binary values are padded to a whole number of bytes.
+func formatBase(v reflect.Value, base int) string {
+	var u uint64
+	var sign string
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		if u = uint64(n); n < 0 {
+			sign, u = "-", uint64(-n)
+		}
+	default:
+		u = v.Uint()
+	}
+	switch base {
+	case 2:
+		s := strconv.FormatUint(u, 2)
+		if n := len(s) % 8; n > 0 {
+			s = strings.Repeat("0", 8-n) + s
+		}
+		return sign + "0b" + s
+	case 8:
+		return sign + "0o" + strconv.FormatUint(u, 8)
+	case 16:
+		return sign + formatHex(u)
+	default:
+		return sign + strconv.FormatUint(u, 10)
+	}
+}
+
 // formatHex prints u as a hexadecimal integer in Go notation.
 func formatHex(u uint64) string {
 	var f string
@@ -329,9 +628,46 @@ func formatHex(u uint64) string {
 	return fmt.Sprintf(f, u)
 }
 
+// formatHeader prints the address of a non-nil pointer or map, or the
+// address, length, and capacity of a non-nil slice.
+// It returns an empty string for any other value.
+func (opts formatOptions) formatHeader(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if !v.IsNil() {
+			return opts.formatPointer(v)
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			return fmt.Sprintf("⟪ptr:%s, len:%d, cap:%d⟫", opts.formatAddress(v), v.Len(), v.Cap())
+		}
+	}
+	return ""
+}
+
 // formatPointer prints the address of the pointer.
-func formatPointer(v reflect.Value) string {
-	return fmt.Sprintf("⟪0x%x⟫", pointerValue(v))
+func (opts formatOptions) formatPointer(v reflect.Value) string {
+	return "⟪" + opts.formatAddress(v) + "⟫"
+}
+
+// formatAddress prints the address of the pointer, or its label if
+// PointerLabels is set. Labels are numbered in the order that pointers
+// are first printed, such that the same pointer has the same label
+// at every occurrence within the report.
+func (opts formatOptions) formatAddress(v reflect.Value) string {
+	if !opts.PointerLabels || opts.labels == nil {
+		return fmt.Sprintf("0x%x", pointerValue(v))
+	}
+	p := value.PointerOf(v)
+	n, ok := opts.labels[p]
+	if !ok {
+		n = len(opts.labels) + 1
+		opts.labels[p] = n
+	}
+	return fmt.Sprintf("p#%d", n)
 }
 func pointerValue(v reflect.Value) uintptr {
 	p := v.Pointer()
@@ -0,0 +1,424 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
+// DiffResult is the result of comparing two values, providing both a
+// humanly-readable report and a structured list of the differences.
+type DiffResult struct {
+	// Report is the humanly-readable report of the differences.
+	// It is identical to the output of Diff for the same inputs.
+	Report string
+
+	// Differences is the list of unequal leaf nodes in the order
+	// that they were encountered while traversing the value trees.
+	Differences []Difference
+
+	root *valueNode    // The report tree; nil if not available
+	opts formatOptions // The options used to format the report
+
+	numIgnored, numTransformed int // See Summary
+}
+
+// Equal reports whether the compared values are equal.
+func (r DiffResult) Equal() bool {
+	return len(r.Differences) == 0
+}
+
+// Summary summarizes the result of a comparison.
+type Summary struct {
+	// NumDiff is the number of unequal leaf values.
+	NumDiff int
+	// NumIgnored is the number of leaf values that were ignored.
+	NumIgnored int
+	// NumTransformed is the number of values that were transformed.
+	NumTransformed int
+	// DeepestDiff is the path to the first unequal leaf value with the
+	// greatest depth, or nil if the compared values are equal.
+	DeepestDiff Path
+}
+
+// String returns a single line describing the summary
+// (e.g., "3 differences, 1 ignored, 0 transformed, deepest at {T}.A[2]").
+func (s Summary) String() string {
+	str := fmt.Sprintf("%d %s, %d ignored, %d transformed",
+		s.NumDiff, pluralize("difference", s.NumDiff), s.NumIgnored, s.NumTransformed)
+	if len(s.DeepestDiff) > 0 {
+		str += ", deepest at " + s.DeepestDiff.GoString()
+	}
+	return str
+}
+
+// Summary returns a summary of the comparison.
+// The number of ignored and transformed values are only available
+// for a result returned by Compare.
+func (r DiffResult) Summary() Summary {
+	s := Summary{NumIgnored: r.numIgnored, NumTransformed: r.numTransformed}
+	for _, d := range r.Differences {
+		s.NumDiff++
+		if len(d.Path) > len(s.DeepestDiff) {
+			s.DeepestDiff = d.Path
+		}
+	}
+	return s
+}
+
+// summarize summarizes the comparison represented by the tree rooted at v.
+func summarize(v *valueNode) Summary {
+	s := Summary{NumDiff: v.NumDiff, NumIgnored: v.NumIgnored, NumTransformed: v.NumTransformed}
+	if d := v.DeepestDiff(); d != nil {
+		s.DeepestDiff = d.Path()
+	}
+	return s
+}
+
+// Severity returns the most severe classification of all differences
+// (see ClassifyDifferences), or NoSeverity if the values are equal.
+func (r DiffResult) Severity() Severity {
+	var sev Severity
+	for _, d := range r.Differences {
+		if d.Severity > sev {
+			sev = d.Severity
+		}
+	}
+	return sev
+}
+
+// OnlyInformational reports whether every difference is Informational.
+// It reports true if the values are equal.
+func (r DiffResult) OnlyInformational() bool {
+	return r.Severity() <= Informational
+}
+
+// HasBlocking reports whether any difference is Blocking.
+func (r DiffResult) HasBlocking() bool {
+	return r.Severity() == Blocking
+}
+
+// Fingerprint returns a hash of the differences, which is intended for
+// grouping identical failures across runs and machines
+// (e.g., by tooling that detects flaky tests).
+// The hash depends only on the path, type, and values of each difference.
+// It is independent of the addresses of pointers and of any options that
+// control the formatting of the report (e.g., Verbosity),
+// but may change between releases of this package.
+// It returns the empty string if the compared values are equal.
+func (r DiffResult) Fingerprint() string {
+	if r.Equal() {
+		return ""
+	}
+	opts := formatOptions{formatValueOptions: formatValueOptions{
+		PointerLabels: true,
+		labels:        make(map[value.Pointer]int),
+	}}
+	h := sha256.New()
+	for _, d := range r.Differences {
+		io.WriteString(h, d.Path.GoString()+"\x00")
+		io.WriteString(h, d.Path.Last().Type().String()+"\x00")
+		redacted := r.opts.isRedactedPath(d.Path)
+		for _, v := range []reflect.Value{d.X, d.Y} {
+			switch {
+			case !v.IsValid():
+				io.WriteString(h, "\x01")
+			case redacted:
+				io.WriteString(h, string(textRedacted))
+			default:
+				io.WriteString(h, opts.formatValueLine(v))
+			}
+			io.WriteString(h, "\x00")
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Invert returns the result as if x and y were swapped when compared.
+// The report is formatted anew such that the "-" and "+" prefixes are
+// reversed, and the X and Y values (and slice indexes) of each difference
+// are swapped. This is useful for presenting a result in a "got vs want"
+// order when the comparison was performed in a "want vs got" order.
+//
+// If the result was not produced by Compare, then the inverted report is empty.
+func (r DiffResult) Invert() DiffResult {
+	r2 := DiffResult{opts: r.opts, numIgnored: r.numIgnored, numTransformed: r.numTransformed}
+	if r.root != nil {
+		r2.root = r.root.Invert()
+		r2.Report = (&defaultReporter{root: r2.root, opts: r.opts}).String()
+	}
+	for _, d := range r.Differences {
+		d2 := Difference{Path: make(Path, len(d.Path)), X: d.Y, Y: d.X, Severity: d.Severity}
+		for i, s := range d.Path {
+			d2.Path[i] = invertStep(s)
+		}
+		r2.Differences = append(r2.Differences, d2)
+	}
+	return r2
+}
+
+// MarshalJSON encodes the result as a JSON object, which is intended for
+// consumption by machines (e.g., continuous integration systems).
+// It is equivalent to encoding the output of Encode.
+func (r DiffResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Encode())
+}
+
+// EncodedVersion is the version of the EncodedResult format.
+// It is incremented whenever the format changes in a way that prevents
+// older programs from interpreting it correctly.
+const EncodedVersion = 1
+
+// Encode returns the result in a stable format that is suitable for
+// serialization and storage. Unlike Report, whose format may change between
+// releases of this package, the EncodedResult format is versioned
+// (see EncodedVersion) and is only extended in backwards compatible ways.
+func (r DiffResult) Encode() EncodedResult {
+	out := EncodedResult{
+		Version:     EncodedVersion,
+		Equal:       r.Equal(),
+		Severity:    r.Severity().String(),
+		Report:      r.Report,
+		Differences: []EncodedDifference{},
+	}
+	for _, d := range r.Differences {
+		ed := EncodedDifference{Path: d.Path.GoString(), JSONPath: d.Path.JSONPath(), Type: d.Path.Last().Type().String(), Kind: d.Kind().String(), Severity: d.Severity.String()}
+		redacted := r.opts.isRedactedPath(d.Path)
+		if d.X.IsValid() {
+			s := r.opts.withPath(d.Path).formatValueLine(d.X)
+			if redacted {
+				s = string(textRedacted)
+			}
+			ed.X = &s
+		}
+		if d.Y.IsValid() {
+			s := r.opts.withPath(d.Path).formatValueLine(d.Y)
+			if redacted {
+				s = string(textRedacted)
+			}
+			ed.Y = &s
+		}
+		out.Differences = append(out.Differences, ed)
+	}
+	return out
+}
+
+// DecodeResult parses a JSON encoded EncodedResult
+// (e.g., as produced by DiffResult.MarshalJSON).
+// It reports an error if the version is missing or newer than EncodedVersion.
+func DecodeResult(b []byte) (EncodedResult, error) {
+	var r EncodedResult
+	if err := json.Unmarshal(b, &r); err != nil {
+		return EncodedResult{}, err
+	}
+	if r.Version < 1 || r.Version > EncodedVersion {
+		return EncodedResult{}, fmt.Errorf("unsupported encoded result version: %d", r.Version)
+	}
+	return r, nil
+}
+
+// EncodedResult is the serializable form of a DiffResult.
+// When encoded as JSON, it has the following form:
+//
+//	{
+//		"version": 1,
+//		"equal": false,
+//		"severity": "blocking",
+//		"report": "...",
+//		"differences": [{
+//			"path": "{T}.Field[2]",
+//			"jsonpath": "$.Field[2]",
+//			"type": "int",
+//			"kind": "modified",
+//			"severity": "blocking",
+//			"x": "1",
+//			"y": "2"
+//		}]
+//	}
+type EncodedResult struct {
+	// Version is the version of the format (see EncodedVersion).
+	Version int `json:"version"`
+	// Equal reports whether the compared values are equal.
+	Equal bool `json:"equal"`
+	// Severity is the most severe classification of all differences,
+	// formatted as by Severity.String.
+	Severity string `json:"severity"`
+	// Report is the humanly-readable report of the differences.
+	Report string `json:"report"`
+	// Differences is the list of unequal leaf nodes.
+	Differences []EncodedDifference `json:"differences"`
+}
+
+// EncodedDifference is the serializable form of a Difference.
+type EncodedDifference struct {
+	// Path is the path to the unequal node, formatted as by Path.GoString.
+	Path string `json:"path"`
+	// JSONPath is the path to the unequal node, formatted as by Path.JSONPath.
+	JSONPath string `json:"jsonpath"`
+	// Type is the type of the unequal node.
+	Type string `json:"type"`
+	// Kind is either "modified", "removed" (only in x),
+	// or "inserted" (only in y), formatted as by DifferenceKind.String.
+	Kind string `json:"kind"`
+	// Severity is the classification of the difference,
+	// formatted as by Severity.String.
+	Severity string `json:"severity"`
+	// X and Y are the values formatted as in the report, but on a single line
+	// and without truncation. They are nil for a missing slice element
+	// or map entry.
+	X *string `json:"x,omitempty"`
+	Y *string `json:"y,omitempty"`
+}
+
+// Difference describes a single leaf node in the value tree that was
+// determined to be unequal.
+type Difference struct {
+	// Path is the path from the root to the unequal node.
+	// Unlike the Path provided to a Reporter, it remains valid
+	// after the comparison has completed.
+	Path Path
+
+	// X and Y are the values of the unequal node.
+	// One of the values is invalid if a slice element or map entry is
+	// missing from either the x or y value.
+	X, Y reflect.Value
+
+	// Severity is the classification of the difference
+	// (see ClassifyDifferences).
+	Severity Severity
+}
+
+// Kind reports whether the node is present in both x and y,
+// or is a slice element or map entry that is only in one of them.
+func (d Difference) Kind() DifferenceKind {
+	switch {
+	case !d.Y.IsValid():
+		return OnlyInX
+	case !d.X.IsValid():
+		return OnlyInY
+	default:
+		return Modified
+	}
+}
+
+// DifferenceKind classifies a difference by the presence of the node
+// in the x and y values.
+type DifferenceKind int
+
+const (
+	// Modified differences are present in both x and y with unequal values.
+	Modified DifferenceKind = iota
+	// OnlyInX differences are slice elements or map entries missing from y.
+	OnlyInX
+	// OnlyInY differences are slice elements or map entries missing from x.
+	OnlyInY
+)
+
+func (k DifferenceKind) String() string {
+	switch k {
+	case Modified:
+		return "modified"
+	case OnlyInX:
+		return "removed"
+	case OnlyInY:
+		return "inserted"
+	default:
+		return fmt.Sprintf("DifferenceKind(%d)", int(k))
+	}
+}
+
+// Severity is the classification of a difference, which is intended for
+// deciding whether differences should fail a check (e.g., in a continuous
+// integration pipeline). Greater values are more severe.
+type Severity int
+
+const (
+	// NoSeverity is the severity of a result with no differences.
+	NoSeverity Severity = iota
+	// Informational differences are expected and require no action.
+	Informational
+	// Warning differences are worth reporting, but are not a failure.
+	Warning
+	// Blocking differences are a failure.
+	Blocking
+)
+
+func (s Severity) String() string {
+	switch s {
+	case NoSeverity:
+		return "none"
+	case Informational:
+		return "informational"
+	case Warning:
+		return "warning"
+	case Blocking:
+		return "blocking"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// CollectDifferences returns an Option that appends every unequal leaf node
+// to the slice pointed to by diffs, in the order that they are encountered
+// while traversing the value trees. It is equivalent to the Differences
+// of the DiffResult returned by Compare, but may be used with Equal or Diff,
+// and is a ready-made alternative to implementing a custom Reporter.
+// The severity of each difference is determined by the ClassifyDifferences
+// options provided alongside it.
+func CollectDifferences(diffs *[]Difference) Option {
+	if diffs == nil {
+		panic("invalid differences pointer: <nil>")
+	}
+	return collector{diffs}
+}
+
+type collector struct{ diffs *[]Difference }
+
+func (collector) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+func (collector) String() string { return "CollectDifferences(...)" }
+
+// diffCollector is a reporter that records every unequal leaf node.
+type diffCollector struct {
+	path        Path
+	diffs       *[]Difference
+	classifiers []classifier
+}
+
+func (r *diffCollector) PushStep(ps PathStep) {
+	r.path = append(r.path, ps)
+}
+func (r *diffCollector) Report(rs Result) {
+	if !rs.Equal() {
+		vx, vy := r.path.Last().Values()
+		d := Difference{Path: r.path.clone(), X: vx, Y: vy, Severity: Blocking}
+		if len(r.classifiers) > 0 {
+			d.Severity = NoSeverity
+			for _, f := range r.classifiers {
+				switch sev := f(d.Path); sev {
+				case Informational, Warning, Blocking:
+					if sev > d.Severity {
+						d.Severity = sev
+					}
+				default:
+					panic(fmt.Sprintf("invalid severity %v for difference at %#v", sev, d.Path))
+				}
+			}
+		}
+		*r.diffs = append(*r.diffs, d)
+	}
+}
+func (r *diffCollector) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
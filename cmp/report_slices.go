@@ -6,6 +6,7 @@ package cmp
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -32,6 +33,10 @@ func (opts formatOptions) CanFormatDiffSlice(v *valueNode) bool {
 		return false // Some ignore option was used
 	case v.NumTransformed > 0:
 		return false // Some transform option was used
+	case len(opts.Transformers) > 0:
+		return false // Some report transformer may apply to the elements
+	case len(opts.RedactedPaths) > 0 && v.Type.Kind() != reflect.String:
+		return false // Some elements may be redacted
 	case v.NumCompared > 1:
 		return false // More than one comparison was used
 	case v.NumCompared == 1 && v.Type.Name() != "":
@@ -66,11 +71,54 @@ func (opts formatOptions) CanFormatDiffSlice(v *valueNode) bool {
 		return false
 	}
 
+	// Always use specialized diffing if explicitly requested.
+	if opts.bytesMode(v.Type) != autoBytes {
+		return true
+	}
+	if opts.IndentJSON {
+		if sx, sy, ok := textValues(v.ValueX, v.ValueY); ok {
+			if _, _, ok := indentJSON(sx, sy); ok {
+				return true
+			}
+		}
+	}
+
 	// Use specialized string diffing for longer slices or strings.
 	const minLength = 64
 	return v.ValueX.Len() >= minLength && v.ValueY.Len() >= minLength
 }
 
+// textValues returns the contents of vx and vy if they are strings or
+// byte slices.
+func textValues(vx, vy reflect.Value) (sx, sy string, ok bool) {
+	switch t := vx.Type(); {
+	case t.Kind() == reflect.String:
+		return vx.String(), vy.String(), true
+	case t.Kind() == reflect.Slice && t.Elem() == reflect.TypeOf(byte(0)):
+		return string(vx.Bytes()), string(vy.Bytes()), true
+	}
+	return "", "", false
+}
+
+// indentJSON reports whether sx and sy are both JSON objects or arrays,
+// and if so, returns them re-indented with one value per line.
+func indentJSON(sx, sy string) (ix, iy string, ok bool) {
+	indent := func(s string) (string, bool) {
+		s = strings.TrimSpace(s)
+		if !strings.HasPrefix(s, "{") && !strings.HasPrefix(s, "[") {
+			return "", false
+		}
+		var b bytes.Buffer
+		if err := json.Indent(&b, []byte(s), "", "\t"); err != nil {
+			return "", false
+		}
+		return b.String() + "\n", true
+	}
+	ix, okx := indent(sx)
+	iy, oky := indent(sy)
+	return ix, iy, okx && oky
+}
+
 // FormatDiffSlice prints a diff for the slices (or strings) represented by v.
 // This provides custom-tailored logic to make printing of differences in
 // textual strings and slices of primitive kinds more readable.
@@ -95,13 +143,23 @@ func (opts formatOptions) FormatDiffSlice(v *valueNode) textNode {
 		vy2.Set(vy)
 		vx, vy = vx2, vy2
 	}
+	if (isText || isBinary) && opts.IndentJSON {
+		if ix, iy, ok := indentJSON(sx, sy); ok {
+			sx, sy = ix, iy
+		}
+	}
 	if isText || isBinary {
-		var numLines, lastLineIdx, maxLineLen int
-		isBinary = !utf8.ValidString(sx) || !utf8.ValidString(sy)
+		// Treat the data as binary if it is not valid UTF-8 or
+		// if more than a small fraction of the characters are non-printable.
+		// This permits text with a few control characters,
+		// which are escaped when printed.
+		const maxNonPrintRatio = 16
+		var numLines, lastLineIdx, maxLineLen, numRunes, numNonPrint int
+		isInvalid := !utf8.ValidString(sx) || !utf8.ValidString(sy)
 		for i, r := range sx + sy {
+			numRunes++
 			if !(unicode.IsPrint(r) || unicode.IsSpace(r)) || r == utf8.RuneError {
-				isBinary = true
-				break
+				numNonPrint++
 			}
 			if r == '\n' {
 				if maxLineLen < i-lastLineIdx {
@@ -111,7 +169,17 @@ func (opts formatOptions) FormatDiffSlice(v *valueNode) textNode {
 				numLines++
 			}
 		}
+		isBinary = isInvalid || numNonPrint*maxNonPrintRatio > numRunes
+		switch opts.bytesMode(t) {
+		case textBytes:
+			isBinary = false
+		case binaryBytes:
+			isBinary = true
+		}
 		isText = !isBinary
+		if isText && isConfusable(sx, sy) {
+			opts.EscapeUnicode = true // print the code points of confusable characters
+		}
 		isLinedText = isText && numLines >= 4 && maxLineLen <= 256
 	}
 
@@ -127,7 +195,7 @@ func (opts formatOptions) FormatDiffSlice(v *valueNode) textNode {
 		list = opts.formatDiffSlice(
 			reflect.ValueOf(ssx), reflect.ValueOf(ssy), 1, "line",
 			func(v reflect.Value, d diffMode) textRecord {
-				s := formatString(v.Index(0).String())
+				s := opts.formatString(v.Index(0).String())
 				return textRecord{Diff: d, Value: textLine(s)}
 			},
 		)
@@ -158,7 +226,9 @@ func (opts formatOptions) FormatDiffSlice(v *valueNode) textNode {
 		for _, r := range list {
 			if !r.Value.Equal(textEllipsis) {
 				line, _ := strconv.Unquote(string(r.Value.(textLine)))
-				line = strings.TrimPrefix(strings.TrimSuffix(line, "\r"), "\r") // trim leading/trailing carriage returns for legacy Windows endline support
+				if !opts.ShowWhitespace {
+					line = strings.TrimPrefix(strings.TrimSuffix(line, "\r"), "\r") // trim leading/trailing carriage returns for legacy Windows endline support
+				}
 				normLine := strings.Map(func(r rune) rune {
 					if unicode.IsSpace(r) {
 						return -1 // drop whitespace to avoid visually indistinguishable output
@@ -166,9 +236,13 @@ func (opts formatOptions) FormatDiffSlice(v *valueNode) textNode {
 					return r
 				}, line)
 				isPrintable := func(r rune) bool {
-					return unicode.IsPrint(r) || r == '\t' // specially treat tab as printable
+					if opts.EscapeUnicode && r >= utf8.RuneSelf {
+						return false
+					}
+					return unicode.IsPrint(r) || r == '\t' && opts.rawTabs() // specially treat tab as printable
 				}
 				isTripleQuoted = !strings.HasPrefix(line, `"""`) && !strings.HasPrefix(line, "...") && strings.TrimFunc(line, isPrintable) == ""
+				isTripleQuoted = isTripleQuoted && !(opts.ShowWhitespace && hasTrailingSpace(line))
 				switch r.Diff {
 				case diffRemoved:
 					isTripleQuoted = isTripleQuoted && !prevInsertLines[normLine]
@@ -216,7 +290,7 @@ func (opts formatOptions) FormatDiffSlice(v *valueNode) textNode {
 		list = opts.formatDiffSlice(
 			reflect.ValueOf(sx), reflect.ValueOf(sy), 64, "byte",
 			func(v reflect.Value, d diffMode) textRecord {
-				s := formatString(v.String())
+				s := opts.formatString(v.String())
 				return textRecord{Diff: d, Value: textLine(s)}
 			},
 		)
@@ -263,6 +337,10 @@ func (opts formatOptions) FormatDiffSlice(v *valueNode) textNode {
 			func(v reflect.Value, d diffMode) textRecord {
 				var ss []string
 				for i := 0; i < v.Len(); i++ {
+					if base := opts.integerBase(t.Elem()); base != 0 {
+						ss = append(ss, formatBase(v.Index(i), base))
+						continue
+					}
 					switch t.Elem().Kind() {
 					case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 						ss = append(ss, fmt.Sprint(v.Index(i).Int()))
@@ -270,7 +348,13 @@ func (opts formatOptions) FormatDiffSlice(v *valueNode) textNode {
 						ss = append(ss, fmt.Sprint(v.Index(i).Uint()))
 					case reflect.Uint8, reflect.Uintptr:
 						ss = append(ss, formatHex(v.Index(i).Uint()))
-					case reflect.Bool, reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+					case reflect.Float32, reflect.Float64:
+						if opts.FloatFormat != 0 {
+							ss = append(ss, strconv.FormatFloat(v.Index(i).Float(), opts.FloatFormat, opts.FloatPrecision, t.Elem().Bits()))
+							break
+						}
+						ss = append(ss, fmt.Sprint(v.Index(i).Interface()))
+					case reflect.Bool, reflect.Complex64, reflect.Complex128:
 						ss = append(ss, fmt.Sprint(v.Index(i).Interface()))
 					}
 				}
@@ -283,6 +367,7 @@ func (opts formatOptions) FormatDiffSlice(v *valueNode) textNode {
 	// Wrap the output with appropriate type information.
 	var out textNode = textWrap{"{", list, "}"}
 	if !isText {
+		out = textWrap{opts.formatDiffHeader(v) + "{", list, "}"}
 		// The "{...}" byte-sequence literal is not valid Go syntax for strings.
 		// Emit the type for extra clarity (e.g. "string{...}").
 		if t.Kind() == reflect.String {
@@ -321,7 +406,11 @@ func (opts formatOptions) formatDiffSlice(
 	vx, vy reflect.Value, chunkSize int, name string,
 	makeRec func(reflect.Value, diffMode) textRecord,
 ) (list textList) {
-	es := diff.Difference(vx.Len(), vy.Len(), func(ix int, iy int) diff.Result {
+	difference := diff.Difference
+	if opts.Deterministic {
+		difference = diff.StableDifference
+	}
+	es := difference(vx.Len(), vy.Len(), func(ix int, iy int) diff.Result {
 		return diff.BoolResult(vx.Index(ix).Interface() == vy.Index(iy).Interface())
 	})
 
@@ -344,6 +433,7 @@ func (opts formatOptions) formatDiffSlice(
 		maxLen = (1 << opts.verbosity()) << 2 // 4, 8, 16, 32, 64, etc...
 		opts.VerbosityLevel--
 	}
+	maxLen = opts.maxDiffRecords(maxLen)
 
 	groups := coalesceAdjacentEdits(name, es)
 	groups = coalesceInterveningIdentical(groups, chunkSize/4)
@@ -359,13 +449,13 @@ func (opts formatOptions) formatDiffSlice(
 			// Compute the number of leading and trailing equal bytes to print.
 			var numLo, numHi int
 			numEqual := ds.NumIgnored + ds.NumIdentical
-			for numLo < chunkSize*numContextRecords && numLo+numHi < numEqual && i != 0 {
+			for numLo < chunkSize*opts.numContext() && numLo+numHi < numEqual && i != 0 {
 				numLo++
 			}
-			for numHi < chunkSize*numContextRecords && numLo+numHi < numEqual && i != len(groups)-1 {
+			for numHi < chunkSize*opts.numContext() && numLo+numHi < numEqual && i != len(groups)-1 {
 				numHi++
 			}
-			if numEqual-(numLo+numHi) <= chunkSize && ds.NumIgnored == 0 {
+			if numEqual-(numLo+numHi) <= chunkSize && ds.NumIgnored == 0 || opts.FullContext {
 				numHi = numEqual - numLo // Avoid pointless coalescing of single equal row
 			}
 
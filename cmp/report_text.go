@@ -8,8 +8,10 @@ import (
 	"bytes"
 	"fmt"
 	"math/rand"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/google/go-cmp/cmp/internal/flags"
@@ -19,7 +21,15 @@ var randBool = rand.New(rand.NewSource(time.Now().Unix())).Intn(2) == 0
 
 const maxColumnLength = 80
 
-type indentMode int
+// indentMode is the current indentation level and the string used to
+// indent each level, where an empty string indents with a single tab.
+type indentMode struct {
+	level int
+	unit  string
+	width int // Maximum length of batched values; zero means maxColumnLength
+
+	stable bool // Whether to always use regular spaces after diff markers
+}
 
 func (n indentMode) appendIndent(b []byte, d diffMode) []byte {
 	// The output of Diff is documented as being unstable to provide future
@@ -27,7 +37,7 @@ func (n indentMode) appendIndent(b []byte, d diffMode) []byte {
 	// This logic intentionally introduces instability to the exact output
 	// so that users can detect accidental reliance on stability early on,
 	// rather than much later when an actual change to the format occurs.
-	if flags.Deterministic || randBool {
+	if flags.Deterministic || randBool || n.stable {
 		// Use regular spaces (U+0020).
 		switch d {
 		case diffUnknown, diffIdentical:
@@ -48,7 +58,13 @@ func (n indentMode) appendIndent(b []byte, d diffMode) []byte {
 			b = append(b, "+ "...)
 		}
 	}
-	return repeatCount(n).appendChar(b, '\t')
+	if n.unit == "" {
+		return repeatCount(n.level).appendChar(b, '\t')
+	}
+	for i := 0; i < n.level; i++ {
+		b = append(b, n.unit...)
+	}
+	return b
 }
 
 type repeatCount int
@@ -83,8 +99,12 @@ type textNode interface {
 	// node. Since the top-level node cannot replace itself, this also returns
 	// the current node itself.
 	//
+	// If the width is positive, then any list longer than the width is not
+	// collapsed. Otherwise, only removed or inserted lists longer than
+	// maxColumnLength are not collapsed.
+	//
 	// This does not mutate the receiver.
-	formatCompactTo([]byte, diffMode) ([]byte, textNode)
+	formatCompactTo(b []byte, d diffMode, width int) ([]byte, textNode)
 	// formatExpandedTo formats the contents of the tree as a multi-line string
 	// to the provided buffer. In order for column alignment to operate well,
 	// formatCompactTo must be called before calling formatExpandedTo.
@@ -111,16 +131,217 @@ func (s1 textWrap) Equal(s2 textNode) bool {
 func (s textWrap) String() string {
 	var d diffMode
 	var n indentMode
-	_, s2 := s.formatCompactTo(nil, d)
+	_, s2 := s.formatCompactTo(nil, d, 0)
 	b := n.appendIndent(nil, d)      // Leading indent
 	b = s2.formatExpandedTo(b, d, n) // Main body
 	b = append(b, '\n')              // Trailing newline
 	return string(b)
 }
-func (s textWrap) formatCompactTo(b []byte, d diffMode) ([]byte, textNode) {
+// formatText returns the string representation of the text tree
+// according to the Indent and CompactLength options.
+func (opts formatOptions) formatText(s textNode) string {
+	if opts.CompactLength > 0 && !opts.Unified {
+		if b, ok := appendInline(nil, s); ok && len(b) <= opts.CompactLength {
+			if opts.LogSafe {
+				b = escapeControls(b)
+			}
+			return string(append(b, '\n'))
+		}
+	}
+	var d diffMode
+	n := indentMode{unit: opts.Indent, width: opts.LineWidth, stable: opts.Deterministic}
+	if opts.LogSafe {
+		n.unit = strings.Replace(n.unit, "\t", "  ", -1)
+		if n.unit == "" {
+			n.unit = "  "
+		}
+	}
+	_, s2 := s.formatCompactTo(nil, d, opts.LineWidth)
+	b := n.appendIndent(nil, d)      // Leading indent
+	b = s2.formatExpandedTo(b, d, n) // Main body
+	b = append(b, '\n')              // Trailing newline
+	if opts.Unified {
+		b = formatUnified(b, opts.UnifiedContext)
+	}
+	if opts.Color && !opts.LogSafe {
+		b = colorizeLines(b)
+	}
+	if opts.LogSafe {
+		b = escapeControls(b)
+	}
+	return string(b)
+}
+
+// escapeControls replaces control characters (other than newlines) and
+// invalid UTF-8 with Go escape sequences (e.g., "\x1b" or "\r").
+func escapeControls(b []byte) []byte {
+	var out []byte
+	for len(b) > 0 {
+		r, n := utf8.DecodeRune(b)
+		switch {
+		case r == utf8.RuneError && n == 1:
+			out = append(out, fmt.Sprintf(`\x%02x`, b[0])...)
+		case r != '\n' && unicode.IsControl(r):
+			q := strconv.QuoteRune(r)
+			out = append(out, q[1:len(q)-1]...)
+		default:
+			out = append(out, b[:n]...)
+		}
+		b = b[n:]
+	}
+	return out
+}
+
+// formatUnified converts a multi-line report into the unified diff format,
+// where each hunk of removed and inserted lines is surrounded by at most
+// context unchanged lines and preceded by a "@@ -l,s +l,s @@" header.
+// Line numbers are relative to the lines of the report for x and for y.
+func formatUnified(b []byte, context int) []byte {
+	type line struct {
+		mark    byte   // ' ', '-', or '+'
+		content []byte // line without the diff marker
+	}
+	var lines []line
+	for _, l := range bytes.Split(bytes.TrimSuffix(b, []byte("\n")), []byte("\n")) {
+		mark := byte(' ')
+		if len(l) > 0 && (l[0] == '-' || l[0] == '+') {
+			mark = l[0]
+		}
+		for i := 0; i < 2 && len(l) > 0; i++ {
+			_, n := utf8.DecodeRune(l)
+			l = l[n:] // Strip the marker and the following space
+		}
+		lines = append(lines, line{mark, l})
+	}
+
+	out := []byte("--- x\n+++ y\n")
+	var nx, ny int // Number of x and y lines before lines[i]
+	for i := 0; i < len(lines); {
+		if lines[i].mark == ' ' {
+			nx, ny = nx+1, ny+1
+			i++
+			continue
+		}
+
+		// Determine the extent of the hunk, merging nearby changes.
+		lo := i - context
+		if lo < 0 {
+			lo = 0
+		}
+		hi := i
+		for j := i; j < len(lines) && j <= hi+2*context; j++ {
+			if lines[j].mark != ' ' {
+				hi = j
+			}
+		}
+		hi += context
+		if hi >= len(lines) {
+			hi = len(lines) - 1
+		}
+
+		// Format the hunk header and body.
+		sx, sy := nx-(i-lo), ny-(i-lo)
+		var lx, ly int
+		var body []byte
+		for _, l := range lines[lo : hi+1] {
+			if l.mark != '+' {
+				lx++
+			}
+			if l.mark != '-' {
+				ly++
+			}
+			body = append(append(append(body, l.mark), l.content...), '\n')
+		}
+		out = append(out, fmt.Sprintf("@@ -%s +%s @@\n", unifiedRange(sx, lx), unifiedRange(sy, ly))...)
+		out = append(out, body...)
+		nx, ny = sx+lx, sy+ly
+		i = hi + 1
+	}
+	return out
+}
+
+// unifiedRange formats the start line and number of lines of a hunk,
+// where start is the number of lines preceding the hunk.
+func unifiedRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+const (
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiReset = "\x1b[0m"
+)
+
+// colorizeLines wraps every removed line in red and every inserted line
+// in green using ANSI escape sequences.
+func colorizeLines(b []byte) []byte {
+	var out []byte
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		body := bytes.TrimSuffix(line, []byte("\n"))
+		var color string
+		if len(body) > 0 {
+			switch diffMode(body[0]) {
+			case diffRemoved:
+				color = ansiRed
+			case diffInserted:
+				color = ansiGreen
+			}
+		}
+		if color == "" {
+			out = append(out, line...)
+			continue
+		}
+		out = append(out, color...)
+		out = append(out, body...)
+		out = append(out, ansiReset...)
+		out = append(out, line[len(body):]...)
+	}
+	return out
+}
+
+// appendInline appends a single-line representation of the text tree,
+// where removed and inserted records are prefixed with '-' and '+'.
+// Comments other than those wrapping a node are dropped.
+// It reports false if the tree cannot be represented on a single line
+// (e.g., it contains a triple-quoted string).
+func appendInline(b []byte, s textNode) ([]byte, bool) {
+	ok := true
+	switch s := s.(type) {
+	case textWrap:
+		b = append(b, s.Prefix...)
+		b, ok = appendInline(b, s.Value)
+		b = append(b, s.Suffix...)
+	case textList:
+		for i, r := range s {
+			if r.ElideComma && r.Value.Equal(textLine(`"""`)) {
+				return b, false
+			}
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			if r.Diff == diffRemoved || r.Diff == diffInserted {
+				b = append(b, byte(r.Diff))
+			}
+			if r.Key != "" {
+				b = append(b, r.Key+": "...)
+			}
+			if b, ok = appendInline(b, r.Value); !ok {
+				return b, false
+			}
+		}
+	case textLine:
+		b = append(b, s...)
+	}
+	return b, ok
+}
+
+func (s textWrap) formatCompactTo(b []byte, d diffMode, width int) ([]byte, textNode) {
 	n0 := len(b) // Original buffer length
 	b = append(b, s.Prefix...)
-	b, s.Value = s.Value.formatCompactTo(b, d)
+	b, s.Value = s.Value.formatCompactTo(b, d, width)
 	b = append(b, s.Suffix...)
 	if _, ok := s.Value.(textLine); ok {
 		return b, textLine(b[n0:])
@@ -198,7 +419,7 @@ func (s textList) String() string {
 	return textWrap{"{", s, "}"}.String()
 }
 
-func (s textList) formatCompactTo(b []byte, d diffMode) ([]byte, textNode) {
+func (s textList) formatCompactTo(b []byte, d diffMode, width int) ([]byte, textNode) {
 	s = append(textList(nil), s...) // Avoid mutating original
 
 	// Determine whether we can collapse this list as a single line.
@@ -212,7 +433,7 @@ func (s textList) formatCompactTo(b []byte, d diffMode) ([]byte, textNode) {
 		if r.Key != "" {
 			b = append(b, ": "...)
 		}
-		b, s[i].Value = r.Value.formatCompactTo(b, d|r.Diff)
+		b, s[i].Value = r.Value.formatCompactTo(b, d|r.Diff, width)
 		if _, ok := s[i].Value.(textLine); !ok {
 			multiLine = true
 		}
@@ -225,7 +446,12 @@ func (s textList) formatCompactTo(b []byte, d diffMode) ([]byte, textNode) {
 	}
 	// Force multi-lined output when printing a removed/inserted node that
 	// is sufficiently long.
-	if (d == diffInserted || d == diffRemoved) && len(b[n0:]) > maxColumnLength {
+	if (d == diffInserted || d == diffRemoved) && len(b[n0:]) > maxColumnLength && width <= 0 {
+		multiLine = true
+	}
+	// Force multi-lined output when printing any node that exceeds the
+	// explicitly requested width.
+	if width > 0 && len(b[n0:]) > width {
 		multiLine = true
 	}
 	if !multiLine {
@@ -262,7 +488,7 @@ func (s textList) formatExpandedTo(b []byte, d diffMode, n indentMode) []byte {
 		}
 	}
 	if isSimple {
-		n++
+		n.level++
 		var batch []byte
 		emitBatch := func() {
 			if len(batch) > 0 {
@@ -271,21 +497,25 @@ func (s textList) formatExpandedTo(b []byte, d diffMode, n indentMode) []byte {
 				batch = batch[:0]
 			}
 		}
+		maxLen := maxColumnLength
+		if n.width > 0 {
+			maxLen = n.width
+		}
 		for _, r := range s {
 			line := r.Value.(textLine)
-			if len(batch)+len(line)+len(", ") > maxColumnLength {
+			if len(batch)+len(line)+len(", ") > maxLen {
 				emitBatch()
 			}
 			batch = append(batch, line...)
 			batch = append(batch, ", "...)
 		}
 		emitBatch()
-		n--
+		n.level--
 		return n.appendIndent(append(b, '\n'), d)
 	}
 
 	// Format the list as a multi-lined output.
-	n++
+	n.level++
 	for i, r := range s {
 		b = n.appendIndent(append(b, '\n'), d|r.Diff)
 		if r.Key != "" {
@@ -303,7 +533,7 @@ func (s textList) formatExpandedTo(b []byte, d diffMode, n indentMode) []byte {
 			b = append(b, " // "+r.Comment.String()...)
 		}
 	}
-	n--
+	n.level--
 
 	return n.appendIndent(append(b, '\n'), d)
 }
@@ -340,6 +570,7 @@ type textLine []byte
 var (
 	textNil      = textLine("nil")
 	textEllipsis = textLine("...")
+	textRedacted = textLine("<redacted>")
 )
 
 func (s textLine) Len() int {
@@ -354,7 +585,7 @@ func (s1 textLine) Equal(s2 textNode) bool {
 func (s textLine) String() string {
 	return string(s)
 }
-func (s textLine) formatCompactTo(b []byte, d diffMode) ([]byte, textNode) {
+func (s textLine) formatCompactTo(b []byte, _ diffMode, _ int) ([]byte, textNode) {
 	return append(b, s...), s
 }
 func (s textLine) formatExpandedTo(b []byte, _ diffMode, _ indentMode) []byte {
@@ -405,14 +636,7 @@ func (s diffStats) String() string {
 		sum += n
 	}
 
-	// Pluralize the name (adjusting for some obscure English grammar rules).
-	name := s.Name
-	if sum > 1 {
-		name += "s"
-		if strings.HasSuffix(name, "ys") {
-			name = name[:len(name)-2] + "ies" // e.g., "entrys" => "entries"
-		}
-	}
+	name := pluralize(s.Name, sum)
 
 	// Format the list according to English grammar (with Oxford comma).
 	switch n := len(ss); n {
@@ -425,6 +649,19 @@ func (s diffStats) String() string {
 	}
 }
 
+// pluralize pluralizes the name if n is greater than one
+// (adjusting for some obscure English grammar rules).
+func pluralize(name string, n int) string {
+	if n > 1 {
+		name += "s"
+		if strings.HasSuffix(name, "ys") && !strings.HasSuffix(name, "ays") &&
+			!strings.HasSuffix(name, "eys") && !strings.HasSuffix(name, "oys") {
+			name = name[:len(name)-2] + "ies" // e.g., "entrys" => "entries", but not "keys"
+		}
+	}
+	return name
+}
+
 type commentString string
 
 func (s commentString) String() string { return string(s) }
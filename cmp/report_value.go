@@ -4,13 +4,17 @@
 
 package cmp
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+)
 
 // valueNode represents a single node within a report, which is a
 // structured representation of the value tree, containing information
 // regarding which nodes are equal or not.
 type valueNode struct {
 	parent *valueNode
+	step   PathStep // The step from the parent to this node (not aliased)
 
 	Type   reflect.Type
 	ValueX reflect.Value
@@ -44,6 +48,18 @@ type valueNode struct {
 
 	// TransformerName is the name of the transformer.
 	TransformerName string // If non-empty, implies Value is populated
+
+	// AppliedBy describes the Equal method, Comparer, or Transformer
+	// that was directly applied to this node (e.g., "Comparer(main.f)").
+	AppliedBy string
+
+	// Redacted reports whether the value is a struct field or is at a path
+	// that must not be printed (see ReportRedactFields and ReportRedactPaths).
+	Redacted bool
+
+	// Annotator is the Comparer that determined this node to be unequal,
+	// if it is able to describe why (see AnnotateComparer).
+	Annotator *comparer
 }
 type reportRecord struct {
 	Key   reflect.Value // Invalid for slice element
@@ -52,7 +68,7 @@ type reportRecord struct {
 
 func (parent *valueNode) PushStep(ps PathStep) (child *valueNode) {
 	vx, vy := ps.Values()
-	child = &valueNode{parent: parent, Type: ps.Type(), ValueX: vx, ValueY: vy}
+	child = &valueNode{parent: parent, step: cloneStep(ps), Type: ps.Type(), ValueX: vx, ValueY: vy}
 	switch s := ps.(type) {
 	case StructField:
 		assert(parent.Value == nil)
@@ -73,6 +89,7 @@ func (parent *valueNode) PushStep(ps PathStep) (child *valueNode) {
 		assert(parent.Value == nil && parent.Records == nil)
 		parent.Value = child
 		parent.TransformerName = s.Name()
+		parent.AppliedBy = fmt.Sprint(s.Option())
 		parent.NumTransformed++
 	default:
 		assert(parent == nil) // Must be the root step
@@ -80,6 +97,41 @@ func (parent *valueNode) PushStep(ps PathStep) (child *valueNode) {
 	return child
 }
 
+// Path returns the path from the root to this node.
+func (v *valueNode) Path() (p Path) {
+	for ; v != nil; v = v.parent {
+		p = append(p, v.step)
+	}
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
+
+// DeepestDiff returns the first unequal leaf node with the greatest depth,
+// or nil if all descendants are equal.
+func (v *valueNode) DeepestDiff() *valueNode {
+	if v.NumDiff == 0 {
+		return nil
+	}
+	if v.Value != nil {
+		return v.Value.DeepestDiff()
+	}
+	var deepest *valueNode
+	var maxDepth int
+	for _, r := range v.Records {
+		if d := r.Value.DeepestDiff(); d != nil {
+			if n := len(d.Path()); deepest == nil || n > maxDepth {
+				deepest, maxDepth = d, n
+			}
+		}
+	}
+	if deepest == nil {
+		return v // Must be an unequal leaf node
+	}
+	return deepest
+}
+
 func (r *valueNode) Report(rs Result) {
 	assert(r.MaxDepth == 0) // May only be called on leaf nodes
 
@@ -96,9 +148,14 @@ func (r *valueNode) Report(rs Result) {
 
 	if rs.ByMethod() {
 		r.NumCompared++
+		r.AppliedBy = "Equal method"
 	}
 	if rs.ByFunc() {
 		r.NumCompared++
+		r.AppliedBy = fmt.Sprint(rs.opt)
+		if cm, ok := rs.opt.(*comparer); ok && !rs.Equal() && cm.annotate.IsValid() {
+			r.Annotator = cm
+		}
 	}
 	assert(r.NumCompared <= 1)
 }
@@ -108,6 +165,12 @@ func (child *valueNode) PopStep() (parent *valueNode) {
 		return nil
 	}
 	parent = child.parent
+	parent.addStats(child)
+	return parent
+}
+
+// addStats accumulates the statistics of the child into the parent.
+func (parent *valueNode) addStats(child *valueNode) {
 	parent.NumSame += child.NumSame
 	parent.NumDiff += child.NumDiff
 	parent.NumIgnored += child.NumIgnored
@@ -117,5 +180,63 @@ func (child *valueNode) PopStep() (parent *valueNode) {
 	if parent.MaxDepth < child.MaxDepth+1 {
 		parent.MaxDepth = child.MaxDepth + 1
 	}
-	return parent
+}
+
+// LimitDiffs prunes the tree such that only the first n unequal leaf nodes
+// (in traversal order) remain. Records that only contain pruned differences
+// are removed entirely, while equal records are preserved as context.
+func (v *valueNode) LimitDiffs(n int) {
+	if v.NumDiff <= n || v.MaxDepth == 0 {
+		return
+	}
+	if v.Value != nil {
+		v.Value.LimitDiffs(n)
+	} else {
+		var recs []reportRecord
+		for _, r := range v.Records {
+			if r.Value.NumDiff > 0 {
+				if n == 0 {
+					continue // Drop records with pruned differences
+				}
+				r.Value.LimitDiffs(n)
+				n -= r.Value.NumDiff
+			}
+			recs = append(recs, r)
+		}
+		v.Records = recs
+	}
+
+	// Recompute the statistics from the remaining children.
+	v.NumSame, v.NumDiff, v.NumIgnored, v.NumCompared = 0, 0, 0, 0
+	v.NumTransformed, v.NumChildren, v.MaxDepth = 0, 0, 0
+	if v.TransformerName != "" {
+		v.NumTransformed++
+	}
+	if v.Value != nil {
+		v.addStats(v.Value)
+	}
+	for _, r := range v.Records {
+		v.addStats(r.Value)
+	}
+}
+
+// Invert returns a copy of the tree as if the x and y values were swapped.
+func (v *valueNode) Invert() *valueNode {
+	return v.invert(nil)
+}
+func (v *valueNode) invert(parent *valueNode) *valueNode {
+	v2 := *v
+	v2.parent = parent
+	v2.step = invertStep(v.step)
+	v2.ValueX, v2.ValueY = v.ValueY, v.ValueX
+	if v.Value != nil {
+		v2.Value = v.Value.invert(&v2)
+	}
+	if v.Records != nil {
+		v2.Records = make([]reportRecord, len(v.Records))
+		for i, r := range v.Records {
+			v2.Records[i] = reportRecord{Key: r.Key, Value: r.Value.invert(&v2)}
+		}
+	}
+	return &v2
 }
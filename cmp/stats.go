@@ -0,0 +1,52 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import "reflect"
+
+// Stats is a set of counters describing the work performed by a comparison
+// (see CollectStats).
+type Stats struct {
+	// NumVisited is the number of nodes visited in the value trees,
+	// including nodes that are visited more than once and nodes visited
+	// while verifying that Transformers are deterministic.
+	NumVisited int
+	// MaxDepth is the maximum depth of a visited node,
+	// where the root node has a depth of zero.
+	MaxDepth int
+	// NumComparerCalls is the number of pairs of values compared by
+	// Comparer functions.
+	NumComparerCalls int
+	// NumEqualCalls is the number of pairs of values compared by
+	// Equal methods.
+	NumEqualCalls int
+	// NumTransformerCalls is the number of values transformed by
+	// Transformer functions, which is two for each transformed node.
+	NumTransformerCalls int
+}
+
+// CollectStats returns an Option that counts the work performed by
+// the Equal, Diff, or Compare call that it is passed to,
+// storing the counters in stats when the call returns.
+// The counters include all work performed by the call, which may
+// traverse the value trees more than once (e.g., Diff first checks
+// for equality before constructing the report), such that it is possible
+// to determine how often a custom Comparer or Transformer is invoked.
+//
+// The Stats must not be accessed concurrently with the comparison.
+func CollectStats(stats *Stats) Option {
+	if stats == nil {
+		panic("invalid stats pointer: <nil>")
+	}
+	return statsCollector{stats}
+}
+
+type statsCollector struct{ stats *Stats }
+
+func (statsCollector) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+func (statsCollector) String() string { return "CollectStats(...)" }
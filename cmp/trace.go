@@ -0,0 +1,74 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Trace returns an Option that writes a log of the comparison to w,
+// which is intended for debugging why an option did or did not apply
+// to a particular node. For each node in the value trees, it writes a line
+// when the node is visited, when a FilterPath or FilterValues filter
+// matches the node, when an option is applied, and when the result of
+// the node is determined. Each line is prefixed by the path to the node,
+// formatted as by Path.GoString. For example:
+//
+//	{T}.Name: visit string
+//	{T}.Name: filter FilterPath(main.isName, Ignore()) matched
+//	{T}.Name: apply Ignore()
+//	{T}.Name: ignored by Ignore()
+//
+// Nodes that are compared speculatively (e.g., to align slice elements)
+// are not traced. The format of the log is not stable.
+// Errors writing to w are ignored.
+func Trace(w io.Writer) Option {
+	if w == nil {
+		panic("invalid trace writer: <nil>")
+	}
+	return tracer{w}
+}
+
+type tracer struct{ w io.Writer }
+
+func (tracer) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+func (tracer) String() string { return "Trace(...)" }
+
+// tracef writes a line to every tracer, prefixed by the current path.
+// Callers should check that tracers exist to avoid needless allocations.
+func (s *state) tracef(format string, args ...interface{}) {
+	line := fmt.Sprintf("%#v: "+format+"\n", append([]interface{}{s.curPath}, args...)...)
+	for _, t := range s.tracers {
+		io.WriteString(t.w, line)
+	}
+}
+
+// traceResult traces the result of the current node.
+func (s *state) traceResult(rf resultFlags, opt Option) {
+	r := Result{flags: rf, opt: opt}
+	var desc string
+	switch {
+	case r.ByIgnore():
+		desc = "ignored"
+	case r.Equal():
+		desc = "equal"
+	default:
+		desc = "unequal"
+	}
+	switch {
+	case opt != nil:
+		desc += fmt.Sprintf(" by %v", opt)
+	case r.ByMethod():
+		desc += " by Equal method"
+	case r.ByCycle():
+		desc += " by cycle detection"
+	}
+	s.tracef("%s", desc)
+}
@@ -0,0 +1,177 @@
+// Copyright 2020, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"reflect"
+
+	"github.com/google/go-cmp/cmp/internal/function"
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
+// Walk traverses the value tree of v in the same order and using the same
+// path steps as Equal, calling fn for each node with the path from the root
+// to that node and the value of that node. The Path is only valid for the
+// duration of the call to fn. If fn returns false, then Walk does not
+// descend into the children of that node.
+//
+// Walk obeys the same rules as Equal when deciding whether to descend:
+//
+// • Nodes that are ignored by an Ignore option are skipped entirely.
+//
+// • If a Transformer applies, then Walk descends into the Transform step,
+// which holds the output of the transformer.
+//
+// • If a Comparer applies or the type has an Equal method,
+// then the node is visited, but not descended into.
+//
+// • Unexported fields are only visited if an Exporter allows it.
+// Otherwise, Walk panics unless the field is ignored.
+//
+// • Pointers, maps, and slice elements that have already been visited within
+// the current path are visited, but not descended into again.
+//
+// Reporter options have no effect on Walk.
+func Walk(v interface{}, fn func(Path, reflect.Value) bool, opts ...Option) {
+	w := walker{s: newState(opts), fn: fn}
+	w.walkAny(rootStep(v, v))
+}
+
+type walker struct {
+	s  *state
+	fn func(Path, reflect.Value) bool
+}
+
+func (w *walker) walkAny(step PathStep) {
+	s := w.s
+	s.curPath.push(step)
+	defer s.curPath.pop()
+	s.recChecker.Check(s.curPath)
+
+	t := step.Type()
+	v, _ := step.Values()
+
+	// Evaluate the options first since an ignored node is never visited.
+	opt := s.opts.filter(s, t, v, v)
+	switch opt.(type) {
+	case ignore:
+		return
+	case validator:
+		if v.IsValid() && !v.CanInterface() && s.skipPrivate {
+			return // Inaccessible unexported fields are ignored
+		}
+		if v.IsValid() {
+			opt.apply(s, v, v) // Panics for an inaccessible unexported field
+		}
+	}
+	if !w.fn(s.curPath, v) || !v.IsValid() {
+		return
+	}
+
+	// Cycle-detection for slice elements (see NOTE in compareSlice).
+	if si, ok := step.(SliceIndex); ok && si.isSlice {
+		p := v.Addr()
+		if _, visited := s.curPtrs.Push(p, p); visited {
+			return
+		}
+		defer s.curPtrs.Pop(p, p)
+	}
+
+	switch opt := opt.(type) {
+	case nil:
+	case *transformer:
+		out := opt.fnc.Call([]reflect.Value{sanitizeValue(v, opt.fnc.Type().In(0))})[0]
+		w.walkAny(Transform{&transform{pathStep{opt.fnc.Type().Out(0), out, out}, opt}})
+		return
+	case Options:
+		opt.apply(s, v, v) // Panics due to ambiguous set of options
+	default:
+		return // Node is compared as a whole by a Comparer
+	}
+	if m, ok := t.MethodByName("Equal"); ok && function.IsType(m.Type, function.EqualAssignable) {
+		return // Node is compared as a whole by the Equal method
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		w.walkStruct(t, v)
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && v.IsNil() {
+			return
+		}
+		step := SliceIndex{&sliceIndex{pathStep: pathStep{typ: t.Elem()}, isSlice: t.Kind() == reflect.Slice}}
+		for i := 0; i < v.Len(); i++ {
+			step.vx, step.xkey = v.Index(i), i
+			step.vy, step.ykey = v.Index(i), i
+			w.walkAny(step)
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		if _, visited := s.curPtrs.Push(v, v); visited {
+			return
+		}
+		defer s.curPtrs.Pop(v, v)
+		step := MapIndex{&mapIndex{pathStep: pathStep{typ: t.Elem()}}}
+		for _, k := range value.SortKeys(v.MapKeys()) {
+			step.vx = v.MapIndex(k)
+			step.vy = step.vx
+			step.key = k
+			w.walkAny(step)
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if _, visited := s.curPtrs.Push(v, v); visited {
+			return
+		}
+		defer s.curPtrs.Pop(v, v)
+		w.walkAny(Indirect{&indirect{pathStep{t.Elem(), v.Elem(), v.Elem()}}})
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		w.walkAny(TypeAssertion{&typeAssertion{pathStep{v.Elem().Type(), v.Elem(), v.Elem()}}})
+	}
+}
+
+func (w *walker) walkStruct(t reflect.Type, v reflect.Value) {
+	var va reflect.Value // Addressable version of v
+	var addr bool
+
+	var mayForce, mayForceInit bool
+	step := StructField{&structField{}}
+	for i := 0; i < t.NumField(); i++ {
+		step.field = t.Field(i)
+		step.typ = step.field.Type
+		step.vx = v.Field(i)
+		step.vy = v.Field(i)
+		step.name = step.field.Name
+		step.idx = i
+		step.unexported = !isExported(step.name)
+		if step.unexported {
+			if step.name == "_" {
+				continue
+			}
+			if !va.IsValid() {
+				addr = v.CanAddr()
+				va = makeAddressable(v)
+			}
+			if !mayForceInit {
+				for _, xf := range w.s.exporters {
+					mayForce = mayForce || xf.fnc(t)
+				}
+				mayForceInit = true
+			}
+			step.mayForce = mayForce
+			step.paddr = addr
+			step.pvx = va
+			step.pvy = va
+		}
+		w.walkAny(step)
+	}
+}